@@ -0,0 +1,299 @@
+// Package analysis implements classical item analysis for quizzes: how hard
+// each question is, how well it separates strong players from weak ones and
+// how each answer option was chosen.
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// GroupFraction is the share of attempts placed in each of the upper and
+// lower scoring groups.
+const GroupFraction = 0.27
+
+// Thresholds used to flag questions for review.
+const (
+	TooEasyAbove      = 0.9
+	TooHardBelow      = 0.2
+	LowDiscrimination = 0.2
+)
+
+const (
+	optionSkipped = "skipped"
+	// Upper and lower groups need at least two attempts to differ.
+	minimumGroupedSize = 2
+)
+
+// Flags raised on items that look problematic.
+const (
+	FlagTooEasy                = "too_easy"
+	FlagTooHard                = "too_hard"
+	FlagLowDiscrimination      = "low_discrimination"
+	FlagNegativeDiscrimination = "negative_discrimination"
+	FlagPossibleMiskey         = "possible_miskey"
+)
+
+// Options lists the answer options reported for every item, in order.
+var Options = []string{"A", "B", "C", "D", optionSkipped}
+
+// Question is an item under analysis.
+type Question struct {
+	ID            string
+	Text          string
+	CorrectAnswer string
+	Points        float64
+}
+
+// Response is one recorded answer together with the total score of the
+// attempt it belongs to. An empty Answer means the question was skipped.
+type Response struct {
+	AttemptID  string
+	QuestionID string
+	Answer     string
+	Correct    bool
+	Points     float64
+	Score      float64
+	MaxPoints  float64
+}
+
+// Option describes how often an answer option was chosen overall and by the
+// upper and lower scoring groups.
+type Option struct {
+	Option     string  `json:"option"`
+	Key        bool    `json:"key"`
+	Count      int     `json:"count"`
+	Proportion float64 `json:"proportion"`
+	Upper      float64 `json:"upper_proportion"`
+	Lower      float64 `json:"lower_proportion"`
+}
+
+// Item is the analysis of a single question. Statistics that cannot be
+// computed from the available responses are nil.
+type Item struct {
+	QuestionID     string   `json:"question_id"`
+	QuestionText   string   `json:"question_text"`
+	CorrectAnswer  string   `json:"correct_answer"`
+	Responses      int      `json:"responses"`
+	PValue         *float64 `json:"p_value"`
+	PointBiserial  *float64 `json:"point_biserial"`
+	Discrimination *float64 `json:"discrimination_index"`
+	Options        []Option `json:"options"`
+	Flags          []string `json:"flags"`
+}
+
+// Report is the item analysis of a quiz.
+type Report struct {
+	Attempts  int    `json:"attempts"`
+	GroupSize int    `json:"group_size"`
+	Items     []Item `json:"items"`
+}
+
+// Analyze computes the item analysis of questions from responses.
+//
+// Difficulty is the proportion of correct answers (the p-value). The
+// point-biserial correlation relates answering correctly to the attempt's
+// score on the remaining questions, and the discrimination index is the
+// difference in p-value between the top and bottom 27% of attempts.
+func Analyze(questions []Question, responses []Response) Report {
+	totals := make(map[string]float64)
+	for _, r := range responses {
+		if _, ok := totals[r.AttemptID]; !ok {
+			totals[r.AttemptID] = fraction(r.Score, r.MaxPoints)
+		}
+	}
+
+	ranked := make([]string, 0, len(totals))
+	for id := range totals {
+		ranked = append(ranked, id)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if totals[ranked[i]] != totals[ranked[j]] {
+			return totals[ranked[i]] > totals[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	group := 0
+	if len(ranked) >= minimumGroupedSize {
+		group = max(1, int(math.Round(float64(len(ranked))*GroupFraction)))
+	}
+	upper := make(map[string]bool, group)
+	lower := make(map[string]bool, group)
+	for i := 0; i < group; i++ {
+		upper[ranked[i]] = true
+		lower[ranked[len(ranked)-1-i]] = true
+	}
+
+	byQuestion := make(map[string][]Response)
+	for _, r := range responses {
+		byQuestion[r.QuestionID] = append(byQuestion[r.QuestionID], r)
+	}
+
+	report := Report{
+		Attempts:  len(ranked),
+		GroupSize: group,
+		Items:     make([]Item, 0, len(questions)),
+	}
+	for _, q := range questions {
+		report.Items = append(report.Items, analyzeItem(q, byQuestion[q.ID], upper, lower))
+	}
+
+	return report
+}
+
+func analyzeItem(q Question, responses []Response, upper, lower map[string]bool) Item {
+	item := Item{
+		QuestionID:    q.ID,
+		QuestionText:  q.Text,
+		CorrectAnswer: q.CorrectAnswer,
+		Responses:     len(responses),
+		Options:       make([]Option, 0, len(Options)),
+		Flags:         []string{},
+	}
+
+	counts := make(map[string]int)
+	upperCounts := make(map[string]int)
+	lowerCounts := make(map[string]int)
+	var correct, upperN, lowerN, upperCorrect, lowerCorrect int
+	for _, r := range responses {
+		option := r.Answer
+		if option == "" {
+			option = optionSkipped
+		}
+		counts[option]++
+		if r.Correct {
+			correct++
+		}
+		if upper[r.AttemptID] {
+			upperN++
+			upperCounts[option]++
+			if r.Correct {
+				upperCorrect++
+			}
+		}
+		if lower[r.AttemptID] {
+			lowerN++
+			lowerCounts[option]++
+			if r.Correct {
+				lowerCorrect++
+			}
+		}
+	}
+
+	for _, o := range Options {
+		item.Options = append(item.Options, Option{
+			Option:     o,
+			Key:        o == q.CorrectAnswer,
+			Count:      counts[o],
+			Proportion: ratio(counts[o], len(responses)),
+			Upper:      ratio(upperCounts[o], upperN),
+			Lower:      ratio(lowerCounts[o], lowerN),
+		})
+	}
+
+	if len(responses) == 0 {
+		return item
+	}
+
+	p := ratio(correct, len(responses))
+	item.PValue = &p
+	item.PointBiserial = pointBiserial(q, responses, p)
+	if upperN > 0 && lowerN > 0 {
+		d := ratio(upperCorrect, upperN) - ratio(lowerCorrect, lowerN)
+		item.Discrimination = &d
+	}
+
+	item.Flags = flags(item)
+
+	return item
+}
+
+// pointBiserial correlates answering q correctly with the attempt's score on
+// the other questions, so the item does not inflate its own correlation.
+func pointBiserial(q Question, responses []Response, p float64) *float64 {
+	if p == 0 || p == 1 {
+		return nil
+	}
+
+	rest := make([]float64, len(responses))
+	var mean float64
+	for i, r := range responses {
+		rest[i] = fraction(r.Score-r.Points, r.MaxPoints-q.Points)
+		mean += rest[i]
+	}
+	mean /= float64(len(rest))
+
+	var variance, sumCorrect, sumWrong float64
+	var nCorrect int
+	for i, r := range responses {
+		variance += (rest[i] - mean) * (rest[i] - mean)
+		if r.Correct {
+			sumCorrect += rest[i]
+			nCorrect++
+		} else {
+			sumWrong += rest[i]
+		}
+	}
+	sd := math.Sqrt(variance / float64(len(rest)))
+	if sd == 0 {
+		return nil
+	}
+
+	meanCorrect := sumCorrect / float64(nCorrect)
+	meanWrong := sumWrong / float64(len(rest)-nCorrect)
+	r := (meanCorrect - meanWrong) / sd * math.Sqrt(p*(1-p))
+
+	return &r
+}
+
+func flags(item Item) []string {
+	out := []string{}
+
+	switch p := *item.PValue; {
+	case p > TooEasyAbove:
+		out = append(out, FlagTooEasy)
+	case p < TooHardBelow:
+		out = append(out, FlagTooHard)
+	}
+
+	if d := item.Discrimination; d != nil {
+		switch {
+		case *d < 0:
+			out = append(out, FlagNegativeDiscrimination)
+		case *d < LowDiscrimination:
+			out = append(out, FlagLowDiscrimination)
+		}
+	}
+
+	// A distractor that attracts strong players more than the key does is
+	// the classic sign of a wrong answer key.
+	var key Option
+	for _, o := range item.Options {
+		if o.Key {
+			key = o
+		}
+	}
+	for _, o := range item.Options {
+		if !o.Key && o.Option != optionSkipped && o.Upper > key.Upper && o.Upper > o.Lower {
+			out = append(out, FlagPossibleMiskey)
+			break
+		}
+	}
+
+	return out
+}
+
+func fraction(score, maxPoints float64) float64 {
+	if maxPoints <= 0 {
+		return 0
+	}
+	return score / maxPoints
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
@@ -0,0 +1,56 @@
+package analysis
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteCSV writes r as CSV with one row per question. Option columns give
+// the count and the upper and lower group proportions for each option.
+func WriteCSV(w io.Writer, r Report) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"question_id", "question_text", "correct_answer", "responses", "p_value", "point_biserial", "discrimination_index"}
+	for _, o := range Options {
+		header = append(header, o+"_count", o+"_upper", o+"_lower")
+	}
+	header = append(header, "flags")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, item := range r.Items {
+		row := []string{
+			item.QuestionID,
+			item.QuestionText,
+			item.CorrectAnswer,
+			strconv.Itoa(item.Responses),
+			formatStat(item.PValue),
+			formatStat(item.PointBiserial),
+			formatStat(item.Discrimination),
+		}
+		for _, o := range item.Options {
+			row = append(row, strconv.Itoa(o.Count), formatFloat(o.Upper), formatFloat(o.Lower))
+		}
+		row = append(row, strings.Join(item.Flags, ";"))
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatStat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return formatFloat(*v)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
@@ -0,0 +1,335 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/grading"
+	"github.com/Iknite-Space/sqlc-example-api/irt"
+	"github.com/Iknite-Space/sqlc-example-api/policy"
+	"github.com/Iknite-Space/sqlc-example-api/scoring"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// adaptiveQuestion is a question served during an adaptive attempt, without
+// its answer.
+type adaptiveQuestion struct {
+	ID           string  `json:"id"`
+	QuestionText string  `json:"question_text"`
+	OptionA      string  `json:"option_a"`
+	OptionB      string  `json:"option_b"`
+	OptionC      string  `json:"option_c"`
+	OptionD      string  `json:"option_d"`
+	Points       float64 `json:"points"`
+}
+
+func newAdaptiveQuestion(q repo.Question) adaptiveQuestion {
+	return adaptiveQuestion{
+		ID:           q.ID,
+		QuestionText: q.QuestionText,
+		OptionA:      q.OptionA,
+		OptionB:      q.OptionB,
+		OptionC:      q.OptionC,
+		OptionD:      q.OptionD,
+		Points:       q.Points,
+	}
+}
+
+// irtItem returns the calibrated parameters of q, or defaults if it has not
+// been calibrated yet.
+func irtItem(q repo.Question) irt.Item {
+	if q.IrtDifficulty == nil || q.IrtDiscrimination == nil {
+		return irt.DefaultItem(q.ID, q.EmpiricalDifficulty)
+	}
+	return irt.Item{ID: q.ID, Discrimination: *q.IrtDiscrimination, Difficulty: *q.IrtDifficulty}
+}
+
+// adaptivePool loads a quiz's questions as the item pool for adaptive
+// attempts.
+func (h *QuizHandler) adaptivePool(c *gin.Context, quizID string) ([]irt.Item, map[string]repo.Question, error) {
+	questions, err := h.querier.ListQuestionsWithAnswers(c, quizID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pool := make([]irt.Item, len(questions))
+	byID := make(map[string]repo.Question, len(questions))
+	for i, q := range questions {
+		pool[i] = irtItem(q)
+		byID[q.ID] = q
+	}
+
+	return pool, byID, nil
+}
+
+func (h *QuizHandler) handleStartAdaptiveAttempt(c *gin.Context) {
+	var req struct {
+		QuizID   string `json:"quiz_id"`
+		UserName string `json:"user_name"`
+	}
+
+	err := c.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.QuizID == "" || req.UserName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quiz_id and user_name are required"})
+		return
+	}
+
+	eligibility, err := h.querier.GetAttemptEligibility(c, repo.GetAttemptEligibilityParams{
+		ID:       req.QuizID,
+		UserName: req.UserName,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quiz not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	decision := policy.Check(eligibility)
+	if !decision.Allowed {
+		abortWithPolicyDecision(c, decision)
+		return
+	}
+
+	if !h.requireUnlocked(c, req.QuizID, req.UserName) {
+		return
+	}
+
+	pool, questions, err := h.adaptivePool(c, req.QuizID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(pool) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No questions found for this quiz"})
+		return
+	}
+
+	theta, se := irt.EstimateAbility(nil)
+	next, _ := irt.NextItem(theta, pool, nil)
+
+	var attempt repo.QuizAttempt
+	err = repo.ExecTx(c, h.db, func(q repo.Querier) error {
+		decision, err = grading.CheckEligibility(c, q, req.QuizID, req.UserName, policy.ModeAdaptive)
+		if err != nil {
+			return err
+		}
+
+		attempt, err = q.StartAdaptiveAttempt(c, repo.StartAdaptiveAttemptParams{
+			QuizID:            req.QuizID,
+			UserName:          req.UserName,
+			CurrentQuestionID: &next.ID,
+		})
+		return err
+	})
+	var refused grading.RefusedError
+	if errors.As(err, &refused) {
+		abortWithPolicyDecision(c, refused.Decision)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"attempt":        attempt,
+		"question":       newAdaptiveQuestion(questions[next.ID]),
+		"ability":        theta,
+		"standard_error": se,
+	}
+	if decision.AttemptsRemaining != nil {
+		response["attempts_remaining"] = *decision.AttemptsRemaining - 1
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// handleAdaptiveAnswer grades the answer to the current question of an
+// adaptive attempt, updates the ability estimate and either serves the most
+// informative remaining question or, once the estimate is precise enough,
+// submits the attempt. The answer, the attempt's progress and its
+// submission are written together, and a question answered twice, such as
+// by two requests at once, is refused with 409 Conflict.
+func (h *QuizHandler) handleAdaptiveAnswer(c *gin.Context) {
+	var req struct {
+		QuestionID string `json:"question_id"`
+		Answer     string `json:"answer"`
+	}
+
+	err := c.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attempt, err := h.querier.GetQuizAttemptByID(c, c.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "attempt not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if attempt.Mode != policy.ModeAdaptive {
+		c.JSON(http.StatusConflict, gin.H{"error": "attempt is not adaptive"})
+		return
+	}
+	if attempt.Status != attemptInProgress {
+		c.JSON(http.StatusConflict, gin.H{"error": "attempt has already been submitted"})
+		return
+	}
+
+	quiz, err := h.querier.GetQuizByID(c, attempt.QuizID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	pool, questions, err := h.adaptivePool(c, attempt.QuizID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous, err := h.querier.GetAttemptAnswers(c, attempt.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	answered := make(map[string]bool, len(previous)+1)
+	responses := make([]irt.Response, 0, len(previous)+1)
+	graded := make([]scoring.Question, 0, len(previous)+1)
+	given := make(map[string]string, len(previous)+1)
+	for _, a := range previous {
+		if q, ok := questions[a.QuestionID]; ok {
+			answered[q.ID] = true
+			responses = append(responses, irt.Response{Item: irtItem(q), Correct: a.IsCorrect})
+			graded = append(graded, scoring.Question{ID: q.ID, CorrectAnswer: q.CorrectAnswer, Points: q.Points})
+			given[q.ID] = a.Answer
+		}
+	}
+
+	// The attempt stores the question it is waiting on. Attempts started
+	// before it did, or whose question was deleted, wait on the most
+	// informative remaining question instead.
+	currentID := ""
+	if id := attempt.CurrentQuestionID; id != nil && !answered[*id] {
+		if _, ok := questions[*id]; ok {
+			currentID = *id
+		}
+	}
+	if currentID == "" {
+		theta, _ := irt.EstimateAbility(responses)
+		if next, ok := irt.NextItem(theta, pool, answered); ok {
+			currentID = next.ID
+		}
+	}
+	if currentID == "" || currentID != req.QuestionID {
+		c.JSON(http.StatusConflict, gin.H{"error": "question is not the current question of this attempt", "current_question_id": currentID})
+		return
+	}
+
+	question := questions[currentID]
+	if !validAnswer(req.Answer, question.OptionA, question.OptionB, question.OptionC, question.OptionD) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "answer must be one of the question's options, A to D, or empty to skip"})
+		return
+	}
+	graded = append(graded, scoring.Question{ID: question.ID, CorrectAnswer: question.CorrectAnswer, Points: question.Points})
+	given[question.ID] = req.Answer
+	result := scoring.GradeAnswer(graded[len(graded)-1], req.Answer, scoring.Rules{WrongAnswerPenalty: quiz.WrongAnswerPenalty})
+
+	answered[question.ID] = true
+	responses = append(responses, irt.Response{Item: irtItem(question), Correct: result.Correct})
+	theta, se := irt.EstimateAbility(responses)
+
+	done := irt.Done(se, len(responses), len(pool))
+	var next *string
+	if !done {
+		item, _ := irt.NextItem(theta, pool, answered)
+		next = &item.ID
+	}
+
+	var submitted grading.Submitted
+	err = repo.ExecTx(c, h.db, func(q repo.Querier) error {
+		err := q.CreateAttemptAnswers(c, grading.AnswersParams(attempt.ID,
+			scoring.Summary{Results: []scoring.Result{result}},
+			map[string]string{question.ID: req.Answer}))
+		if err != nil {
+			return err
+		}
+
+		attempt, err = q.UpdateAdaptiveAttempt(c, repo.UpdateAdaptiveAttemptParams{
+			ID:                 attempt.ID,
+			Score:              attempt.Score + result.Points,
+			MaxPoints:          attempt.MaxPoints + question.Points,
+			TotalQuestions:     int32(len(responses)),
+			Ability:            &theta,
+			AbilitySe:          &se,
+			NextQuestionID:     next,
+			AnsweredQuestionID: attempt.CurrentQuestionID,
+		})
+		if err != nil || !done {
+			return err
+		}
+
+		// The answers were recorded as they were given, so submitting
+		// grades them again only to total the score
+		submitted, err = grading.Submit(c, q, grading.Submission{
+			Quiz:      quiz,
+			AttemptID: attempt.ID,
+			Questions: graded,
+			Answers:   given,
+			Stored:    true,
+		})
+		return err
+	})
+	if repo.IsUniqueViolation(err) || errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "question has already been answered or the attempt submitted"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"result":         result,
+		"ability":        theta,
+		"standard_error": se,
+	}
+
+	if !done {
+		response["attempt"] = attempt
+		response["question"] = newAdaptiveQuestion(questions[*next])
+		response["done"] = false
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	err = grading.RecalculateDifficulty(c, h.querier, quiz.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response["attempt"] = submitted.Attempt
+	response["done"] = true
+	if submitted.Certificate != nil {
+		response["certificate"] = certificateLinks(c, submitted.Certificate.VerificationCode)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Iknite-Space/sqlc-example-api/analysis"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// handleItemAnalysis reports classical item statistics for every question of
+// a quiz, as JSON or, with ?format=csv, as a CSV download.
+func (h *QuizHandler) handleItemAnalysis(c *gin.Context) {
+	quiz, err := h.querier.GetQuizByID(c, c.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quiz not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	questions, err := h.querier.ListQuestionsWithAnswers(c, quiz.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := h.querier.GetItemResponses(c, quiz.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items := make([]analysis.Question, len(questions))
+	for i, q := range questions {
+		items[i] = analysis.Question{
+			ID:            q.ID,
+			Text:          q.QuestionText,
+			CorrectAnswer: q.CorrectAnswer,
+			Points:        q.Points,
+		}
+	}
+
+	responses := make([]analysis.Response, len(rows))
+	for i, r := range rows {
+		responses[i] = analysis.Response{
+			AttemptID:  r.AttemptID,
+			QuestionID: r.QuestionID,
+			Answer:     r.Answer,
+			Correct:    r.IsCorrect,
+			Points:     r.PointsAwarded,
+			Score:      r.Score,
+			MaxPoints:  r.MaxPoints,
+		}
+	}
+
+	report := analysis.Analyze(items, responses)
+
+	if c.Query("format") == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=item-analysis-%s.csv", quiz.ID))
+		err = analysis.WriteCSV(c.Writer, report)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, struct {
+		QuizID    string `json:"quiz_id"`
+		QuizTitle string `json:"quiz_title"`
+		analysis.Report
+	}{quiz.ID, quiz.Title, report})
+}
@@ -1,19 +1,42 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/difficulty"
+	"github.com/Iknite-Space/sqlc-example-api/grading"
+	"github.com/Iknite-Space/sqlc-example-api/lint"
+	"github.com/Iknite-Space/sqlc-example-api/policy"
+	"github.com/Iknite-Space/sqlc-example-api/scoring"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultHistogramBuckets = 10
+	maxHistogramBuckets     = 100
 )
 
 type QuizHandler struct {
 	querier repo.Querier
+	// db starts transactions for handlers that make several writes which
+	// must succeed or fail together.
+	db repo.TxBeginner
+	// stream runs the queries that exports read row by row.
+	stream repo.Streamer
+	// lint is the content linter's rules.
+	lint lint.Config
 }
 
-func NewQuizHandler(querier repo.Querier) *QuizHandler {
+func NewQuizHandler(querier repo.Querier, db repo.TxBeginner, stream repo.Streamer, rules lint.Config) *QuizHandler {
 	return &QuizHandler{
 		querier: querier,
+		db:      db,
+		stream:  stream,
+		lint:    rules,
 	}
 }
 
@@ -30,37 +53,113 @@ func (h *QuizHandler) WireHttpHandler() http.Handler {
 	r.POST("/quizzes", h.handleCreateQuiz)
 	r.GET("/quizzes/:id", h.handleGetQuiz)
 	r.GET("/quizzes/:id/questions", h.handleGetQuizQuestions)
+	r.POST("/quizzes/:id/questions/import", h.handlePreviewQuestionImport)
+	r.POST("/quizzes/:id/questions/import/:import_id/commit", h.handleCommitQuestionImport)
     r.DELETE("/quizzes/:id", h.handleDeleteQuiz)
 	r.PUT("/quizzes/:id", h.handleUpdateQuiz)
 	r.GET("/quizzes/:id/stats", h.handleQuizStats)
 	r.GET("/quizzes/:id/attempts", h.handleListQuizAttempts)
+	r.GET("/quizzes/:id/eligibility", h.handleAttemptEligibility)
+	r.GET("/quizzes/:id/item-analysis", h.handleItemAnalysis)
+	r.GET("/quizzes/:id/export", h.handleExportQuiz)
+	r.GET("/quizzes/:id/lint", h.handleLintQuiz)
+	r.GET("/quizzes/:id/print", h.handlePrintQuiz)
+	r.POST("/quizzes/:id/print/results", h.handleUploadPaperResults)
+	r.POST("/quizzes/import", h.handleImportQuiz)
+	r.GET("/quizzes/:id/tags", h.handleListQuizTags)
+	r.POST("/quizzes/:id/tags/:tag", h.handleAddQuizTag)
+	r.DELETE("/quizzes/:id/tags/:tag", h.handleRemoveQuizTag)
 
 	// Question endpoints
 	r.POST("/questions", h.handleCreateQuestion)
 	r.PUT("/questions/:id", h.handleUpdateQuestion)
 	r.DELETE("/questions/:id", h.handleDeleteQuestion)
+	r.GET("/questions/:id/tags", h.handleListQuestionTags)
+	r.POST("/questions/:id/tags/:tag", h.handleAddQuestionTag)
+	r.DELETE("/questions/:id/tags/:tag", h.handleRemoveQuestionTag)
+	r.GET("/questions/:id/media", h.handleListQuestionMedia)
+	r.GET("/questions/:id/media/:name", h.handleGetQuestionMedia)
+
+	// Player endpoints
+	r.GET("/players/:name/progress", h.handlePlayerProgress)
+	r.GET("/players/:name/review", h.handleListReviews)
+	r.POST("/players/:name/review/:question_id", h.handleGradeReview)
+	r.GET("/me/review", h.handleListReviews)
+	r.POST("/me/review/:question_id", h.handleGradeReview)
+	r.GET("/players/:name/collections/:slug", h.handlePlayerCollection)
+
+	// Search endpoint
+	r.GET("/search", h.handleSearch)
+
+	// Tag endpoints
+	r.GET("/tags", h.handleListTags)
+	r.POST("/tags", h.handleCreateTag)
+	r.PUT("/tags/:tag", h.handleUpdateTag)
+	r.DELETE("/tags/:tag", h.handleDeleteTag)
+
+	// Collection endpoints
+	r.GET("/collections", h.handleListCollections)
+	r.POST("/collections", h.handleCreateCollection)
+	r.GET("/collections/:slug", h.handleGetCollection)
+	r.DELETE("/collections/:slug", h.handleDeleteCollection)
+	r.POST("/collections/:slug/quizzes/:quiz_id", h.handleAddCollectionQuiz)
+	r.DELETE("/collections/:slug/quizzes/:quiz_id", h.handleRemoveCollectionQuiz)
 
 	// Attempt endpoints
 	r.POST("/attempts", h.handleCreateAttempt)
+	r.POST("/attempts/start", h.handleStartAttempt)
+	r.POST("/attempts/adaptive", h.handleStartAdaptiveAttempt)
+	r.POST("/attempts/:id/answer", h.handleAdaptiveAnswer)
+	r.GET("/attempts/:id", h.handleGetAttempt)
+	r.POST("/attempts/:id/questions/:qid/hint", h.handleUseHint)
 	r.GET("/leaderboard/:quiz_id", h.handleLeaderboard)
 
+	// Report endpoints
+	r.GET("/reports/attempts", h.handleExportAttempts)
+	r.GET("/reports/gradebook", h.handleExportGradebook)
+
+	// Certificate endpoints
+	r.GET("/certificates/:code", h.handleGetCertificate)
+	r.GET("/certificates/:code/verify", h.handleVerifyCertificate)
+
 
 	return r
 }
 
 // Quiz handlers
 func (h *QuizHandler) handleListQuizzes(c *gin.Context) {
-	quizzes, err := h.querier.ListQuizzes(c)
+	levels, err := difficultyFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var quizzes []repo.Quiz
+	if filter, ok := tagFilter(c); ok {
+		quizzes, err = h.querier.ListQuizzesByTags(c, filter)
+	} else {
+		quizzes, err = h.querier.ListQuizzes(c)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if !levels.Empty() {
+		filtered := make([]repo.Quiz, 0, len(quizzes))
+		for _, q := range quizzes {
+			if levels.Contains(q.Difficulty, q.EmpiricalDifficulty) {
+				filtered = append(filtered, q)
+			}
+		}
+		quizzes = filtered
+	}
+
 	c.JSON(http.StatusOK, quizzes)
 }
 
 func (h *QuizHandler) handleCreateQuiz(c *gin.Context) {
-	var req repo.CreateQuizParams
+	req := repo.CreateQuizParams{HintPenalty: scoring.DefaultHintPenalty}
 
 	err := c.ShouldBindBodyWithJSON(&req)
 	if err != nil {
@@ -68,7 +167,35 @@ func (h *QuizHandler) handleCreateQuiz(c *gin.Context) {
 		return
 	}
 
-	quiz, err := h.querier.CreateQuiz(c, req)
+	if req.RevealPolicy == "" {
+		req.RevealPolicy = policy.RevealAfterAttempt
+	}
+	if !policy.ValidRevealPolicy(req.RevealPolicy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reveal_policy must be never, after_attempt or after_close"})
+		return
+	}
+	if !difficulty.Valid(req.Difficulty) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidDifficulty})
+		return
+	}
+
+	var quiz repo.Quiz
+	var findings []lint.Finding
+	err = repo.ExecTx(c, h.db, func(q repo.Querier) error {
+		quiz, err = q.CreateQuiz(c, req)
+		if err != nil {
+			return err
+		}
+		findings, err = h.lintErrors(c, q, quiz.ID)
+		if err == nil && len(findings) > 0 {
+			err = errLintErrors
+		}
+		return err
+	})
+	if errors.Is(err, errLintErrors) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "findings": findings})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -116,13 +243,52 @@ func (h *QuizHandler)  handleQuizStats(c *gin.Context) {
 		return
 	}
 
+	buckets := defaultHistogramBuckets
+	if v := c.Query("buckets"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxHistogramBuckets {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "buckets must be between 1 and 100"})
+			return
+		}
+		buckets = n
+	}
+
+	period := c.DefaultQuery("period", "day")
+	if period != "day" && period != "week" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be day or week"})
+		return
+	}
+
 	stats, err := h.querier.GetQuizStats(c, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, stats)
+	histogram, err := h.querier.GetQuizScoreHistogram(c, repo.GetQuizScoreHistogramParams{
+		QuizID:  id,
+		Buckets: int32(buckets),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	trend, err := h.querier.GetQuizScoreTrend(c, repo.GetQuizScoreTrendParams{
+		Period: period,
+		QuizID: id,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, struct {
+		repo.GetQuizStatsRow
+		Histogram []repo.GetQuizScoreHistogramRow `json:"histogram"`
+		Period    string                          `json:"period"`
+		Trend     []repo.GetQuizScoreTrendRow     `json:"trend"`
+	}{stats, histogram, period, trend})
 }
 
 func (h *QuizHandler)  handleDeleteQuestion(c *gin.Context) {
@@ -183,7 +349,35 @@ func (h *QuizHandler) handleCreateQuestion(c *gin.Context) {
 		return
 	}
 
-	question, err := h.querier.CreateQuestion(c, req)
+	if req.Points == 0 {
+		req.Points = 1
+	}
+	if !difficulty.Valid(req.Difficulty) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidDifficulty})
+		return
+	}
+	if !scoring.ValidAnswer(req.CorrectAnswer, req.OptionA, req.OptionB, req.OptionC, req.OptionD) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidCorrectAnswer})
+		return
+	}
+
+	var question repo.Question
+	var findings []lint.Finding
+	err = repo.ExecTx(c, h.db, func(q repo.Querier) error {
+		question, err = q.CreateQuestion(c, req)
+		if err != nil {
+			return err
+		}
+		findings, err = h.lintErrors(c, q, question.QuizID, exportedID(question))
+		if err == nil && len(findings) > 0 {
+			err = errLintErrors
+		}
+		return err
+	})
+	if errors.Is(err, errLintErrors) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "findings": findings})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -195,9 +389,15 @@ func (h *QuizHandler) handleCreateQuestion(c *gin.Context) {
 // Attempt handlers
 func (h *QuizHandler) handleCreateAttempt(c *gin.Context) {
 	var req struct {
-		QuizID   string            `json:"quiz_id"`
-		UserName string            `json:"user_name"`
-		Answers  map[string]string `json:"answers"`
+		QuizID   string `json:"quiz_id"`
+		UserName string `json:"user_name"`
+		// AttemptID submits an attempt started with POST /attempts/start,
+		// applying any hint penalties recorded against it.
+		AttemptID string            `json:"attempt_id"`
+		Answers   map[string]string `json:"answers"`
+		// Mode is exam (the default), practice or flashcard. A started
+		// attempt keeps the mode it was started with.
+		Mode string `json:"mode"`
 	}
 
 	err := c.ShouldBindBodyWithJSON(&req)
@@ -211,6 +411,79 @@ func (h *QuizHandler) handleCreateAttempt(c *gin.Context) {
 		return
 	}
 
+	if req.Mode == "" {
+		req.Mode = policy.ModeExam
+	}
+	if !policy.ValidMode(req.Mode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be exam, practice or flashcard"})
+		return
+	}
+
+	// Enforce the quiz's availability window, attempt limit and cooldown
+	eligibility, err := h.querier.GetAttemptEligibility(c, repo.GetAttemptEligibilityParams{
+		ID:       req.QuizID,
+		UserName: req.UserName,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quiz not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// A started attempt already passed the policy check and counts towards
+	// the attempt limit, so only new attempts are checked here.
+	decision := policy.Check(eligibility)
+	hintsUsed := make(map[string]int)
+
+	if req.AttemptID != "" {
+		started, err := h.querier.GetQuizAttemptByID(c, req.AttemptID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "attempt not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if started.QuizID != req.QuizID || started.UserName != req.UserName {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "attempt does not belong to this quiz and player"})
+			return
+		}
+		if started.Status != attemptInProgress {
+			c.JSON(http.StatusConflict, gin.H{"error": "attempt has already been submitted"})
+			return
+		}
+		if started.Mode == policy.ModeAdaptive {
+			c.JSON(http.StatusConflict, gin.H{"error": "adaptive attempts are answered one question at a time"})
+			return
+		}
+		req.Mode = started.Mode
+
+		hints, err := h.querier.GetAttemptHints(c, req.AttemptID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for _, hint := range hints {
+			hintsUsed[hint.QuestionID] = int(hint.HintsUsed)
+		}
+	} else if !decision.Allowed && (policy.Official(req.Mode) || !decision.AllowsUnofficial()) {
+		abortWithPolicyDecision(c, decision)
+		return
+	} else if !h.requireUnlocked(c, req.QuizID, req.UserName) {
+		return
+	}
+
+	quiz, err := h.querier.GetQuizByID(c, req.QuizID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Get all questions for the quiz
 	questions, err := h.querier.GetQuestionsByQuizID(c, req.QuizID)
 	if err != nil {
@@ -223,9 +496,18 @@ func (h *QuizHandler) handleCreateAttempt(c *gin.Context) {
 		return
 	}
 
-	// Calculate score
-	score := 0
-	results := make([]map[string]interface{}, 0)
+	// Answers to questions outside the quiz are not graded, so only the
+	// quiz's questions are checked
+	for _, q := range questions {
+		if !validAnswer(req.Answers[q.ID], q.OptionA, q.OptionB, q.OptionC, q.OptionD) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "answer for question " + q.ID + " must be one of its options, A to D"})
+			return
+		}
+	}
+
+	// Collect the correct answers and point values for grading
+	graded := make([]scoring.Question, 0, len(questions))
+	fullQuestions := make(map[string]repo.Question, len(questions))
 
 	for _, q := range questions {
 		// Get the full question with correct answer
@@ -234,35 +516,97 @@ func (h *QuizHandler) handleCreateAttempt(c *gin.Context) {
 			continue
 		}
 
-		userAnswer := req.Answers[q.ID]
-		isCorrect := userAnswer == fullQuestion.CorrectAnswer
+		graded = append(graded, scoring.Question{
+			ID:            fullQuestion.ID,
+			CorrectAnswer: fullQuestion.CorrectAnswer,
+			Points:        fullQuestion.Points,
+			HintsUsed:     hintsUsed[fullQuestion.ID],
+		})
+		fullQuestions[fullQuestion.ID] = fullQuestion
+	}
 
-		if isCorrect {
-			score++
+	// The attempt, its answers, review queue entries and certificate are
+	// recorded together or not at all
+	var submitted grading.Submitted
+	err = repo.ExecTx(c, h.db, func(q repo.Querier) error {
+		// New attempts are checked again under the player's lock; the check
+		// above only refuses early
+		if req.AttemptID == "" {
+			decision, err = grading.CheckEligibility(c, q, req.QuizID, req.UserName, req.Mode)
+			if err != nil {
+				return err
+			}
 		}
 
-		results = append(results, map[string]interface{}{
-			"question_id": q.ID,
-			"correct":     isCorrect,
+		submitted, err = grading.Submit(c, q, grading.Submission{
+			Quiz:      quiz,
+			AttemptID: req.AttemptID,
+			UserName:  req.UserName,
+			Mode:      req.Mode,
+			Questions: graded,
+			Answers:   req.Answers,
 		})
+		return err
+	})
+	var refused grading.RefusedError
+	if errors.As(err, &refused) {
+		abortWithPolicyDecision(c, refused.Decision)
+		return
+	}
+	if req.AttemptID != "" && errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "attempt has already been submitted"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
+	attempt := submitted.Attempt
 
-	// Save attempt
-	attempt, err := h.querier.CreateQuizAttempt(c, repo.CreateQuizAttemptParams{
-		QuizID:         req.QuizID,
-		UserName:       req.UserName,
-		Score:          int32(score),
-		TotalQuestions: int32(len(questions)),
-	})
+	err = grading.RecalculateDifficulty(c, h.querier, quiz.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"attempt": attempt,
-		"results": results,
-	})
+	reveal := policy.RevealAnswers(quiz.RevealPolicy, quiz.ClosesAt, decision.Now)
+	results := make([]attemptResult, 0, len(submitted.Summary.Results))
+	for _, r := range submitted.Summary.Results {
+		result := attemptResult{Result: r}
+		if reveal {
+			result.CorrectAnswer = fullQuestions[r.QuestionID].CorrectAnswer
+			result.Explanation = fullQuestions[r.QuestionID].Explanation
+		}
+		results = append(results, result)
+	}
+
+	percentile, err := h.querier.GetAttemptPercentile(c, attempt.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"attempt":          attempt,
+		"results":          results,
+		"answers_revealed": reveal,
+		"percentile":       percentile,
+	}
+
+	if decision.AttemptsRemaining != nil {
+		remaining := *decision.AttemptsRemaining
+		if req.AttemptID == "" && attempt.Official {
+			remaining--
+		}
+		response["attempts_remaining"] = remaining
+	}
+
+	// Certificates are only issued for official attempts
+	if submitted.Certificate != nil {
+		response["certificate"] = certificateLinks(c, submitted.Certificate.VerificationCode)
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *QuizHandler) handleLeaderboard(c *gin.Context) {
@@ -284,7 +628,7 @@ func (h *QuizHandler) handleLeaderboard(c *gin.Context) {
 func (h *QuizHandler) handleUpdateQuiz(c *gin.Context) {
     id := c.Param("id")
     
-    var req repo.UpdateQuizParams
+    req := repo.UpdateQuizParams{HintPenalty: scoring.DefaultHintPenalty}
     if err := c.ShouldBindJSON(&req); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
@@ -292,8 +636,36 @@ func (h *QuizHandler) handleUpdateQuiz(c *gin.Context) {
     
     
     req.ID = id
+    if req.RevealPolicy == "" {
+        req.RevealPolicy = policy.RevealAfterAttempt
+    }
+    if !policy.ValidRevealPolicy(req.RevealPolicy) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "reveal_policy must be never, after_attempt or after_close"})
+        return
+    }
+    if !difficulty.Valid(req.Difficulty) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": invalidDifficulty})
+        return
+    }
     
-    quiz, err := h.querier.UpdateQuiz(c, req)
+    var quiz repo.Quiz
+    var findings []lint.Finding
+    err := repo.ExecTx(c, h.db, func(q repo.Querier) error {
+        var err error
+        quiz, err = q.UpdateQuiz(c, req)
+        if err != nil {
+            return err
+        }
+        findings, err = h.lintErrors(c, q, quiz.ID)
+        if err == nil && len(findings) > 0 {
+            err = errLintErrors
+        }
+        return err
+    })
+    if errors.Is(err, errLintErrors) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "findings": findings})
+        return
+    }
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
@@ -313,12 +685,40 @@ func (h *QuizHandler) handleUpdateQuestion(c *gin.Context) {
     
     
     req.ID = id
+    if req.Points == 0 {
+        req.Points = 1
+    }
+    if !difficulty.Valid(req.Difficulty) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": invalidDifficulty})
+        return
+    }
+    if !scoring.ValidAnswer(req.CorrectAnswer, req.OptionA, req.OptionB, req.OptionC, req.OptionD) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": invalidCorrectAnswer})
+        return
+    }
     
-    q, err := h.querier.UpdateQuestion(c, req)
+    var question repo.Question
+    var findings []lint.Finding
+    err := repo.ExecTx(c, h.db, func(q repo.Querier) error {
+        var err error
+        question, err = q.UpdateQuestion(c, req)
+        if err != nil {
+            return err
+        }
+        findings, err = h.lintErrors(c, q, question.QuizID, exportedID(question))
+        if err == nil && len(findings) > 0 {
+            err = errLintErrors
+        }
+        return err
+    })
+    if errors.Is(err, errLintErrors) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "findings": findings})
+        return
+    }
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
     
-    c.JSON(http.StatusOK, q)
+    c.JSON(http.StatusOK, question)
 }
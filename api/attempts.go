@@ -0,0 +1,260 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/grading"
+	"github.com/Iknite-Space/sqlc-example-api/policy"
+	"github.com/Iknite-Space/sqlc-example-api/scoring"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// attemptInProgress is the status of an attempt started with
+// POST /attempts/start that has not been submitted yet.
+const attemptInProgress = "in_progress"
+
+// attemptResult is a graded question as returned to the player. The correct
+// answer and explanation are only filled in when the quiz's reveal policy
+// allows it.
+type attemptResult struct {
+	scoring.Result
+	CorrectAnswer string `json:"correct_answer,omitempty"`
+	Explanation   string `json:"explanation,omitempty"`
+}
+
+// reviewedAnswer is a stored answer shown when reviewing a past attempt.
+type reviewedAnswer struct {
+	attemptResult
+	QuestionText string  `json:"question_text"`
+	OptionA      string  `json:"option_a"`
+	OptionB      string  `json:"option_b"`
+	OptionC      string  `json:"option_c"`
+	OptionD      string  `json:"option_d"`
+	Answer       string  `json:"answer"`
+	MaxPoints    float64 `json:"max_points"`
+}
+
+func (h *QuizHandler) handleStartAttempt(c *gin.Context) {
+	var req struct {
+		QuizID   string `json:"quiz_id"`
+		UserName string `json:"user_name"`
+		Mode     string `json:"mode"`
+	}
+
+	err := c.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.QuizID == "" || req.UserName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quiz_id and user_name are required"})
+		return
+	}
+
+	if req.Mode == "" {
+		req.Mode = policy.ModeExam
+	}
+	if !policy.ValidMode(req.Mode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be exam, practice or flashcard"})
+		return
+	}
+
+	eligibility, err := h.querier.GetAttemptEligibility(c, repo.GetAttemptEligibilityParams{
+		ID:       req.QuizID,
+		UserName: req.UserName,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quiz not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	decision := policy.Check(eligibility)
+	if !decision.Allowed && (policy.Official(req.Mode) || !decision.AllowsUnofficial()) {
+		abortWithPolicyDecision(c, decision)
+		return
+	}
+
+	if !h.requireUnlocked(c, req.QuizID, req.UserName) {
+		return
+	}
+
+	questions, err := h.querier.GetQuestionsByQuizID(c, req.QuizID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(questions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No questions found for this quiz"})
+		return
+	}
+
+	// The check above only refuses early; the attempt is started under the
+	// player's lock once it has been checked again
+	attempt, decision, err := grading.Start(c, h.db, repo.StartQuizAttemptParams{
+		QuizID:         req.QuizID,
+		UserName:       req.UserName,
+		TotalQuestions: int32(len(questions)),
+		Mode:           req.Mode,
+	})
+	var refused grading.RefusedError
+	if errors.As(err, &refused) {
+		abortWithPolicyDecision(c, refused.Decision)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"attempt":   attempt,
+		"questions": questions,
+	}
+	if decision.AttemptsRemaining != nil && attempt.Official {
+		response["attempts_remaining"] = *decision.AttemptsRemaining - 1
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *QuizHandler) handleUseHint(c *gin.Context) {
+	attemptID := c.Param("id")
+	questionID := c.Param("qid")
+
+	attempt, err := h.querier.GetQuizAttemptByID(c, attemptID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "attempt not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if attempt.Status != attemptInProgress {
+		c.JSON(http.StatusConflict, gin.H{"error": "hints can only be used on an attempt in progress"})
+		return
+	}
+
+	question, err := h.querier.GetQuestionByID(c, questionID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && question.QuizID != attempt.QuizID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "question not found in this quiz"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(question.Hints) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "this question has no hints"})
+		return
+	}
+
+	hintsUsed, err := h.querier.UseHint(c, repo.UseHintParams{
+		AttemptID:  attemptID,
+		QuestionID: questionID,
+		MaxHints:   int32(len(question.Hints)),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "all hints for this question have been revealed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	quiz, err := h.querier.GetQuizByID(c, attempt.QuizID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"question_id":      questionID,
+		"hint":             question.Hints[hintsUsed-1],
+		"hints_used":       hintsUsed,
+		"hints_remaining":  len(question.Hints) - int(hintsUsed),
+		"points_available": scoring.AvailablePoints(question.Points, int(hintsUsed), quiz.HintPenalty),
+	})
+}
+
+func (h *QuizHandler) handleGetAttempt(c *gin.Context) {
+	id := c.Param("id")
+
+	review, err := h.querier.GetAttemptReview(c, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "attempt not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	answers, err := h.querier.GetAttemptAnswers(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	reveal := policy.RevealAnswers(review.RevealPolicy, review.ClosesAt, review.CheckedAt.Time)
+	results := make([]reviewedAnswer, 0, len(answers))
+	for _, a := range answers {
+		result := reviewedAnswer{
+			attemptResult: attemptResult{Result: scoring.Result{
+				QuestionID: a.QuestionID,
+				Correct:    a.IsCorrect,
+				Skipped:    a.Answer == "",
+				Points:     a.PointsAwarded,
+			}},
+			QuestionText: a.QuestionText,
+			OptionA:      a.OptionA,
+			OptionB:      a.OptionB,
+			OptionC:      a.OptionC,
+			OptionD:      a.OptionD,
+			Answer:       a.Answer,
+			MaxPoints:    a.Points,
+		}
+		if reveal {
+			result.CorrectAnswer = a.CorrectAnswer
+			result.Explanation = a.Explanation
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attempt": repo.QuizAttempt{
+			ID:             review.ID,
+			QuizID:         review.QuizID,
+			UserName:       review.UserName,
+			Score:          review.Score,
+			TotalQuestions: review.TotalQuestions,
+			CreatedAt:      review.CreatedAt,
+			MaxPoints:      review.MaxPoints,
+			Passed:         review.Passed,
+			Status:         review.Status,
+		},
+		"quiz_title":       review.QuizTitle,
+		"results":          results,
+		"answers_revealed": reveal,
+	})
+}
+
+const invalidCorrectAnswer = "correct_answer must be the letter of a filled in option, A to D"
+
+// validAnswer reports whether answer is one of the question's options, A to
+// D, or blank for a skipped question.
+func validAnswer(answer string, optionA, optionB, optionC, optionD string) bool {
+	return answer == "" || scoring.ValidAnswer(answer, optionA, optionB, optionC, optionD)
+}
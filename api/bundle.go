@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Iknite-Space/sqlc-example-api/bundle"
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/lint"
+	"github.com/Iknite-Space/sqlc-example-api/moodle"
+	"github.com/Iknite-Space/sqlc-example-api/qti"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// handleExportQuiz downloads a quiz as a portable JSON bundle or, with
+// ?format=moodle or ?format=gift, as a Moodle question bank. With
+// ?format=qti it downloads a QTI package, of the QTI version given by
+// ?version (2.1 by default).
+func (h *QuizHandler) handleExportQuiz(c *gin.Context) {
+	format := c.DefaultQuery("format", "bundle")
+	if format != "bundle" && format != "moodle" && format != "gift" && format != "qti" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be bundle, moodle, gift or qti"})
+		return
+	}
+	version := qti.Version(c.DefaultQuery("version", string(qti.V21)))
+	if format == "qti" && !qti.ValidVersion(version) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version must be 2.1 or 3.0"})
+		return
+	}
+
+	b, err := bundle.Export(c, h.querier, c.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quiz not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "moodle":
+		err = moodle.WriteXML(&buf, moodle.FromQuiz(b.Quiz))
+	case "gift":
+		// GIFT has no points or hints; Moodle XML keeps them
+		_, err = moodle.WriteGIFT(&buf, moodle.FromQuiz(b.Quiz))
+	case "qti":
+		// What packages cannot hold, such as hints, is left out
+		_, err = qti.Write(&buf, b.Quiz, version)
+	default:
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, b.Quiz.Slug))
+		c.JSON(http.StatusOK, b)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	contentType, ext := "application/xml; charset=utf-8", "xml"
+	switch format {
+	case "gift":
+		contentType, ext = "text/plain; charset=utf-8", "txt"
+	case "qti":
+		contentType, ext = "application/zip", "zip"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, b.Quiz.Slug, ext))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// handleImportQuiz creates a quiz from a bundle in a single transaction, so
+// a bundle that fails part way imports nothing. The conflict query parameter
+// decides what happens when the quiz's slug is already in use.
+//
+// With ?format=qti the body is a QTI package instead, and the response lists
+// what could not be imported from it as warnings. A package without an
+// assessment test takes its quiz title from ?title.
+//
+// Either way the response lists the content linter's findings about the
+// imported quiz. Error findings stop the import, others are only reported.
+func (h *QuizHandler) handleImportQuiz(c *gin.Context) {
+	conflict := bundle.Conflict(c.DefaultQuery("conflict", string(bundle.Skip)))
+	if !bundle.ValidConflict(conflict) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "conflict must be skip, overwrite or copy"})
+		return
+	}
+	format := c.DefaultQuery("format", "bundle")
+	if format != "bundle" && format != "qti" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be bundle or qti"})
+		return
+	}
+
+	var b bundle.Bundle
+	var warnings []qti.Issue
+	var err error
+	if format == "qti" {
+		b, warnings, err = readQTI(c)
+	} else {
+		b, err = bundle.Decode(c.Request.Body)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	findings := lint.Quiz(b.Quiz, h.lint)
+	if lint.Count(findings, lint.Error) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":    errLintErrors.Error(),
+			"findings": findings,
+			"warnings": warnings,
+		})
+		return
+	}
+
+	var result bundle.Result
+	err = repo.ExecTx(c, h.db, func(q repo.Querier) error {
+		result, err = bundle.Import(c, q, b, conflict)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format == "qti" {
+		c.JSON(http.StatusOK, gin.H{
+			"status":    result.Status,
+			"quiz":      result.Quiz,
+			"questions": result.Questions,
+			"warnings":  warnings,
+			"findings":  findings,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, struct {
+		bundle.Result
+		Findings []lint.Finding `json:"findings"`
+	}{result, findings})
+}
+
+// readQTI reads the QTI package in the request body into a bundle.
+func readQTI(c *gin.Context) (bundle.Bundle, []qti.Issue, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, qti.MaxSize))
+	if err != nil {
+		return bundle.Bundle{}, nil, err
+	}
+
+	quiz, warnings, err := qti.Read(bytes.NewReader(body), int64(len(body)), c.Query("title"))
+	if err != nil {
+		return bundle.Bundle{}, nil, err
+	}
+
+	b, err := bundle.New(quiz)
+	return b, warnings, err
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Iknite-Space/sqlc-example-api/certificate"
+	"github.com/Iknite-Space/sqlc-example-api/scoring"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Certificate handlers
+func (h *QuizHandler) handleGetCertificate(c *gin.Context) {
+	code := c.Param("code")
+
+	cert, err := h.querier.GetCertificateByCode(c, code)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "certificate not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	data := certificate.NewData(cert, certificateURL(c, cert.VerificationCode, "/verify"))
+
+	if c.Query("format") == "pdf" {
+		c.Header("Content-Type", "application/pdf")
+		c.Header("Content-Disposition", fmt.Sprintf("inline; filename=certificate-%s.pdf", cert.VerificationCode))
+		err = certificate.RenderPDF(c.Writer, data)
+	} else {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		err = certificate.RenderHTML(c.Writer, data)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+}
+
+func (h *QuizHandler) handleVerifyCertificate(c *gin.Context) {
+	code := c.Param("code")
+
+	cert, err := h.querier.GetCertificateByCode(c, code)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"valid": false, "error": "certificate not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"valid":             true,
+		"verification_code": cert.VerificationCode,
+		"user_name":         cert.UserName,
+		"quiz_id":           cert.QuizID,
+		"quiz_title":        cert.QuizTitle,
+		"score":             cert.Score,
+		"max_points":        cert.MaxPoints,
+		"score_percent":     scoring.Percent(cert.Score, cert.MaxPoints),
+		"issued_at":         cert.IssuedAt,
+	})
+}
+
+// certificateLinks describes an issued certificate in an attempt response:
+// its verification code and the URLs to download and verify it.
+func certificateLinks(c *gin.Context, code string) gin.H {
+	return gin.H{
+		"verification_code": code,
+		"url":               certificateURL(c, code, ""),
+		"verify_url":        certificateURL(c, code, "/verify"),
+	}
+}
+
+// certificateURL builds the absolute URL of a certificate resource for the
+// host the request was made against.
+func certificateURL(c *gin.Context, code, suffix string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s/certificates/%s%s", scheme, c.Request.Host, code, suffix)
+}
@@ -0,0 +1,471 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Iknite-Space/sqlc-example-api/collection"
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/slug"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type collectionQuiz struct {
+	Position      int32                `json:"position"`
+	QuizID        string               `json:"quiz_id"`
+	Title         string               `json:"title"`
+	PassPercent   *float64             `json:"pass_percent"`
+	Prerequisites []prerequisiteStatus `json:"prerequisites"`
+}
+
+type prerequisiteStatus struct {
+	QuizID          string   `json:"quiz_id"`
+	Title           string   `json:"title"`
+	RequiredPercent *float64 `json:"required_percent"`
+}
+
+// Collection handlers
+func (h *QuizHandler) handleListCollections(c *gin.Context) {
+	collections, err := h.querier.ListCollections(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collections)
+}
+
+func (h *QuizHandler) handleCreateCollection(c *gin.Context) {
+	var req struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	}
+
+	err := c.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collectionSlug := slug.Make(req.Title)
+	if collectionSlug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+
+	created, err := h.querier.CreateCollection(c, repo.CreateCollectionParams{
+		Title:       req.Title,
+		Slug:        collectionSlug,
+		Description: req.Description,
+	})
+	if repo.IsUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "a collection with this title already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, created)
+}
+
+func (h *QuizHandler) handleGetCollection(c *gin.Context) {
+	col, ok := h.lookupCollection(c)
+	if !ok {
+		return
+	}
+
+	quizzes, err := h.collectionQuizzes(c, col.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collection": col, "quizzes": quizzes})
+}
+
+func (h *QuizHandler) handleDeleteCollection(c *gin.Context) {
+	col, ok := h.lookupCollection(c)
+	if !ok {
+		return
+	}
+
+	err := h.querier.DeleteCollection(c, col.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, err)
+}
+
+// handleAddCollectionQuiz adds a quiz to a collection, or moves it and
+// replaces its prerequisites if it is already there. Without a position the
+// quiz goes to the end; without prerequisites it requires the quiz before it
+// to be passed at that quiz's own pass mark. A move that would leave another
+// quiz requiring one that comes after it is refused with 409.
+func (h *QuizHandler) handleAddCollectionQuiz(c *gin.Context) {
+	col, ok := h.lookupCollection(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Position      *int32 `json:"position"`
+		Prerequisites *[]struct {
+			QuizID     string   `json:"quiz_id"`
+			MinPercent *float64 `json:"min_percent"`
+		} `json:"prerequisites"`
+	}
+
+	// The body is optional
+	err := c.ShouldBindBodyWithJSON(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quiz, err := h.querier.GetQuizByID(c, c.Param("quiz_id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quiz not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	members, err := h.querier.ListCollectionQuizzes(c, col.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	positions := make(map[string]int32, len(members))
+	var last int32
+	for _, m := range members {
+		positions[m.QuizID] = m.Position
+		last = max(last, m.Position)
+	}
+
+	position, member := positions[quiz.ID]
+	switch {
+	case req.Position != nil:
+		position = *req.Position
+	case !member:
+		position = last + 1
+	}
+	if position < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "position must be at least 1"})
+		return
+	}
+
+	// Prerequisites only point back along the path, which keeps it free of
+	// cycles. The quiz's own are replaced below, but quizzes that require it
+	// must still come after it.
+	rules, err := h.querier.ListCollectionPrerequisites(c, col.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, r := range rules {
+		if r.RequiresQuizID == quiz.ID && positions[r.QuizID] <= position {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "quizzes that require this quiz must come after it, move them or change their prerequisites first",
+				"quiz_id": r.QuizID,
+			})
+			return
+		}
+	}
+
+	var prereqs []repo.AddCollectionPrerequisiteParams
+	if req.Prerequisites == nil {
+		// Default to the closest quiz before this one
+		var previous *repo.ListCollectionQuizzesRow
+		for i, m := range members {
+			if m.QuizID != quiz.ID && m.Position < position && (previous == nil || m.Position >= previous.Position) {
+				previous = &members[i]
+			}
+		}
+		if previous != nil {
+			prereqs = append(prereqs, repo.AddCollectionPrerequisiteParams{RequiresQuizID: previous.QuizID})
+		}
+	} else {
+		for _, p := range *req.Prerequisites {
+			requiredPosition, ok := positions[p.QuizID]
+			if !ok || p.QuizID == quiz.ID || requiredPosition >= position {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "prerequisites must be quizzes earlier in the collection"})
+				return
+			}
+			if p.MinPercent != nil && (*p.MinPercent < 0 || *p.MinPercent > 100) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "min_percent must be between 0 and 100"})
+				return
+			}
+			prereqs = append(prereqs, repo.AddCollectionPrerequisiteParams{
+				RequiresQuizID: p.QuizID,
+				MinPercent:     p.MinPercent,
+			})
+		}
+	}
+
+	err = repo.ExecTx(c, h.db, func(q repo.Querier) error {
+		err := q.AddCollectionQuiz(c, repo.AddCollectionQuizParams{
+			CollectionID: col.ID,
+			QuizID:       quiz.ID,
+			Position:     position,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.ClearCollectionPrerequisites(c, repo.ClearCollectionPrerequisitesParams{
+			CollectionID: col.ID,
+			QuizID:       quiz.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, p := range prereqs {
+			p.CollectionID = col.ID
+			p.QuizID = quiz.ID
+			err = q.AddCollectionPrerequisite(c, p)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	quizzes, err := h.collectionQuizzes(c, col.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collection": col, "quizzes": quizzes})
+}
+
+// handleRemoveCollectionQuiz removes a quiz from a collection, along with
+// any prerequisites that referred to it.
+func (h *QuizHandler) handleRemoveCollectionQuiz(c *gin.Context) {
+	col, ok := h.lookupCollection(c)
+	if !ok {
+		return
+	}
+
+	err := h.querier.RemoveCollectionQuiz(c, repo.RemoveCollectionQuizParams{
+		CollectionID: col.ID,
+		QuizID:       c.Param("quiz_id"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, err)
+}
+
+// handlePlayerCollection shows a player's way through a collection: which
+// quizzes they have completed, which are unlocked and what each locked quiz
+// is still waiting for.
+func (h *QuizHandler) handlePlayerCollection(c *gin.Context) {
+	col, ok := h.lookupCollection(c)
+	if !ok {
+		return
+	}
+
+	userName := c.Param("name")
+
+	rows, err := h.querier.ListCollectionProgress(c, repo.ListCollectionProgressParams{
+		CollectionID: col.ID,
+		UserName:     userName,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rules, err := h.querier.ListCollectionPrerequisites(c, col.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	type playerPrerequisite struct {
+		prerequisiteStatus
+		BestScorePercent float64 `json:"best_score_percent"`
+		Met              bool    `json:"met"`
+	}
+
+	type playerQuiz struct {
+		repo.ListCollectionProgressRow
+		Completed     bool                 `json:"completed"`
+		Unlocked      bool                 `json:"unlocked"`
+		Prerequisites []playerPrerequisite `json:"prerequisites"`
+	}
+
+	byID := make(map[string]repo.ListCollectionProgressRow, len(rows))
+	for _, r := range rows {
+		byID[r.QuizID] = r
+	}
+
+	quizzes := make([]playerQuiz, len(rows))
+	completed := 0
+	for i, r := range rows {
+		pq := playerQuiz{
+			ListCollectionProgressRow: r,
+			Completed:                 collection.Completed(r.PassPercent, collection.Result{Attempts: r.Attempts, BestPercent: r.BestScorePercent}),
+			Unlocked:                  true,
+			Prerequisites:             []playerPrerequisite{},
+		}
+
+		for _, rule := range rules {
+			if rule.QuizID != r.QuizID {
+				continue
+			}
+			required := byID[rule.RequiresQuizID]
+			p := collection.Prerequisite{
+				QuizID:      rule.RequiresQuizID,
+				MinPercent:  rule.MinPercent,
+				PassPercent: required.PassPercent,
+				Result:      collection.Result{Attempts: required.Attempts, BestPercent: required.BestScorePercent},
+			}
+			pq.Unlocked = pq.Unlocked && p.Met()
+			pq.Prerequisites = append(pq.Prerequisites, playerPrerequisite{
+				prerequisiteStatus: prerequisiteStatus{
+					QuizID:          required.QuizID,
+					Title:           required.Title,
+					RequiredPercent: p.RequiredPercent(),
+				},
+				BestScorePercent: required.BestScorePercent,
+				Met:              p.Met(),
+			})
+		}
+
+		if pq.Completed {
+			completed++
+		}
+		quizzes[i] = pq
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collection": col,
+		"user_name":  userName,
+		"completed":  completed,
+		"total":      len(quizzes),
+		"quizzes":    quizzes,
+	})
+}
+
+// collectionQuizzes lists the quizzes of a collection in order with their
+// prerequisites.
+func (h *QuizHandler) collectionQuizzes(c *gin.Context, collectionID string) ([]collectionQuiz, error) {
+	members, err := h.querier.ListCollectionQuizzes(c, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := h.querier.ListCollectionPrerequisites(c, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]repo.ListCollectionQuizzesRow, len(members))
+	for _, m := range members {
+		byID[m.QuizID] = m
+	}
+
+	quizzes := make([]collectionQuiz, len(members))
+	for i, m := range members {
+		quizzes[i] = collectionQuiz{
+			Position:      m.Position,
+			QuizID:        m.QuizID,
+			Title:         m.Title,
+			PassPercent:   m.PassPercent,
+			Prerequisites: []prerequisiteStatus{},
+		}
+		for _, rule := range rules {
+			if rule.QuizID != m.QuizID {
+				continue
+			}
+			required := byID[rule.RequiresQuizID]
+			p := collection.Prerequisite{MinPercent: rule.MinPercent, PassPercent: required.PassPercent}
+			quizzes[i].Prerequisites = append(quizzes[i].Prerequisites, prerequisiteStatus{
+				QuizID:          required.QuizID,
+				Title:           required.Title,
+				RequiredPercent: p.RequiredPercent(),
+			})
+		}
+	}
+
+	return quizzes, nil
+}
+
+// requireUnlocked checks that a player has passed the prerequisites of a
+// quiz in every collection it belongs to. If not, it responds with 403
+// Forbidden listing what is missing and returns false.
+func (h *QuizHandler) requireUnlocked(c *gin.Context, quizID, userName string) bool {
+	rows, err := h.querier.GetQuizPrerequisites(c, repo.GetQuizPrerequisitesParams{
+		QuizID:   quizID,
+		UserName: userName,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+
+	missing := []gin.H{}
+	for _, r := range rows {
+		p := collection.Prerequisite{
+			QuizID:      r.RequiresQuizID,
+			MinPercent:  r.MinPercent,
+			PassPercent: r.PassPercent,
+			Result:      collection.Result{Attempts: r.Attempts, BestPercent: r.BestScorePercent},
+		}
+		if p.Met() {
+			continue
+		}
+		missing = append(missing, gin.H{
+			"collection":         r.CollectionSlug,
+			"quiz_id":            r.RequiresQuizID,
+			"title":              r.RequiresQuizTitle,
+			"required_percent":   p.RequiredPercent(),
+			"best_score_percent": r.BestScorePercent,
+		})
+	}
+
+	if len(missing) > 0 {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":   "quiz is locked until its prerequisites are passed",
+			"reason":  "locked",
+			"missing": missing,
+		})
+		return false
+	}
+
+	return true
+}
+
+// lookupCollection loads the collection named by the :slug path parameter,
+// responding with 404 if it does not exist.
+func (h *QuizHandler) lookupCollection(c *gin.Context) (repo.Collection, bool) {
+	col, err := h.querier.GetCollectionBySlug(c, slug.Make(c.Param("slug")))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "collection not found"})
+		return col, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return col, false
+	}
+
+	return col, true
+}
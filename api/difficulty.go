@@ -0,0 +1,51 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Iknite-Space/sqlc-example-api/difficulty"
+	"github.com/gin-gonic/gin"
+)
+
+const invalidDifficulty = "difficulty must be between 1 and 5"
+
+// difficultyFilter reads the ?min_difficulty=, ?max_difficulty=,
+// ?min_correct= and ?max_correct= query parameters used to filter quiz
+// listings.
+func difficultyFilter(c *gin.Context) (difficulty.Range, error) {
+	var r difficulty.Range
+
+	for _, p := range []struct {
+		name string
+		dst  **int32
+	}{{"min_difficulty", &r.Min}, {"max_difficulty", &r.Max}} {
+		v := c.Query(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < difficulty.Min || n > difficulty.Max {
+			return r, fmt.Errorf("%s must be between %d and %d", p.name, difficulty.Min, difficulty.Max)
+		}
+		d := int32(n)
+		*p.dst = &d
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **float64
+	}{{"min_correct", &r.MinCorrect}, {"max_correct", &r.MaxCorrect}} {
+		v := c.Query(p.name)
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 1 {
+			return r, fmt.Errorf("%s must be between 0 and 1", p.name)
+		}
+		*p.dst = &f
+	}
+
+	return r, nil
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/policy"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Eligibility handlers
+func (h *QuizHandler) handleAttemptEligibility(c *gin.Context) {
+	id := c.Param("id")
+	userName := c.Query("user_name")
+	if userName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_name is required"})
+		return
+	}
+
+	eligibility, err := h.querier.GetAttemptEligibility(c, repo.GetAttemptEligibilityParams{
+		ID:       id,
+		UserName: userName,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quiz not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policyResponse(policy.Check(eligibility)))
+}
+
+// abortWithPolicyDecision responds to a refused attempt. Availability window
+// violations are 403 Forbidden, while attempt limits and cooldowns are 409
+// Conflict. Whenever the player can retry later, a Retry-After header is set.
+func abortWithPolicyDecision(c *gin.Context, d policy.Decision) {
+	status := http.StatusConflict
+	if d.Reason == policy.NotOpenYet || d.Reason == policy.Closed {
+		status = http.StatusForbidden
+	}
+
+	if wait := d.RetryAfter(); wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	}
+
+	c.AbortWithStatusJSON(status, policyResponse(d))
+}
+
+func policyResponse(d policy.Decision) gin.H {
+	response := gin.H{
+		"allowed":            d.Allowed,
+		"attempts_remaining": d.AttemptsRemaining,
+	}
+
+	if !d.Allowed {
+		response["error"] = d.Message
+		response["reason"] = d.Reason
+	}
+
+	if wait := d.RetryAfter(); wait > 0 {
+		response["retry_at"] = pgtype.Timestamp{Time: d.RetryAt, Valid: true}
+		response["retry_after_seconds"] = int(math.Ceil(wait.Seconds()))
+	}
+
+	return response
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/Iknite-Space/sqlc-example-api/bundle"
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/lint"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// handleLintQuiz runs the content linter over a quiz and its questions.
+func (h *QuizHandler) handleLintQuiz(c *gin.Context) {
+	b, err := bundle.Export(c, h.querier, c.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quiz not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	findings := lint.Quiz(b.Quiz, h.lint)
+	if findings == nil {
+		findings = []lint.Finding{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"findings": findings,
+		"errors":   lint.Count(findings, lint.Error),
+		"warnings": lint.Count(findings, lint.Warning),
+		"infos":    lint.Count(findings, lint.Info),
+	})
+}
+
+// errLintErrors rolls back a write that leaves content the linter finds
+// errors in, so error findings never reach players.
+var errLintErrors = errors.New("the content linter found errors, fix them before publishing")
+
+// lintErrors runs the content linter over a quiz as the transaction sees
+// it, and returns the error findings about the quiz as a whole and about
+// the written questions, named as bundle.Export names them (see
+// exportedID). Findings about other questions are left to the quiz's own
+// lint report, so they do not block unrelated edits.
+func (h *QuizHandler) lintErrors(ctx context.Context, q repo.Querier, quizID string, written ...string) ([]lint.Finding, error) {
+	b, err := bundle.Export(ctx, q, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []lint.Finding
+	for _, f := range lint.Quiz(b.Quiz, h.lint) {
+		if f.Severity == lint.Error && (f.Number == 0 || slices.Contains(written, f.Question)) {
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+// exportedID is the ID bundle.Export gives a question: its external ID
+// when it has one, or else its database ID.
+func exportedID(question repo.Question) string {
+	if question.ExternalID != nil {
+		return *question.ExternalID
+	}
+	return question.ID
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// handleListQuestionMedia lists the media files shown with a question,
+// without their data.
+func (h *QuizHandler) handleListQuestionMedia(c *gin.Context) {
+	files, err := h.querier.ListQuestionMediaFiles(c, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, files)
+}
+
+// handleGetQuestionMedia serves one of a question's media files. Files come
+// from imported packages, so they are sandboxed in case one is a document
+// that runs scripts, such as an SVG image.
+func (h *QuizHandler) handleGetQuestionMedia(c *gin.Context) {
+	file, err := h.querier.GetQuestionMediaFile(c, repo.GetQuestionMediaFileParams{
+		QuestionID: c.Param("id"),
+		Name:       c.Param("name"),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "media not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Security-Policy", "sandbox")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
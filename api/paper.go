@@ -0,0 +1,243 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/grading"
+	"github.com/Iknite-Space/sqlc-example-api/paper"
+	"github.com/Iknite-Space/sqlc-example-api/policy"
+	"github.com/Iknite-Space/sqlc-example-api/scoring"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// paperAttempt is an answer sheet recorded as an attempt.
+type paperAttempt struct {
+	Line        int              `json:"line"`
+	Variant     string           `json:"variant"`
+	Blank       int              `json:"blank"`
+	Attempt     repo.QuizAttempt `json:"attempt"`
+	Certificate gin.H            `json:"certificate,omitempty"`
+}
+
+// paperSkip is an answer sheet that was already recorded by an earlier
+// upload of the same paper.
+type paperSkip struct {
+	Line      int    `json:"line"`
+	Student   string `json:"student"`
+	Variant   string `json:"variant"`
+	AttemptID string `json:"attempt_id"`
+}
+
+// quizForPrinting loads a quiz and its questions with their answers. It
+// responds with 404 and returns false when either is missing.
+func (h *QuizHandler) quizForPrinting(c *gin.Context) (repo.Quiz, []repo.Question, bool) {
+	quiz, err := h.querier.GetQuizByID(c, c.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quiz not found"})
+		return quiz, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return quiz, nil, false
+	}
+
+	questions, err := h.querier.ListQuestionsWithAnswers(c, quiz.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return quiz, nil, false
+	}
+	if len(questions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No questions found for this quiz"})
+		return quiz, nil, false
+	}
+
+	return quiz, questions, true
+}
+
+// handlePrintQuiz renders a quiz for a paper exam as HTML, or as a PDF with
+// ?format=pdf. ?variants sets the number of shuffled variants, 3 by
+// default. The student papers are question papers and answer sheets;
+// ?part=keys gives the answer keys instead.
+func (h *QuizHandler) handlePrintQuiz(c *gin.Context) {
+	variants, err := strconv.Atoi(c.DefaultQuery("variants", "3"))
+	if err != nil || variants < 1 || variants > paper.MaxVariants {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("variants must be a number from 1 to %d", paper.MaxVariants)})
+		return
+	}
+
+	part := c.DefaultQuery("part", "papers")
+	if part != "papers" && part != "keys" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "part must be papers or keys"})
+		return
+	}
+
+	quiz, questions, ok := h.quizForPrinting(c)
+	if !ok {
+		return
+	}
+	p := paper.Build(quiz, questions, variants)
+
+	name := fmt.Sprintf("%s-%s", p.Code, part)
+	if c.Query("format") == "pdf" {
+		c.Header("Content-Type", "application/pdf")
+		c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%s.pdf", name))
+		if part == "keys" {
+			err = paper.RenderKeysPDF(c.Writer, p)
+		} else {
+			err = paper.RenderPDF(c.Writer, p)
+		}
+	} else {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		if part == "keys" {
+			err = paper.RenderKeysHTML(c.Writer, p)
+		} else {
+			err = paper.RenderHTML(c.Writer, p)
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+}
+
+// handleUploadPaperResults records scanned answer sheets, a CSV file in the
+// multipart form field "file", as official exam attempts. The form field
+// "paper" is the code printed on the sheets; it must match the quiz's
+// questions as they are now. The whole file is recorded in one transaction,
+// or nothing is when any row has a problem. A student's sheet for a
+// variant of the paper is recorded once: uploading the file again skips
+// the sheets already recorded, so a file sent twice, or sent again with
+// more sheets, records each sheet once.
+//
+// The exam has already been sat, so the quiz's availability window,
+// attempt limit and prerequisites are not checked.
+func (h *QuizHandler) handleUploadPaperResults(c *gin.Context) {
+	quiz, questions, ok := h.quizForPrinting(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, paper.MaxSize+1<<20)
+	code := c.PostForm("paper")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "paper is required, it is the code printed on the answer sheets"})
+		return
+	}
+	if code != paper.Code(questions) {
+		c.JSON(http.StatusConflict, gin.H{"error": "the quiz's questions have changed since paper " + code + " was printed"})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "upload a file in the form field \"file\""})
+		return
+	}
+	f, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	// Sheets can name any variant the paper could have been printed with
+	results, problems, err := paper.ReadResults(f, paper.Build(quiz, questions, paper.MaxVariants))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":    "the file has problems, fix them and upload it again",
+			"problems": problems,
+		})
+		return
+	}
+
+	graded := make([]scoring.Question, 0, len(questions))
+	for _, q := range questions {
+		graded = append(graded, scoring.Question{
+			ID:            q.ID,
+			CorrectAnswer: q.CorrectAnswer,
+			Points:        q.Points,
+		})
+	}
+
+	recorded := make([]paperAttempt, 0, len(results))
+	skipped := []paperSkip{}
+	err = repo.ExecTx(c, h.db, func(q repo.Querier) error {
+		for _, result := range results {
+			sheet := repo.GetPaperSheetParams{
+				QuizID:    quiz.ID,
+				PaperCode: code,
+				UserName:  result.Student,
+				Variant:   result.Variant,
+			}
+			attemptID, err := q.GetPaperSheet(c, sheet)
+			if err == nil {
+				skipped = append(skipped, paperSkip{Line: result.Line, Student: result.Student, Variant: result.Variant, AttemptID: attemptID})
+				continue
+			}
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+
+			// Sheets are graded and recorded like exam attempts submitted
+			// with POST /attempts
+			submitted, err := grading.Submit(c, q, grading.Submission{
+				Quiz:      quiz,
+				UserName:  result.Student,
+				Mode:      policy.ModeExam,
+				Questions: graded,
+				Answers:   result.Answers,
+			})
+			if err != nil {
+				return fmt.Errorf("line %d: %w", result.Line, err)
+			}
+
+			err = q.AddPaperSheet(c, repo.AddPaperSheetParams{
+				AttemptID: submitted.Attempt.ID,
+				QuizID:    sheet.QuizID,
+				PaperCode: sheet.PaperCode,
+				UserName:  sheet.UserName,
+				Variant:   sheet.Variant,
+			})
+			if err != nil {
+				return fmt.Errorf("line %d: %w", result.Line, err)
+			}
+
+			row := paperAttempt{Line: result.Line, Variant: result.Variant, Blank: result.Blank, Attempt: submitted.Attempt}
+			if submitted.Certificate != nil {
+				row.Certificate = certificateLinks(c, submitted.Certificate.VerificationCode)
+			}
+			recorded = append(recorded, row)
+		}
+		return nil
+	})
+	if repo.IsUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "these results are being uploaded at the same time, try again"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = grading.RecalculateDifficulty(c, h.querier, quiz.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"paper":    code,
+		"recorded": len(recorded),
+		"attempts": recorded,
+		"skipped":  skipped,
+	})
+}
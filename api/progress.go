@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/gin-gonic/gin"
+)
+
+// progressTagCount is how many tags are reported as a player's strongest and
+// weakest.
+const progressTagCount = 3
+
+// handlePlayerProgress summarises a player's attempts: per quiz progress,
+// activity streaks, performance by tag and the quizzes they have not tried.
+func (h *QuizHandler) handlePlayerProgress(c *gin.Context) {
+	userName := c.Param("name")
+
+	quizzes, err := h.querier.GetPlayerQuizProgress(c, userName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	streaks, err := h.querier.GetPlayerStreaks(c, userName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, err := h.querier.GetPlayerTagPerformance(c, userName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	notAttempted, err := h.querier.ListUnattemptedQuizzes(c, userName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var totalAttempts int64
+	for _, q := range quizzes {
+		totalAttempts += q.Attempts
+	}
+
+	// Tags come ordered best first, so the weakest are taken from the end
+	// without overlapping the strongest.
+	strongest := tags[:min(progressTagCount, len(tags))]
+	weakest := []repo.GetPlayerTagPerformanceRow{}
+	for i := len(tags) - 1; i >= len(strongest) && len(weakest) < progressTagCount; i-- {
+		weakest = append(weakest, tags[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_name":      userName,
+		"total_attempts": totalAttempts,
+		"streaks":        streaks,
+		"quizzes":        quizzes,
+		"tags":           tags,
+		"strongest_tags": strongest,
+		"weakest_tags":   weakest,
+		"not_attempted":  notAttempted,
+	})
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/importer"
+	"github.com/Iknite-Space/sqlc-example-api/lint"
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+	"github.com/Iknite-Space/sqlc-example-api/slug"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// handlePreviewQuestionImport validates an uploaded CSV, XLSX, Moodle XML,
+// GIFT or Open Trivia DB file, sent as the multipart form field "file", and
+// lists what importing it would change. Nothing is added to the quiz: a
+// valid upload is staged under an import ID that is committed with
+// handleCommitQuestionImport. The content linter's findings about the
+// uploaded questions are listed with the changes; numbers are their order
+// in the file. An upload with error findings is refused, as other problems
+// are.
+func (h *QuizHandler) handlePreviewQuestionImport(c *gin.Context) {
+	quiz, err := h.querier.GetQuizByID(c, c.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quiz not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, importer.MaxSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "upload a file in the form field \"file\""})
+		return
+	}
+	if !importer.Supported(header.Filename) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file must be .csv, .xlsx, .xml, .gift, .txt or .json"})
+		return
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	sheet, err := importer.Read(header.Filename, f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(sheet.Problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":    "the file has problems, fix them and upload it again",
+			"problems": sheet.Problems,
+			"warnings": sheet.Warnings,
+		})
+		return
+	}
+
+	changes, err := importer.Plan(c, h.querier, quiz.ID, sheet.Rows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	uploaded := quizfile.Quiz{Slug: slug.Make(quiz.Title)}
+	if quiz.Slug != nil {
+		uploaded.Slug = *quiz.Slug
+	}
+	for _, row := range sheet.Rows {
+		uploaded.Questions = append(uploaded.Questions, row.Question)
+	}
+	findings := lint.Quiz(uploaded, h.lint)
+	if lint.Count(findings, lint.Error) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":    errLintErrors.Error(),
+			"findings": findings,
+			"warnings": sheet.Warnings,
+		})
+		return
+	}
+
+	rows, err := json.Marshal(sheet.Rows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Previews can be committed for a day, and old rows are cleaned up after a week
+	err = h.querier.DeleteStaleQuestionImports(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	staged, err := h.querier.CreateQuestionImport(c, repo.CreateQuestionImportParams{
+		QuizID:   quiz.ID,
+		FileName: header.Filename,
+		Rows:     rows,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"import_id": staged.ID,
+		"file_name": staged.FileName,
+		"changes":   changes,
+		"warnings":  sheet.Warnings,
+		"findings":  findings,
+	})
+}
+
+// handleCommitQuestionImport adds the questions of a previewed import to its
+// quiz in a single transaction. An import can be committed once, within a
+// day of its preview. The quiz is linted again with the questions added,
+// since the preview only saw the uploaded ones, and the commit is refused
+// if that finds errors.
+func (h *QuizHandler) handleCommitQuestionImport(c *gin.Context) {
+	var summary importer.Summary
+	var findings []lint.Finding
+	err := repo.ExecTx(c, h.db, func(q repo.Querier) error {
+		staged, err := q.ClaimQuestionImport(c, repo.ClaimQuestionImportParams{
+			ID:     c.Param("import_id"),
+			QuizID: c.Param("id"),
+		})
+		if err != nil {
+			return err
+		}
+
+		var rows []importer.Row
+		err = json.Unmarshal(staged.Rows, &rows)
+		if err != nil {
+			return err
+		}
+
+		summary, err = importer.Apply(c, q, staged.QuizID, rows)
+		if err != nil {
+			return err
+		}
+
+		// Rows are stored under their ID, as bundle.Export names them
+		written := make([]string, len(rows))
+		for i, row := range rows {
+			written[i] = row.Question.ID
+		}
+		findings, err = h.lintErrors(c, q, staged.QuizID, written...)
+		if err == nil && len(findings) > 0 {
+			err = errLintErrors
+		}
+		return err
+	})
+	if errors.Is(err, errLintErrors) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "findings": findings})
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "import not found, expired or already committed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Iknite-Space/sqlc-example-api/report"
+	"github.com/Iknite-Space/sqlc-example-api/slug"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// handleExportAttempts downloads official attempts as CSV, NDJSON or XLSX,
+// optionally for one quiz (?quiz_id) or period (?from and ?to, inclusive
+// dates), with each attempt's answers when ?answers=true.
+func (h *QuizHandler) handleExportAttempts(c *gin.Context) {
+	format, filter, ok := reportRequest(c)
+	if !ok {
+		return
+	}
+	filter.QuizID = c.Query("quiz_id")
+
+	answers, err := strconv.ParseBool(c.DefaultQuery("answers", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "answers must be true or false"})
+		return
+	}
+
+	startReport(c, "attempts", format)
+	err = report.Attempts(c, h.stream, c.Writer, format, filter, answers)
+	finishReport(c, err)
+}
+
+// handleExportGradebook downloads a gradebook with a row per player and a
+// column per quiz, optionally for the quizzes of a collection
+// (?collection=slug) and a period (?from and ?to).
+func (h *QuizHandler) handleExportGradebook(c *gin.Context) {
+	format, filter, ok := reportRequest(c)
+	if !ok {
+		return
+	}
+
+	// Collections are named as in their own endpoints, see lookupCollection
+	if name := c.Query("collection"); name != "" {
+		collection, err := h.querier.GetCollectionBySlug(c, slug.Make(name))
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "collection not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		filter.CollectionID = collection.ID
+	}
+
+	startReport(c, "gradebook", format)
+	err := report.Gradebook(c, h.querier, h.stream, c.Writer, format, filter)
+	finishReport(c, err)
+}
+
+// reportRequest reads the format and period of a report. It responds with
+// 400 and returns false when they are invalid.
+func reportRequest(c *gin.Context) (report.Format, report.Filter, bool) {
+	var filter report.Filter
+
+	format := report.Format(c.DefaultQuery("format", string(report.CSV)))
+	if !report.ValidFormat(format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, ndjson or xlsx"})
+		return format, filter, false
+	}
+
+	for _, param := range []string{"from", "to"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		day, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be a date such as 2025-01-31"})
+			return format, filter, false
+		}
+		if param == "from" {
+			filter.From = day
+		} else {
+			// To is inclusive, so the period ends when the next day starts
+			filter.To = day.AddDate(0, 0, 1)
+		}
+	}
+
+	return format, filter, true
+}
+
+func startReport(c *gin.Context, name string, format report.Format) {
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	c.Status(http.StatusOK)
+}
+
+// finishReport reports an error that stopped a report. Reports are
+// buffered, so an error before the first few kilobytes were sent can still
+// be answered with 500. After that the client gets a truncated file.
+func finishReport(c *gin.Context, err error) {
+	if err == nil {
+		return
+	}
+	if c.Writer.Written() {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Writer.Header().Del("Content-Disposition")
+	c.Writer.Header().Del("Content-Type")
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
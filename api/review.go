@@ -0,0 +1,167 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/policy"
+	"github.com/Iknite-Space/sqlc-example-api/review"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const defaultReviewLimit = 20
+
+// reviewPlayer returns the player whose review queue is asked for: the
+// player in the path, or for /me routes the user_name query parameter, as
+// players are identified elsewhere.
+func reviewPlayer(c *gin.Context) (string, bool) {
+	userName := c.Param("name")
+	if userName == "" {
+		userName = c.Query("user_name")
+	}
+	if userName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_name is required"})
+		return "", false
+	}
+	return userName, true
+}
+
+// handleListReviews returns the questions in a player's review queue that
+// are due now, without their answers.
+func (h *QuizHandler) handleListReviews(c *gin.Context) {
+	userName, ok := reviewPlayer(c)
+	if !ok {
+		return
+	}
+
+	limit := defaultReviewLimit
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	counts, err := h.querier.CountReviews(c, userName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := h.querier.ListDueReviews(c, repo.ListDueReviewsParams{
+		UserName: userName,
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_name":   userName,
+		"due":         counts.Due,
+		"total":       counts.Total,
+		"next_due_at": counts.NextDueAt,
+		"items":       items,
+	})
+}
+
+// handleGradeReview records how well a player recalled a queued question
+// and schedules its next review. Recall is graded from an answer, or rated
+// by the player with a quality from 0 to 5.
+func (h *QuizHandler) handleGradeReview(c *gin.Context) {
+	userName, ok := reviewPlayer(c)
+	if !ok {
+		return
+	}
+	questionID := c.Param("question_id")
+
+	var req struct {
+		Answer  string `json:"answer"`
+		Quality *int   `json:"quality"`
+	}
+
+	err := c.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Answer == "" && req.Quality == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "answer or quality is required"})
+		return
+	}
+	if req.Quality != nil && !review.ValidQuality(*req.Quality) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quality must be between 0 and 5"})
+		return
+	}
+
+	item, err := h.querier.GetReviewItem(c, repo.GetReviewItemParams{
+		UserName:   userName,
+		QuestionID: questionID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "question is not in this player's review queue"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !validAnswer(req.Answer, item.OptionA, item.OptionB, item.OptionC, item.OptionD) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "answer must be one of the question's options, A to D"})
+		return
+	}
+
+	response := gin.H{}
+
+	var quality int
+	if req.Quality != nil {
+		quality = *req.Quality
+	}
+	if req.Answer != "" {
+		correct := req.Answer == item.CorrectAnswer
+		response["correct"] = correct
+		if req.Quality == nil {
+			quality = review.QualityFor(correct)
+		} else if !correct {
+			// A wrong answer is never remembered, whatever the rating.
+			quality = min(quality, review.PassingQuality-1)
+		}
+	}
+
+	card := review.Schedule(review.Card{
+		EaseFactor:   item.EaseFactor,
+		IntervalDays: item.IntervalDays,
+		Repetitions:  item.Repetitions,
+		Lapses:       item.Lapses,
+	}, quality)
+
+	updated, err := h.querier.UpdateReviewItem(c, repo.UpdateReviewItemParams{
+		UserName:     userName,
+		QuestionID:   questionID,
+		EaseFactor:   card.EaseFactor,
+		IntervalDays: card.IntervalDays,
+		Repetitions:  card.Repetitions,
+		Lapses:       card.Lapses,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response["quality"] = quality
+	response["review"] = updated
+
+	if policy.RevealAnswers(item.RevealPolicy, item.ClosesAt, item.CheckedAt.Time) {
+		response["correct_answer"] = item.CorrectAnswer
+		response["explanation"] = item.Explanation
+	}
+
+	c.JSON(http.StatusOK, response)
+}
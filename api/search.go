@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// handleSearch runs a full-text search over quiz titles and descriptions and
+// question text. Question hits carry only a highlighted snippet of the
+// question itself, never its options or answer.
+func (h *QuizHandler) handleSearch(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	limit := defaultSearchLimit
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	quizzes, err := h.querier.SearchQuizzes(c, repo.SearchQuizzesParams{
+		Search:     query,
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	questions, err := h.querier.SearchQuestions(c, repo.SearchQuestionsParams{
+		Search:     query,
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":     query,
+		"quizzes":   quizzes,
+		"questions": questions,
+	})
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/slug"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type tagRequest struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// Tag handlers
+func (h *QuizHandler) handleListTags(c *gin.Context) {
+	tags, err := h.querier.ListTagsWithCounts(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if category := c.Query("category"); category != "" {
+		filtered := make([]repo.ListTagsWithCountsRow, 0, len(tags))
+		for _, t := range tags {
+			if t.Category == category {
+				filtered = append(filtered, t)
+			}
+		}
+		tags = filtered
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *QuizHandler) handleCreateTag(c *gin.Context) {
+	var req tagRequest
+
+	err := c.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tagSlug := slug.Make(req.Name)
+	if tagSlug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	tag, err := h.querier.CreateTag(c, repo.CreateTagParams{
+		Name:     req.Name,
+		Slug:     tagSlug,
+		Category: req.Category,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *QuizHandler) handleUpdateTag(c *gin.Context) {
+	tag, ok := h.lookupTag(c)
+	if !ok {
+		return
+	}
+
+	var req tagRequest
+
+	err := c.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tagSlug := slug.Make(req.Name)
+	if tagSlug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	tag, err = h.querier.UpdateTag(c, repo.UpdateTagParams{
+		ID:       tag.ID,
+		Name:     req.Name,
+		Slug:     tagSlug,
+		Category: req.Category,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *QuizHandler) handleDeleteTag(c *gin.Context) {
+	tag, ok := h.lookupTag(c)
+	if !ok {
+		return
+	}
+
+	err := h.querier.DeleteTag(c, tag.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, err)
+}
+
+func (h *QuizHandler) handleListQuizTags(c *gin.Context) {
+	tags, err := h.querier.ListTagsForQuiz(c, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *QuizHandler) handleAddQuizTag(c *gin.Context) {
+	tag, ok := h.lookupTag(c)
+	if !ok {
+		return
+	}
+
+	err := h.querier.AddQuizTag(c, repo.AddQuizTagParams{QuizID: c.Param("id"), TagID: tag.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *QuizHandler) handleRemoveQuizTag(c *gin.Context) {
+	tag, ok := h.lookupTag(c)
+	if !ok {
+		return
+	}
+
+	err := h.querier.RemoveQuizTag(c, repo.RemoveQuizTagParams{QuizID: c.Param("id"), TagID: tag.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, err)
+}
+
+func (h *QuizHandler) handleListQuestionTags(c *gin.Context) {
+	tags, err := h.querier.ListTagsForQuestion(c, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *QuizHandler) handleAddQuestionTag(c *gin.Context) {
+	tag, ok := h.lookupTag(c)
+	if !ok {
+		return
+	}
+
+	err := h.querier.AddQuestionTag(c, repo.AddQuestionTagParams{QuestionID: c.Param("id"), TagID: tag.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *QuizHandler) handleRemoveQuestionTag(c *gin.Context) {
+	tag, ok := h.lookupTag(c)
+	if !ok {
+		return
+	}
+
+	err := h.querier.RemoveQuestionTag(c, repo.RemoveQuestionTagParams{QuestionID: c.Param("id"), TagID: tag.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, err)
+}
+
+// lookupTag loads the tag named by the :tag path parameter, responding with
+// 404 if it does not exist.
+func (h *QuizHandler) lookupTag(c *gin.Context) (repo.Tag, bool) {
+	tag, err := h.querier.GetTagBySlug(c, slug.Make(c.Param("tag")))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		return tag, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return tag, false
+	}
+
+	return tag, true
+}
+
+// tagFilter reads the ?tag= query parameters used to filter quiz listings.
+// Tags must all match unless ?match=any is given.
+func tagFilter(c *gin.Context) (repo.ListQuizzesByTagsParams, bool) {
+	seen := make(map[string]bool)
+	params := repo.ListQuizzesByTagsParams{
+		Slugs:    []string{},
+		MatchAll: c.Query("match") != "any",
+	}
+
+	for _, t := range c.QueryArray("tag") {
+		s := slug.Make(t)
+		if s != "" && !seen[s] {
+			seen[s] = true
+			params.Slugs = append(params.Slugs, s)
+		}
+	}
+
+	return params, len(params.Slugs) > 0
+}
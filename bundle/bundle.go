@@ -0,0 +1,429 @@
+// Package bundle moves quizzes between instances as versioned JSON
+// documents. A bundle holds one quiz in the quiz file format (see package
+// quizfile) with its questions, options, explanations, hints and tags.
+//
+// Question media, such as the images of imported QTI items, is carried in
+// the bundle itself, base64 encoded, so a bundle is complete on its own.
+//
+// An imported quiz gets a slug but is not marked as file-managed (see
+// package reconcile), so the seeder and quiz sync never delete it.
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+	"github.com/Iknite-Space/sqlc-example-api/reconcile"
+	"github.com/Iknite-Space/sqlc-example-api/slug"
+	"github.com/jackc/pgx/v5"
+)
+
+// Format identifies quiz bundles, and Version is the newest bundle version
+// this code reads and the one it writes.
+const (
+	Format  = "quiz-bundle"
+	Version = 1
+)
+
+// Bundle is an exported quiz.
+type Bundle struct {
+	Format     string        `json:"format"`
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Quiz       quizfile.Quiz `json:"quiz"`
+
+	// QuizID is the exported quiz's ID when it has no slug, so importing the
+	// bundle into the same instance finds the quiz again.
+	QuizID string `json:"quiz_id,omitempty"`
+}
+
+// Conflict decides what happens when an imported quiz's slug is already in
+// use.
+type Conflict string
+
+const (
+	// Skip leaves the existing quiz alone.
+	Skip Conflict = "skip"
+	// Overwrite replaces the existing quiz's settings, questions and tags
+	// with the bundle's. Imported questions missing from the bundle are
+	// deleted, questions added through the API are kept.
+	Overwrite Conflict = "overwrite"
+	// Copy imports the bundle as a new quiz under a free slug.
+	Copy Conflict = "copy"
+)
+
+// Import outcomes.
+const (
+	Created     = "created"
+	Overwritten = "overwritten"
+	Skipped     = "skipped"
+)
+
+// ValidConflict reports whether c is a known conflict policy.
+func ValidConflict(c Conflict) bool {
+	return c == Skip || c == Overwrite || c == Copy
+}
+
+// Result describes an import.
+type Result struct {
+	Status    string    `json:"status"`
+	Quiz      repo.Quiz `json:"quiz"`
+	Questions int       `json:"questions"`
+}
+
+// Decode reads and validates a bundle. Unknown fields are rejected, as are
+// bundles written by a newer version.
+func Decode(r io.Reader) (Bundle, error) {
+	var b Bundle
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&b)
+	if err != nil {
+		return b, err
+	}
+
+	if b.Format != Format {
+		return b, fmt.Errorf("format must be %q", Format)
+	}
+	if b.Version < 1 || b.Version > Version {
+		return b, fmt.Errorf("unsupported bundle version %d, the newest supported is %d", b.Version, Version)
+	}
+
+	b.Quiz.SetDefaults()
+	return b, b.Quiz.Validate()
+}
+
+// New bundles a quiz read from another format, such as a QTI package, and
+// validates it as Decode does.
+func New(quiz quizfile.Quiz) (Bundle, error) {
+	b := Bundle{Format: Format, Version: Version, ExportedAt: time.Now().UTC(), Quiz: quiz}
+	b.Quiz.SetDefaults()
+	return b, b.Quiz.Validate()
+}
+
+// Encode writes b as indented JSON.
+func (b Bundle) Encode(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// Export loads a quiz with its questions and tags. Questions keep the ID
+// they were imported or seeded with, or else their database ID, so that
+// importing the bundle again overwrites the same questions.
+func Export(ctx context.Context, querier repo.Querier, quizID string) (Bundle, error) {
+	b := Bundle{Format: Format, Version: Version, ExportedAt: time.Now().UTC()}
+
+	quiz, err := querier.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return b, err
+	}
+
+	questions, err := querier.ListQuestionsWithAnswers(ctx, quizID)
+	if err != nil {
+		return b, err
+	}
+
+	quizTags, err := querier.ListTagsForQuiz(ctx, quizID)
+	if err != nil {
+		return b, err
+	}
+
+	hintPenalty := quiz.HintPenalty
+	b.Quiz = quizfile.Quiz{
+		Slug:               slug.Make(quiz.Title),
+		Title:              quiz.Title,
+		Description:        quiz.Description,
+		Difficulty:         quiz.Difficulty,
+		PassPercent:        quiz.PassPercent,
+		MaxAttempts:        quiz.MaxAttempts,
+		CooldownSeconds:    quiz.CooldownSeconds,
+		WrongAnswerPenalty: quiz.WrongAnswerPenalty,
+		HintPenalty:        &hintPenalty,
+		RevealPolicy:       quiz.RevealPolicy,
+		Tags:               tagNames(quizTags),
+		Questions:          make([]quizfile.Question, len(questions)),
+	}
+	if quiz.Slug != nil {
+		b.Quiz.Slug = *quiz.Slug
+	} else {
+		b.QuizID = quiz.ID
+	}
+
+	for i, q := range questions {
+		questionTags, err := querier.ListTagsForQuestion(ctx, q.ID)
+		if err != nil {
+			return b, err
+		}
+
+		files, err := querier.GetQuestionMediaFiles(ctx, q.ID)
+		if err != nil {
+			return b, err
+		}
+		var media []quizfile.Media
+		for _, f := range files {
+			media = append(media, quizfile.Media{Name: f.Name, ContentType: f.ContentType, Data: f.Data})
+		}
+
+		id := q.ID
+		if q.ExternalID != nil {
+			id = *q.ExternalID
+		}
+
+		b.Quiz.Questions[i] = quizfile.Question{
+			ID:            id,
+			QuestionText:  q.QuestionText,
+			OptionA:       q.OptionA,
+			OptionB:       q.OptionB,
+			OptionC:       q.OptionC,
+			OptionD:       q.OptionD,
+			CorrectAnswer: q.CorrectAnswer,
+			Points:        q.Points,
+			Explanation:   q.Explanation,
+			Hints:         q.Hints,
+			Difficulty:    q.Difficulty,
+			Tags:          tagNames(questionTags),
+			Media:         media,
+		}
+	}
+
+	return b, nil
+}
+
+// Import creates the bundle's quiz, resolving a quiz that already exists
+// with the conflict policy. It makes several writes, so run it in a
+// transaction (see repo.ExecTx) to import all or nothing.
+func Import(ctx context.Context, querier repo.Querier, b Bundle, conflict Conflict) (Result, error) {
+	quiz := b.Quiz
+
+	existing, found, err := findQuiz(ctx, querier, b)
+	if err != nil {
+		return Result{}, err
+	}
+
+	status := Created
+	if found {
+		switch conflict {
+		case Skip:
+			return Result{Status: Skipped, Quiz: existing}, nil
+		case Overwrite:
+			status = Overwritten
+			if existing.Slug == nil {
+				// The quiz was created through the API, it takes the bundle's
+				// slug so the upsert below updates it
+				err = querier.SetQuizSlug(ctx, repo.SetQuizSlugParams{Slug: quiz.Slug, ID: existing.ID})
+				if err != nil {
+					return Result{}, err
+				}
+			}
+		case Copy:
+			if existing.Slug != nil {
+				quiz.Slug, err = freeSlug(ctx, querier, quiz.Slug)
+				if err != nil {
+					return Result{}, err
+				}
+			}
+		default:
+			return Result{}, fmt.Errorf("unknown conflict policy %q", conflict)
+		}
+	}
+
+	created, err := reconcile.UpsertQuiz(ctx, querier, quiz)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if status == Overwritten {
+		err = matchQuestions(ctx, querier, created.ID, quiz.Questions)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	tags := make(reconcile.Tags)
+	err = setTags(ctx, querier, tags, quiz.Tags,
+		func() ([]repo.Tag, error) { return querier.ListTagsForQuiz(ctx, created.ID) },
+		func(tagID string) error {
+			return querier.AddQuizTag(ctx, repo.AddQuizTagParams{QuizID: created.ID, TagID: tagID})
+		},
+		func(tagID string) error {
+			return querier.RemoveQuizTag(ctx, repo.RemoveQuizTagParams{QuizID: created.ID, TagID: tagID})
+		})
+	if err != nil {
+		return Result{}, err
+	}
+
+	for _, q := range quiz.Questions {
+		question, err := reconcile.UpsertQuestion(ctx, querier, created.ID, q)
+		if err != nil {
+			return Result{}, fmt.Errorf("question %s: %w", q.ID, err)
+		}
+
+		err = setQuestionTags(ctx, querier, tags, question.ID, q.Tags)
+		if err != nil {
+			return Result{}, fmt.Errorf("question %s: %w", q.ID, err)
+		}
+
+		err = SetQuestionMedia(ctx, querier, question.ID, q.Media)
+		if err != nil {
+			return Result{}, fmt.Errorf("question %s: %w", q.ID, err)
+		}
+	}
+
+	return Result{Status: status, Quiz: created, Questions: len(quiz.Questions)}, nil
+}
+
+// findQuiz finds the quiz a bundle was exported from: the quiz with its
+// slug, or else, for quizzes exported without a slug, the quiz with its ID.
+func findQuiz(ctx context.Context, querier repo.Querier, b Bundle) (repo.Quiz, bool, error) {
+	quiz, found, err := reconcile.FindQuiz(ctx, querier, b.Quiz.Slug)
+	if err != nil || found || b.QuizID == "" {
+		return quiz, found, err
+	}
+
+	quiz, err = querier.GetQuizByID(ctx, b.QuizID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return quiz, false, nil
+	}
+	if err != nil {
+		return quiz, false, err
+	}
+	// A quiz that has since been given a slug was not found by it, so the
+	// bundle is for another quiz
+	return quiz, quiz.Slug == nil, nil
+}
+
+// matchQuestions lines up a quiz's questions with the ones being imported.
+// Questions created through the API that the bundle exported under their
+// database ID take that ID as their external ID, so the import updates
+// them. Other imported questions missing from keep are deleted, along with
+// their recorded answers. Questions created through the API that are not in
+// keep are left alone.
+func matchQuestions(ctx context.Context, querier repo.Querier, quizID string, keep []quizfile.Question) error {
+	questions, err := querier.ListQuestionsWithAnswers(ctx, quizID)
+	if err != nil {
+		return err
+	}
+
+	ids := make(map[string]bool, len(keep))
+	for _, q := range keep {
+		ids[q.ID] = true
+	}
+
+	for _, q := range questions {
+		if q.ExternalID == nil && ids[q.ID] {
+			err = querier.SetQuestionExternalID(ctx, repo.SetQuestionExternalIDParams{ExternalID: q.ID, ID: q.ID})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, q := range reconcile.StaleQuestions(questions, ids) {
+		err = querier.DeleteQuestion(ctx, q.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetQuestionTags replaces a question's tags with names, creating tags that
+// do not exist yet.
+func SetQuestionTags(ctx context.Context, querier repo.Querier, questionID string, names []string) error {
+	return setQuestionTags(ctx, querier, make(reconcile.Tags), questionID, names)
+}
+
+func setQuestionTags(ctx context.Context, querier repo.Querier, tags reconcile.Tags, questionID string, names []string) error {
+	return setTags(ctx, querier, tags, names,
+		func() ([]repo.Tag, error) { return querier.ListTagsForQuestion(ctx, questionID) },
+		func(tagID string) error {
+			return querier.AddQuestionTag(ctx, repo.AddQuestionTagParams{QuestionID: questionID, TagID: tagID})
+		},
+		func(tagID string) error {
+			return querier.RemoveQuestionTag(ctx, repo.RemoveQuestionTagParams{QuestionID: questionID, TagID: tagID})
+		})
+}
+
+// SetQuestionMedia replaces a question's media files with media.
+func SetQuestionMedia(ctx context.Context, querier repo.Querier, questionID string, media []quizfile.Media) error {
+	err := querier.ClearQuestionMediaFiles(ctx, questionID)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range media {
+		err = querier.AddQuestionMediaFile(ctx, repo.AddQuestionMediaFileParams{
+			QuestionID:  questionID,
+			Name:        m.Name,
+			ContentType: m.ContentType,
+			Data:        m.Data,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setTags makes the tags returned by current match names, creating tags
+// that do not exist yet.
+func setTags(ctx context.Context, querier repo.Querier, tags reconcile.Tags, names []string, current func() ([]repo.Tag, error), add, remove func(tagID string) error) error {
+	existing, err := current()
+	if err != nil {
+		return err
+	}
+
+	added, removed := reconcile.TagChanges(existing, names)
+	for _, t := range removed {
+		err = remove(t.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, t := range added {
+		tagID, err := tags.ID(ctx, querier, t.Slug, t.Name)
+		if err != nil {
+			return err
+		}
+
+		err = add(tagID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// freeSlug finds the first of base-2, base-3, ... that no quiz uses.
+func freeSlug(ctx context.Context, querier repo.Querier, base string) (string, error) {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", base, n)
+		_, err := querier.GetQuizBySlug(ctx, candidate)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func tagNames(tags []repo.Tag) []string {
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.Name
+	}
+	return names
+}
@@ -0,0 +1,138 @@
+// Package certificate issues and renders completion certificates for passed
+// quiz attempts.
+package certificate
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/pdf"
+	"github.com/Iknite-Space/sqlc-example-api/scoring"
+)
+
+// Data is everything shown on a certificate.
+type Data struct {
+	UserName         string
+	QuizTitle        string
+	Score            float64
+	MaxPoints        float64
+	IssuedAt         string
+	VerificationCode string
+	VerifyURL        string
+}
+
+// NewData builds certificate data from a stored certificate. verifyURL is
+// the public address third parties can use to check the certificate.
+func NewData(c repo.GetCertificateByCodeRow, verifyURL string) Data {
+	issued := ""
+	if c.IssuedAt.Valid {
+		issued = c.IssuedAt.Time.Format("January 2, 2006")
+	}
+
+	return Data{
+		UserName:         c.UserName,
+		QuizTitle:        c.QuizTitle,
+		Score:            c.Score,
+		MaxPoints:        c.MaxPoints,
+		IssuedAt:         issued,
+		VerificationCode: c.VerificationCode,
+		VerifyURL:        verifyURL,
+	}
+}
+
+// Percent returns the certificate score as a percentage.
+func (d Data) Percent() float64 {
+	return scoring.Percent(d.Score, d.MaxPoints)
+}
+
+// NewCode returns a random, human friendly verification code.
+func NewCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.EncodeToString(b), nil
+}
+
+// Issue creates a certificate for a passed attempt.
+func Issue(ctx context.Context, querier repo.Querier, attemptID string) (repo.Certificate, error) {
+	code, err := NewCode()
+	if err != nil {
+		return repo.Certificate{}, fmt.Errorf("failed to generate verification code: %w", err)
+	}
+
+	return querier.CreateCertificate(ctx, repo.CreateCertificateParams{
+		AttemptID:        attemptID,
+		VerificationCode: code,
+	})
+}
+
+var htmlTemplate = template.Must(template.New("certificate").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Certificate of Completion - {{.QuizTitle}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; text-align: center; padding: 60px; }
+.frame { border: 6px double #333; padding: 40px; }
+h1 { font-size: 36px; margin-bottom: 0; }
+.name { font-size: 28px; font-weight: bold; margin: 24px 0; }
+.code { font-family: monospace; font-size: 14px; color: #555; }
+</style>
+</head>
+<body>
+<div class="frame">
+<h1>Certificate of Completion</h1>
+<p>This certifies that</p>
+<p class="name">{{.UserName}}</p>
+<p>has passed</p>
+<h2>{{.QuizTitle}}</h2>
+<p>with a score of {{printf "%g" .Score}}/{{printf "%g" .MaxPoints}} ({{printf "%.1f" .Percent}}%)</p>
+<p>Issued {{.IssuedAt}}</p>
+<p class="code">Verification code: {{.VerificationCode}}<br>{{.VerifyURL}}</p>
+</div>
+</body>
+</html>
+`))
+
+// RenderHTML writes the certificate as an HTML page.
+func RenderHTML(w io.Writer, d Data) error {
+	return htmlTemplate.Execute(w, d)
+}
+
+// RenderPDF writes the certificate as a single page PDF.
+func RenderPDF(w io.Writer, d Data) error {
+	doc := pdf.New()
+	page := doc.AddPage()
+
+	page.Rect(30, 30, pdf.PageWidth-60, pdf.PageHeight-60, 3)
+	page.Rect(40, 40, pdf.PageWidth-80, pdf.PageHeight-80, 1)
+
+	page.TextCentered(200, 30, pdf.Bold, "Certificate of Completion")
+	page.TextCentered(260, 14, pdf.Regular, "This certifies that")
+	page.TextCentered(310, 26, pdf.Bold, d.UserName)
+	page.TextCentered(360, 14, pdf.Regular, "has passed")
+
+	y := 410.0
+	for _, line := range pdf.Wrap(d.QuizTitle, pdf.PageWidth-160, 20, pdf.Bold) {
+		page.TextCentered(y, 20, pdf.Bold, line)
+		y += 26
+	}
+
+	page.TextCentered(y+24, 14, pdf.Regular,
+		fmt.Sprintf("with a score of %g/%g (%.1f%%)", d.Score, d.MaxPoints, d.Percent()))
+	page.TextCentered(y+50, 12, pdf.Regular, "Issued "+d.IssuedAt)
+
+	page.Line(120, 700, pdf.PageWidth-120, 700, 0.5)
+	page.TextCentered(720, 10, pdf.Regular, "Verification code: "+d.VerificationCode)
+	page.TextCentered(736, 10, pdf.Regular, d.VerifyURL)
+
+	_, err := doc.WriteTo(w)
+	return err
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/Iknite-Space/sqlc-example-api/api"
 	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/lint"
 )
 
 // DBConfig holds the database configuration. This struct is populated from the .env in the current directory.
@@ -31,6 +32,7 @@ type DBConfig struct {
 type Config struct {
 	ListenPort     uint16 `conf:"env:LISTEN_PORT,required"`
 	MigrationsPath string `conf:"env:MIGRATIONS_PATH,required"`
+	LintConfig     string `conf:"env:LINT_CONFIG"`
 	DB             DBConfig
 }
 
@@ -77,8 +79,14 @@ func run() error {
 
 	querier := repo.New(db)
 
+	// The content linter uses its default rules unless LINT_CONFIG names a file.
+	rules, err := lint.LoadConfig(config.LintConfig)
+	if err != nil {
+		return fmt.Errorf("failed to load lint config: %w", err)
+	}
+
 	// We create a new http handler using the database querier.
-    handler := api.NewQuizHandler(querier).WireHttpHandler()
+    handler := api.NewQuizHandler(querier, db, querier, rules).WireHttpHandler()
 	// And finally we start the HTTP server on the configured port.
 	err = http.ListenAndServe(fmt.Sprintf(":%d", config.ListenPort), handler)
 	if err != nil {
@@ -0,0 +1,219 @@
+// Command bundle exports quizzes to, and imports them from, portable JSON
+// bundles (see package bundle), to move quizzes between instances:
+//
+//	go run ./cmd/bundle export <quiz-id> [file]
+//	go run ./cmd/bundle import <file> [--conflict=skip|overwrite|copy]
+//
+// Without a file, export writes to standard output. Exporting to a .xml, or
+// a .gift or .txt, file writes a Moodle XML or GIFT question bank instead of
+// a bundle, and exporting to a .zip file writes a QTI package, of the version
+// given by --qti-version. Importing a .zip file reads a QTI package.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Iknite-Space/sqlc-example-api/bundle"
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/moodle"
+	"github.com/Iknite-Space/sqlc-example-api/qti"
+	"github.com/ardanlabs/conf/v3"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+type DBConfig struct {
+	DBUser      string `conf:"env:DB_USER,required"`
+	DBPassword  string `conf:"env:DB_PASSWORD,required,mask"`
+	DBHost      string `conf:"env:DB_HOST,required"`
+	DBPort      uint16 `conf:"env:DB_PORT,required"`
+	DBName      string `conf:"env:DB_Name,required"`
+	TLSDisabled bool   `conf:"env:DB_TLS_DISABLED"`
+}
+
+type Config struct {
+	DB         DBConfig
+	Conflict   string `conf:"default:skip,help:skip|overwrite|copy when an imported quiz's slug is taken"`
+	QTIVersion string `conf:"default:2.1,help:QTI version of exported .zip packages: 2.1 or 3.0"`
+	Args       conf.Args
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ctx := context.Background()
+	config := Config{}
+
+	if _, err := os.Stat(".env"); err == nil {
+		err = godotenv.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load env file: %w", err)
+		}
+	}
+
+	help, err := conf.Parse("", &config)
+	if errors.Is(err, conf.ErrHelpWanted) {
+		fmt.Println(help)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	command := config.Args.Num(0)
+	if command != "export" && command != "import" || config.Args.Num(1) == "" {
+		return errors.New("usage: bundle export <quiz-id> [file] | bundle import <file> [--conflict=skip|overwrite|copy]")
+	}
+
+	conflict := bundle.Conflict(config.Conflict)
+	if !bundle.ValidConflict(conflict) {
+		return fmt.Errorf("unknown conflict policy %q, want skip, overwrite or copy", config.Conflict)
+	}
+	version := qti.Version(config.QTIVersion)
+	if !qti.ValidVersion(version) {
+		return fmt.Errorf("unknown QTI version %q, want 2.1 or 3.0", config.QTIVersion)
+	}
+
+	dbConnectionURL := getPostgresConnectionURL(config.DB)
+	db, err := pgxpool.New(ctx, dbConnectionURL)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if command == "export" {
+		return exportQuiz(ctx, repo.New(db), config.Args.Num(1), config.Args.Num(2), version)
+	}
+	return importQuiz(ctx, db, config.Args.Num(1), conflict)
+}
+
+func exportQuiz(ctx context.Context, querier repo.Querier, quizID, path string, version qti.Version) error {
+	b, err := bundle.Export(ctx, querier, quizID)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		return b.Encode(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		err = moodle.WriteXML(f, moodle.FromQuiz(b.Quiz))
+	case ".gift", ".txt":
+		var issues []moodle.Issue
+		issues, err = moodle.WriteGIFT(f, moodle.FromQuiz(b.Quiz))
+		for _, issue := range issues {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", issue.Error())
+		}
+	case ".zip":
+		var issues []qti.Issue
+		issues, err = qti.Write(f, b.Quiz, version)
+		for _, issue := range issues {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", issue.Error())
+		}
+	default:
+		err = b.Encode(f)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Exported %q (%d questions) to %s\n", b.Quiz.Title, len(b.Quiz.Questions), path)
+	return f.Close()
+}
+
+func importQuiz(ctx context.Context, db *pgxpool.Pool, path string, conflict bundle.Conflict) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var b bundle.Bundle
+	if strings.ToLower(filepath.Ext(path)) == ".zip" {
+		b, err = readQTI(f)
+	} else {
+		b, err = bundle.Decode(f)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	var result bundle.Result
+	err = repo.ExecTx(ctx, db, func(q repo.Querier) error {
+		result, err = bundle.Import(ctx, q, b, conflict)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Status == bundle.Skipped {
+		fmt.Printf("Skipped %q: a quiz with slug %s already exists (use --conflict=overwrite or copy)\n", b.Quiz.Title, b.Quiz.Slug)
+		return nil
+	}
+
+	fmt.Printf("✅ %s %q with %d questions (ID %s, slug %s)\n", result.Status, result.Quiz.Title, result.Questions, result.Quiz.ID, *result.Quiz.Slug)
+	return nil
+}
+
+// readQTI reads a QTI package into a bundle. A package without an assessment
+// test is titled after its file name.
+func readQTI(f *os.File) (bundle.Bundle, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return bundle.Bundle{}, err
+	}
+	if info.Size() > qti.MaxSize {
+		return bundle.Bundle{}, fmt.Errorf("packages can be at most %d MB", qti.MaxSize>>20)
+	}
+
+	title := strings.TrimSuffix(filepath.Base(f.Name()), filepath.Ext(f.Name()))
+	quiz, issues, err := qti.Read(f, info.Size(), title)
+	if err != nil {
+		return bundle.Bundle{}, err
+	}
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", issue.Error())
+	}
+
+	return bundle.New(quiz)
+}
+
+func getPostgresConnectionURL(config DBConfig) string {
+	queryValues := url.Values{}
+	if config.TLSDisabled {
+		queryValues.Add("sslmode", "disable")
+	} else {
+		queryValues.Add("sslmode", "require")
+	}
+
+	dbURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.DBUser, config.DBPassword),
+		Host:     fmt.Sprintf("%s:%d", config.DBHost, config.DBPort),
+		Path:     config.DBName,
+		RawQuery: queryValues.Encode(),
+	}
+
+	return dbURL.String()
+}
@@ -0,0 +1,158 @@
+// Command calibrate estimates item response theory parameters for every
+// question from stored attempt answers. Adaptive attempts use the results
+// to choose questions, so run it periodically as new answers come in:
+//
+//	go run ./cmd/calibrate --model=2pl --min-responses=30
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/irt"
+	"github.com/ardanlabs/conf/v3"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+type DBConfig struct {
+	DBUser      string `conf:"env:DB_USER,required"`
+	DBPassword  string `conf:"env:DB_PASSWORD,required,mask"`
+	DBHost      string `conf:"env:DB_HOST,required"`
+	DBPort      uint16 `conf:"env:DB_PORT,required"`
+	DBName      string `conf:"env:DB_Name,required"`
+	TLSDisabled bool   `conf:"env:DB_TLS_DISABLED"`
+}
+
+type Config struct {
+	DB           DBConfig
+	Model        string `conf:"env:IRT_MODEL,default:2pl,help:rasch or 2pl"`
+	Quiz         string `conf:"env:IRT_QUIZ,help:calibrate only this quiz ID"`
+	MinResponses int    `conf:"env:IRT_MIN_RESPONSES,default:30,help:skip questions with fewer answers"`
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ctx := context.Background()
+	config := Config{}
+
+	if _, err := os.Stat(".env"); err == nil {
+		err = godotenv.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load env file: %w", err)
+		}
+	}
+
+	help, err := conf.Parse("", &config)
+	if errors.Is(err, conf.ErrHelpWanted) {
+		fmt.Println(help)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	model := irt.Model(config.Model)
+	if model != irt.Rasch && model != irt.TwoPL {
+		return fmt.Errorf("unknown model %q, want rasch or 2pl", config.Model)
+	}
+
+	dbConnectionURL := getPostgresConnectionURL(config.DB)
+	db, err := pgxpool.New(ctx, dbConnectionURL)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	querier := repo.New(db)
+
+	quizIDs := []string{config.Quiz}
+	if config.Quiz == "" {
+		quizzes, err := querier.ListQuizzes(ctx)
+		if err != nil {
+			return err
+		}
+		quizIDs = quizIDs[:0]
+		for _, q := range quizzes {
+			quizIDs = append(quizIDs, q.ID)
+		}
+	}
+
+	for _, id := range quizIDs {
+		err := calibrateQuiz(ctx, querier, id, model, config.MinResponses)
+		if err != nil {
+			return fmt.Errorf("quiz %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+func calibrateQuiz(ctx context.Context, querier repo.Querier, quizID string, model irt.Model, minResponses int) error {
+	rows, err := querier.GetItemResponses(ctx, quizID)
+	if err != nil {
+		return err
+	}
+
+	observations := make([]irt.Observation, len(rows))
+	for i, r := range rows {
+		observations[i] = irt.Observation{
+			Person:  r.AttemptID,
+			ItemID:  r.QuestionID,
+			Correct: r.IsCorrect,
+		}
+	}
+
+	updated, skipped := 0, 0
+	for _, item := range irt.Calibrate(observations, model) {
+		if item.Responses < minResponses {
+			skipped++
+			continue
+		}
+
+		err := querier.UpdateQuestionCalibration(ctx, repo.UpdateQuestionCalibrationParams{
+			ID:                item.ID,
+			IrtDifficulty:     &item.Difficulty,
+			IrtDiscrimination: &item.Discrimination,
+		})
+		if err != nil {
+			return err
+		}
+		updated++
+	}
+
+	fmt.Printf("Quiz %s: calibrated %d questions from %d answers (%d skipped with fewer than %d answers)\n",
+		quizID, updated, len(rows), skipped, minResponses)
+
+	return nil
+}
+
+func getPostgresConnectionURL(config DBConfig) string {
+	queryValues := url.Values{}
+	if config.TLSDisabled {
+		queryValues.Add("sslmode", "disable")
+	} else {
+		queryValues.Add("sslmode", "require")
+	}
+
+	dbURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.DBUser, config.DBPassword),
+		Host:     fmt.Sprintf("%s:%d", config.DBHost, config.DBPort),
+		Path:     config.DBName,
+		RawQuery: queryValues.Encode(),
+	}
+
+	return dbURL.String()
+}
@@ -0,0 +1,172 @@
+// Command import adds questions from a CSV file, Excel workbook, Moodle XML
+// or GIFT question bank, Open Trivia DB dump or Kahoot spreadsheet to a quiz
+// (see package importer):
+//
+//	go run ./cmd/import <quiz-id> <file> [--yes]
+//
+// The file is checked and the changes listed before anything is written,
+// then the questions are saved in a single transaction once confirmed.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/importer"
+	"github.com/ardanlabs/conf/v3"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+type DBConfig struct {
+	DBUser      string `conf:"env:DB_USER,required"`
+	DBPassword  string `conf:"env:DB_PASSWORD,required,mask"`
+	DBHost      string `conf:"env:DB_HOST,required"`
+	DBPort      uint16 `conf:"env:DB_PORT,required"`
+	DBName      string `conf:"env:DB_Name,required"`
+	TLSDisabled bool   `conf:"env:DB_TLS_DISABLED"`
+}
+
+type Config struct {
+	DB   DBConfig
+	Yes  bool `conf:"help:import without asking for confirmation"`
+	Args conf.Args
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ctx := context.Background()
+	config := Config{}
+
+	if _, err := os.Stat(".env"); err == nil {
+		err = godotenv.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load env file: %w", err)
+		}
+	}
+
+	help, err := conf.Parse("", &config)
+	if errors.Is(err, conf.ErrHelpWanted) {
+		fmt.Println(help)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	quizID, path := config.Args.Num(0), config.Args.Num(1)
+	if quizID == "" || path == "" {
+		return errors.New("usage: import <quiz-id> <file> [--yes]")
+	}
+	if !importer.Supported(path) {
+		return fmt.Errorf("%s: file must be .csv, .xlsx, .xml, .gift, .txt or .json", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sheet, err := importer.Read(path, f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, w := range sheet.Warnings {
+		fmt.Fprintf(os.Stderr, "%s:%d: warning: %s\n", path, w.Line, w.Message)
+	}
+	if len(sheet.Problems) > 0 {
+		for _, p := range sheet.Problems {
+			fmt.Fprintf(os.Stderr, "%s:%d: %s\n", path, p.Line, p.Message)
+		}
+		return fmt.Errorf("%s has %d problems, nothing was imported", path, len(sheet.Problems))
+	}
+
+	dbConnectionURL := getPostgresConnectionURL(config.DB)
+	db, err := pgxpool.New(ctx, dbConnectionURL)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	querier := repo.New(db)
+	quiz, err := querier.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return fmt.Errorf("quiz %s: %w", quizID, err)
+	}
+
+	changes, err := importer.Plan(ctx, querier, quiz.ID, sheet.Rows)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	fmt.Printf("Importing %s into %q:\n", path, quiz.Title)
+	for _, change := range changes {
+		sign := "~"
+		switch change.Action {
+		case importer.Create:
+			sign = "+"
+		case importer.Skip:
+			sign = "="
+		}
+		counts[change.Action]++
+		fmt.Printf("  %s line %d: %s\n", sign, change.Line, change.Question.QuestionText)
+	}
+	if counts[importer.Skip] > 0 {
+		fmt.Printf("%d questions (=) are already in the quiz and will be skipped.\n", counts[importer.Skip])
+	}
+
+	if !config.Yes {
+		fmt.Printf("Add %d and update %d questions? (y/N): ", counts[importer.Create], counts[importer.Update])
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			fmt.Println("Nothing was imported.")
+			return nil
+		}
+	}
+
+	var summary importer.Summary
+	err = repo.ExecTx(ctx, db, func(q repo.Querier) error {
+		summary, err = importer.Apply(ctx, q, quiz.ID, sheet.Rows)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Added %d, updated %d and skipped %d questions\n", summary.Created, summary.Updated, summary.Skipped)
+	return nil
+}
+
+func getPostgresConnectionURL(config DBConfig) string {
+	queryValues := url.Values{}
+	if config.TLSDisabled {
+		queryValues.Add("sslmode", "disable")
+	} else {
+		queryValues.Add("sslmode", "require")
+	}
+
+	dbURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.DBUser, config.DBPassword),
+		Host:     fmt.Sprintf("%s:%d", config.DBHost, config.DBPort),
+		Path:     config.DBName,
+		RawQuery: queryValues.Encode(),
+	}
+
+	return dbURL.String()
+}
@@ -0,0 +1,218 @@
+// Command lint checks quiz content for authoring mistakes (see package
+// lint), in quiz files or in the database:
+//
+//	go run ./cmd/lint [path...]             # quiz files and directories, db/fixtures by default
+//	go run ./cmd/lint --db [--quiz=<id>]    # quizzes in the database
+//	go run ./cmd/lint --format=json --config=quizlint.yaml quizzes
+//
+// It exits with status 1 when any finding is an error, so it can run in CI.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/Iknite-Space/sqlc-example-api/bundle"
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/lint"
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+	"github.com/ardanlabs/conf/v3"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+// DBConfig is only needed with --db, so none of it is required.
+type DBConfig struct {
+	DBUser      string `conf:"env:DB_USER"`
+	DBPassword  string `conf:"env:DB_PASSWORD,mask"`
+	DBHost      string `conf:"env:DB_HOST"`
+	DBPort      uint16 `conf:"env:DB_PORT"`
+	DBName      string `conf:"env:DB_Name"`
+	TLSDisabled bool   `conf:"env:DB_TLS_DISABLED"`
+}
+
+type Config struct {
+	DB     DBConfig
+	Config string `conf:"env:LINT_CONFIG,help:YAML file of rule settings (default: every rule on)"`
+	Format string `conf:"default:text,help:text or json"`
+	Use    bool   `conf:"flag:db,help:lint the quizzes in the database instead of files"`
+	Quiz   string `conf:"help:with --db lint only the quiz with this ID"`
+	Args   conf.Args
+}
+
+// report is the JSON output.
+type report struct {
+	Findings []lint.Finding `json:"findings"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Infos    int            `json:"infos"`
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ctx := context.Background()
+	config := Config{}
+
+	if _, err := os.Stat(".env"); err == nil {
+		err = godotenv.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load env file: %w", err)
+		}
+	}
+
+	help, err := conf.Parse("", &config)
+	if errors.Is(err, conf.ErrHelpWanted) {
+		fmt.Println(help)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if config.Format != "text" && config.Format != "json" {
+		return errors.New("format must be text or json")
+	}
+
+	rules, err := lint.LoadConfig(config.Config)
+	if err != nil {
+		return err
+	}
+
+	var quizzes []quizfile.Quiz
+	if config.Use {
+		quizzes, err = readDatabase(ctx, config)
+	} else {
+		quizzes, err = readFiles(config.Args)
+	}
+	if err != nil {
+		return err
+	}
+
+	findings := []lint.Finding{}
+	for _, q := range quizzes {
+		findings = append(findings, lint.Quiz(q, rules)...)
+	}
+	r := report{
+		Findings: findings,
+		Errors:   lint.Count(findings, lint.Error),
+		Warnings: lint.Count(findings, lint.Warning),
+		Infos:    lint.Count(findings, lint.Info),
+	}
+
+	if config.Format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(r)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, f := range findings {
+			fmt.Println(f)
+		}
+		fmt.Printf("\n%d quizzes: %d errors, %d warnings, %d infos\n", len(quizzes), r.Errors, r.Warnings, r.Infos)
+	}
+
+	if r.Errors > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// readFiles reads the quiz files at paths, which can be files or
+// directories.
+func readFiles(paths []string) ([]quizfile.Quiz, error) {
+	if len(paths) == 0 {
+		paths = []string{"db/fixtures"}
+	}
+
+	var quizzes []quizfile.Quiz
+	var errs []error
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			dir, err := quizfile.ReadDir(path)
+			quizzes = append(quizzes, dir...)
+			errs = append(errs, err)
+			continue
+		}
+		q, err := quizfile.ReadFile(path)
+		if err == nil {
+			quizzes = append(quizzes, q)
+		}
+		errs = append(errs, err)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid quiz files:\n%w", err)
+	}
+	return quizzes, nil
+}
+
+// readDatabase loads quizzes from the database as they would be exported.
+func readDatabase(ctx context.Context, config Config) ([]quizfile.Quiz, error) {
+	if config.DB.DBHost == "" || config.DB.DBUser == "" || config.DB.DBName == "" {
+		return nil, errors.New("--db needs DB_USER, DB_PASSWORD, DB_HOST, DB_PORT and DB_Name")
+	}
+
+	db, err := pgxpool.New(ctx, getPostgresConnectionURL(config.DB))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+	querier := repo.New(db)
+
+	ids := []string{config.Quiz}
+	if config.Quiz == "" {
+		all, err := querier.ListQuizzes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ids = ids[:0]
+		for _, q := range all {
+			ids = append(ids, q.ID)
+		}
+	}
+
+	var quizzes []quizfile.Quiz
+	for _, id := range ids {
+		b, err := bundle.Export(ctx, querier, id)
+		if err != nil {
+			return nil, fmt.Errorf("quiz %s: %w", id, err)
+		}
+		quizzes = append(quizzes, b.Quiz)
+	}
+	return quizzes, nil
+}
+
+func getPostgresConnectionURL(config DBConfig) string {
+	queryValues := url.Values{}
+	if config.TLSDisabled {
+		queryValues.Add("sslmode", "disable")
+	} else {
+		queryValues.Add("sslmode", "require")
+	}
+
+	dbURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.DBUser, config.DBPassword),
+		Host:     fmt.Sprintf("%s:%d", config.DBHost, config.DBPort),
+		Path:     config.DBName,
+		RawQuery: queryValues.Encode(),
+	}
+
+	return dbURL.String()
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
@@ -10,7 +11,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Iknite-Space/sqlc-example-api/collection"
 	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/difficulty"
+	"github.com/Iknite-Space/sqlc-example-api/grading"
+	"github.com/Iknite-Space/sqlc-example-api/policy"
+	"github.com/Iknite-Space/sqlc-example-api/review"
+	"github.com/Iknite-Space/sqlc-example-api/scoring"
+	"github.com/Iknite-Space/sqlc-example-api/slug"
 	"github.com/ardanlabs/conf/v3"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
@@ -82,7 +90,7 @@ func run() error {
 				fmt.Println("Error:", err)
 			}
 		case "2":
-			err := takeQuiz(ctx, querier, scanner)
+			err := takeQuiz(ctx, querier, db, scanner)
 			if err != nil {
 				fmt.Println("Error:", err)
 			}
@@ -102,6 +110,26 @@ func run() error {
 				fmt.Println("Error:", err)
 			}
 		case "6":
+			err := searchQuizzes(ctx, querier, scanner)
+			if err != nil {
+				fmt.Println("Error:", err)
+			}
+		case "7":
+			err := practiceWeakQuestions(ctx, querier, scanner)
+			if err != nil {
+				fmt.Println("Error:", err)
+			}
+		case "8":
+			err := studyFlashcards(ctx, querier, db, scanner)
+			if err != nil {
+				fmt.Println("Error:", err)
+			}
+		case "9":
+			err := browseLearningPaths(ctx, querier, scanner)
+			if err != nil {
+				fmt.Println("Error:", err)
+			}
+		case "10":
 			fmt.Println("\n Thanks for playing! Goodbye!")
 			return nil
 		default:
@@ -119,12 +147,43 @@ func printMainMenu() {
 	fmt.Println("3.  View leaderboard")
 	fmt.Println("4.  View my history")
 	fmt.Println("5.  Global statistics")
-	fmt.Println("6.  Exit")
+	fmt.Println("6.  Search quizzes and questions")
+	fmt.Println("7.  Practice my weak questions")
+	fmt.Println("8.  Study with flashcards")
+	fmt.Println("9.  Learning paths")
+	fmt.Println("10. Exit")
 	fmt.Println(strings.Repeat("=", 50))
 }
 
 func listQuizzes(ctx context.Context, querier repo.Querier, scanner *bufio.Scanner) error {
-	quizzes, err := querier.ListQuizzes(ctx)
+	tags, err := querier.ListTagsWithCounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	filter := repo.ListQuizzesByTagsParams{Slugs: []string{}, MatchAll: true}
+	if len(tags) > 0 {
+		fmt.Println("\n  Tags:")
+		for _, t := range tags {
+			fmt.Printf("   %s (%d quizzes)\n", t.Slug, t.QuizCount)
+		}
+
+		for _, t := range strings.Split(getUserInput(scanner, "\nFilter by tags (comma separated, Enter for all): "), ",") {
+			if s := slug.Make(t); s != "" {
+				filter.Slugs = append(filter.Slugs, s)
+			}
+		}
+		if len(filter.Slugs) > 1 {
+			filter.MatchAll = !strings.EqualFold(getUserInput(scanner, "Match (A)ll or an(Y) tag? "), "y")
+		}
+	}
+
+	var quizzes []repo.Quiz
+	if len(filter.Slugs) > 0 {
+		quizzes, err = querier.ListQuizzesByTags(ctx, filter)
+	} else {
+		quizzes, err = querier.ListQuizzes(ctx)
+	}
 	if err != nil {
 		return err
 	}
@@ -150,7 +209,26 @@ func listQuizzes(ctx context.Context, querier repo.Querier, scanner *bufio.Scann
 			fmt.Printf("    %s\n", quiz.Description)
 		}
 		fmt.Printf("   ❓ Questions: %d\n", questionCount)
+		if quizTags, _ := querier.ListTagsForQuiz(ctx, quiz.ID); len(quizTags) > 0 {
+			names := make([]string, len(quizTags))
+			for j, t := range quizTags {
+				names[j] = t.Name
+			}
+			fmt.Printf("    Tags: %s\n", strings.Join(names, ", "))
+		}
 		fmt.Printf("    Attempts: %d\n", attemptCount)
+		if quiz.Difficulty != nil {
+			fmt.Printf("    Difficulty: %s\n", difficulty.Label(*quiz.Difficulty))
+		}
+		if quiz.EmpiricalDifficulty != nil {
+			fmt.Printf("    Answered correctly: %.0f%% (%s)\n", *quiz.EmpiricalDifficulty*100, difficulty.Empirical(*quiz.EmpiricalDifficulty))
+		}
+		if quiz.MaxAttempts != nil {
+			fmt.Printf("    Attempts allowed per player: %d\n", *quiz.MaxAttempts)
+		}
+		if label := availabilityLabel(ctx, querier, quiz.ID); label != "" {
+			fmt.Printf("   %s\n", label)
+		}
 		if quiz.CreatedAt.Valid {
 			fmt.Printf("    Created: %s\n", quiz.CreatedAt.Time.Format("Jan 02, 2006"))
 		}
@@ -160,7 +238,454 @@ func listQuizzes(ctx context.Context, querier repo.Querier, scanner *bufio.Scann
 	return nil
 }
 
-func takeQuiz(ctx context.Context, querier repo.Querier, scanner *bufio.Scanner) error {
+func searchQuizzes(ctx context.Context, querier repo.Querier, scanner *bufio.Scanner) error {
+	query := getUserInput(scanner, "\nSearch for: ")
+	if query == "" {
+		return nil
+	}
+
+	quizzes, err := querier.SearchQuizzes(ctx, repo.SearchQuizzesParams{Search: query, MaxResults: 10})
+	if err != nil {
+		return err
+	}
+
+	questions, err := querier.SearchQuestions(ctx, repo.SearchQuestionsParams{Search: query, MaxResults: 10})
+	if err != nil {
+		return err
+	}
+
+	if len(quizzes) == 0 && len(questions) == 0 {
+		fmt.Printf("\n🔍 No results for %q.\n", query)
+		return nil
+	}
+
+	highlight := strings.NewReplacer("<mark>", "[", "</mark>", "]")
+
+	fmt.Println("\n🔍 Search Results")
+	fmt.Println(strings.Repeat("=", 50))
+	if len(quizzes) > 0 {
+		fmt.Println("\n Quizzes:")
+		for i, q := range quizzes {
+			fmt.Printf("%d. %s\n", i+1, q.Title)
+			fmt.Printf("    %s\n", highlight.Replace(q.Snippet))
+		}
+	}
+	if len(questions) > 0 {
+		fmt.Println("\n Questions:")
+		for i, q := range questions {
+			fmt.Printf("%d. %s\n", i+1, highlight.Replace(q.Snippet))
+			fmt.Printf("    in %s\n", q.QuizTitle)
+		}
+	}
+	fmt.Println(strings.Repeat("=", 50))
+
+	return nil
+}
+
+func practiceWeakQuestions(ctx context.Context, querier repo.Querier, scanner *bufio.Scanner) error {
+	userName := getUserInput(scanner, "Enter your name: ")
+	if userName == "" {
+		fmt.Println("❌ Name cannot be empty")
+		return nil
+	}
+
+	items, err := querier.ListDueReviews(ctx, repo.ListDueReviewsParams{UserName: userName, Limit: 20})
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		counts, err := querier.CountReviews(ctx, userName)
+		if err != nil {
+			return err
+		}
+		if counts.NextDueAt.Valid {
+			fmt.Printf("\n✅ Nothing to review right now. Next review: %s\n", counts.NextDueAt.Time.Format("Jan 02, 2006 15:04"))
+		} else {
+			fmt.Println("\n✅ No missed questions to practice. Take a quiz first!")
+		}
+		return nil
+	}
+
+	fmt.Printf("\n PRACTICE - %d questions due\n", len(items))
+	fmt.Println(strings.Repeat("=", 50))
+
+	for i, item := range items {
+		fmt.Printf("\n❓ %d of %d (from %s)\n", i+1, len(items), item.QuizTitle)
+		fmt.Println(strings.Repeat("-", 50))
+		fmt.Println(item.QuestionText)
+		printOptions(item.OptionA, item.OptionB, item.OptionC, item.OptionD)
+
+		var answer string
+		for {
+			answer = strings.ToUpper(getUserInput(scanner, "Your answer (A-D, or Q to stop): "))
+			if answer == "Q" {
+				return nil
+			}
+			if scoring.ValidAnswer(answer, item.OptionA, item.OptionB, item.OptionC, item.OptionD) {
+				break
+			}
+			fmt.Println("❌ Please enter the letter of one of the options")
+		}
+
+		state, err := querier.GetReviewItem(ctx, repo.GetReviewItemParams{UserName: userName, QuestionID: item.QuestionID})
+		if err != nil {
+			return err
+		}
+
+		correct := answer == state.CorrectAnswer
+		card := review.Schedule(review.Card{
+			EaseFactor:   state.EaseFactor,
+			IntervalDays: state.IntervalDays,
+			Repetitions:  state.Repetitions,
+			Lapses:       state.Lapses,
+		}, review.QualityFor(correct))
+
+		_, err = querier.UpdateReviewItem(ctx, repo.UpdateReviewItemParams{
+			UserName:     userName,
+			QuestionID:   item.QuestionID,
+			EaseFactor:   card.EaseFactor,
+			IntervalDays: card.IntervalDays,
+			Repetitions:  card.Repetitions,
+			Lapses:       card.Lapses,
+		})
+		if err != nil {
+			return err
+		}
+
+		reveal := policy.RevealAnswers(state.RevealPolicy, state.ClosesAt, state.CheckedAt.Time)
+		if correct {
+			fmt.Println("✅ Correct!")
+		} else if reveal {
+			fmt.Printf("❌ Wrong! The correct answer was %s\n", state.CorrectAnswer)
+		} else {
+			fmt.Println("❌ Wrong!")
+		}
+		if reveal && state.Explanation != "" {
+			fmt.Printf(" %s\n", state.Explanation)
+		}
+		fmt.Printf(" Next review in %d day(s)\n", card.IntervalDays)
+	}
+
+	fmt.Println("\n Practice session complete!")
+
+	return nil
+}
+
+func studyFlashcards(ctx context.Context, querier repo.Querier, db repo.TxBeginner, scanner *bufio.Scanner) error {
+	quizzes, err := querier.ListQuizzes(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(quizzes) == 0 {
+		fmt.Println("\n No quizzes available yet.")
+		return nil
+	}
+
+	fmt.Println("\n Available Quizzes:")
+	fmt.Println(strings.Repeat("-", 50))
+	for i, quiz := range quizzes {
+		fmt.Printf("%d. %s\n", i+1, quiz.Title)
+	}
+
+	choiceStr := getUserInput(scanner, "\nSelect a quiz (enter number): ")
+	choice, err := strconv.Atoi(choiceStr)
+	if err != nil || choice < 1 || choice > len(quizzes) {
+		return fmt.Errorf("invalid quiz selection")
+	}
+
+	selectedQuiz := quizzes[choice-1]
+
+	questions, err := querier.GetQuestionsByQuizID(ctx, selectedQuiz.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(questions) == 0 {
+		fmt.Println("\n❌ This quiz has no questions yet!")
+		return nil
+	}
+
+	userName := getUserInput(scanner, "\nEnter your name: ")
+	if userName == "" {
+		userName = "Anonymous"
+	}
+
+	// Flashcards reveal every answer, so they follow the quiz's reveal policy
+	// and availability window but not its attempt limit or cooldown
+	eligibility, err := querier.GetAttemptEligibility(ctx, repo.GetAttemptEligibilityParams{
+		ID:       selectedQuiz.ID,
+		UserName: userName,
+	})
+	if err != nil {
+		return err
+	}
+
+	decision := policy.Check(eligibility)
+	if !decision.AllowsUnofficial() {
+		fmt.Printf("\n⛔ Sorry, %s.\n", decision.Message)
+		return nil
+	}
+	if !policy.RevealAnswers(selectedQuiz.RevealPolicy, selectedQuiz.ClosesAt, decision.Now) {
+		fmt.Println("\n⛔ Answers for this quiz aren't revealed yet, so it can't be studied as flashcards.")
+		return nil
+	}
+
+	unlocked, err := checkUnlocked(ctx, querier, selectedQuiz.ID, userName)
+	if err != nil || !unlocked {
+		return err
+	}
+
+	// The check above only refuses early, the attempt is started under the
+	// player's lock once it has been checked again
+	attempt, _, err := grading.Start(ctx, db, repo.StartQuizAttemptParams{
+		QuizID:         selectedQuiz.ID,
+		UserName:       userName,
+		TotalQuestions: int32(len(questions)),
+		Mode:           policy.ModeFlashcard,
+	})
+	var refused grading.RefusedError
+	if errors.As(err, &refused) {
+		fmt.Printf("\n⛔ Sorry, %s.\n", refused.Decision.Message)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n FLASHCARDS - %s (%d cards)\n", selectedQuiz.Title, len(questions))
+	fmt.Println(" Think of the answer, press Enter to reveal it, then mark yourself.")
+	fmt.Println(strings.Repeat("=", 50))
+
+	score := 0.0
+	maxPoints := 0.0
+	answers := repo.CreateAttemptAnswersParams{AttemptID: attempt.ID}
+
+	for i, q := range questions {
+		fmt.Printf("\n Card %d of %d\n", i+1, len(questions))
+		fmt.Println(strings.Repeat("-", 50))
+		fmt.Println(q.QuestionText)
+		printOptions(q.OptionA, q.OptionB, q.OptionC, q.OptionD)
+
+		getUserInput(scanner, "\nPress Enter to reveal the answer...")
+
+		fullQuestion, err := querier.GetQuestionByID(ctx, q.ID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(" Answer: %s\n", fullQuestion.CorrectAnswer)
+		if fullQuestion.Explanation != "" {
+			fmt.Printf("💡 %s\n", fullQuestion.Explanation)
+		}
+
+		var knew string
+		for {
+			knew = strings.ToLower(getUserInput(scanner, "Did you know it? (y/n): "))
+			if knew == "y" || knew == "n" {
+				break
+			}
+			fmt.Println("❌ Please enter y or n")
+		}
+
+		// Self-graded cards record the correct answer or a blank
+		correct := knew == "y"
+		answer := ""
+		points := 0.0
+		if correct {
+			answer = fullQuestion.CorrectAnswer
+			points = fullQuestion.Points
+		}
+		score += points
+		maxPoints += fullQuestion.Points
+
+		answers.QuestionIds = append(answers.QuestionIds, fullQuestion.ID)
+		answers.Answers = append(answers.Answers, answer)
+		answers.Correct = append(answers.Correct, correct)
+		answers.PointsAwarded = append(answers.PointsAwarded, points)
+	}
+
+	attempt, err = querier.SubmitQuizAttempt(ctx, repo.SubmitQuizAttemptParams{
+		ID:             attempt.ID,
+		Score:          score,
+		MaxPoints:      maxPoints,
+		TotalQuestions: int32(len(questions)),
+		Passed:         scoring.Passed(score, maxPoints, selectedQuiz.PassPercent),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = querier.CreateAttemptAnswers(ctx, answers)
+	if err != nil {
+		return err
+	}
+
+	// Cards you didn't know go into your review queue
+	err = querier.EnqueueMissedQuestions(ctx, attempt.ID)
+	if err != nil {
+		return err
+	}
+
+	known := 0
+	for _, correct := range answers.Correct {
+		if correct {
+			known++
+		}
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 50))
+	fmt.Printf(" You knew %d of %d cards\n", known, len(questions))
+	if known < len(questions) {
+		fmt.Println(" The rest have been added to your practice queue.")
+	}
+	fmt.Println(strings.Repeat("=", 50))
+
+	return nil
+}
+
+func browseLearningPaths(ctx context.Context, querier repo.Querier, scanner *bufio.Scanner) error {
+	collections, err := querier.ListCollections(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(collections) == 0 {
+		fmt.Println("\n No learning paths available yet.")
+		return nil
+	}
+
+	fmt.Println("\n Learning Paths:")
+	fmt.Println(strings.Repeat("-", 50))
+	for i, col := range collections {
+		fmt.Printf("%d. %s (%d quizzes)\n", i+1, col.Title, col.QuizCount)
+		if col.Description != "" {
+			fmt.Printf("    %s\n", col.Description)
+		}
+	}
+
+	choiceStr := getUserInput(scanner, "\nSelect a learning path (enter number): ")
+	choice, err := strconv.Atoi(choiceStr)
+	if err != nil || choice < 1 || choice > len(collections) {
+		return fmt.Errorf("invalid learning path selection")
+	}
+
+	selected := collections[choice-1]
+
+	userName := getUserInput(scanner, "Enter your name: ")
+	if userName == "" {
+		userName = "Anonymous"
+	}
+
+	quizzes, err := querier.ListCollectionProgress(ctx, repo.ListCollectionProgressParams{
+		CollectionID: selected.ID,
+		UserName:     userName,
+	})
+	if err != nil {
+		return err
+	}
+
+	rules, err := querier.ListCollectionPrerequisites(ctx, selected.ID)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]repo.ListCollectionProgressRow, len(quizzes))
+	for _, q := range quizzes {
+		byID[q.QuizID] = q
+	}
+
+	fmt.Printf("\n %s - %s\n", strings.ToUpper(selected.Title), userName)
+	fmt.Println(strings.Repeat("=", 50))
+
+	completed := 0
+	for i, q := range quizzes {
+		var waiting []string
+		for _, rule := range rules {
+			if rule.QuizID != q.QuizID {
+				continue
+			}
+			required := byID[rule.RequiresQuizID]
+			p := collection.Prerequisite{
+				QuizID:      required.QuizID,
+				MinPercent:  rule.MinPercent,
+				PassPercent: required.PassPercent,
+				Result:      collection.Result{Attempts: required.Attempts, BestPercent: required.BestScorePercent},
+			}
+			if !p.Met() {
+				waiting = append(waiting, prerequisiteLabel(required.Title, p.RequiredPercent()))
+			}
+		}
+
+		status := "🔓"
+		switch {
+		case collection.Completed(q.PassPercent, collection.Result{Attempts: q.Attempts, BestPercent: q.BestScorePercent}):
+			status = "✅"
+			completed++
+		case len(waiting) > 0:
+			status = "🔒"
+		}
+
+		fmt.Printf("%s %d. %s", status, i+1, q.Title)
+		if q.Attempts > 0 {
+			fmt.Printf(" (best %.1f%%)", q.BestScorePercent)
+		}
+		fmt.Println()
+		if len(waiting) > 0 {
+			fmt.Printf("     Unlocks after: %s\n", strings.Join(waiting, ", "))
+		}
+	}
+
+	fmt.Println(strings.Repeat("-", 50))
+	fmt.Printf(" Completed %d of %d quizzes\n", completed, len(quizzes))
+
+	return nil
+}
+
+// checkUnlocked reports whether the player has passed the prerequisites of a
+// quiz in every learning path it belongs to, listing what is missing if not.
+func checkUnlocked(ctx context.Context, querier repo.Querier, quizID, userName string) (bool, error) {
+	rows, err := querier.GetQuizPrerequisites(ctx, repo.GetQuizPrerequisitesParams{
+		QuizID:   quizID,
+		UserName: userName,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var waiting []string
+	for _, r := range rows {
+		p := collection.Prerequisite{
+			QuizID:      r.RequiresQuizID,
+			MinPercent:  r.MinPercent,
+			PassPercent: r.PassPercent,
+			Result:      collection.Result{Attempts: r.Attempts, BestPercent: r.BestScorePercent},
+		}
+		if !p.Met() {
+			waiting = append(waiting, fmt.Sprintf("%s in %s", prerequisiteLabel(r.RequiresQuizTitle, p.RequiredPercent()), r.CollectionTitle))
+		}
+	}
+
+	if len(waiting) > 0 {
+		fmt.Println("\n🔒 This quiz is locked. First pass:")
+		for _, w := range waiting {
+			fmt.Printf("   - %s\n", w)
+		}
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func prerequisiteLabel(title string, requiredPercent *float64) string {
+	if requiredPercent == nil {
+		return title
+	}
+	return fmt.Sprintf("%s (%.0f%%)", title, *requiredPercent)
+}
+
+func takeQuiz(ctx context.Context, querier repo.Querier, db repo.TxBeginner, scanner *bufio.Scanner) error {
 	// List available quizzes first
 	quizzes, err := querier.ListQuizzes(ctx)
 	if err != nil {
@@ -176,7 +701,7 @@ func takeQuiz(ctx context.Context, querier repo.Querier, scanner *bufio.Scanner)
 	fmt.Println(strings.Repeat("-", 50))
 	for i, quiz := range quizzes {
 		questions, _ := querier.GetQuestionsByQuizID(ctx, quiz.ID)
-		fmt.Printf("%d. %s (%d questions)\n", i+1, quiz.Title, len(questions))
+		fmt.Printf("%d. %s (%d questions)%s\n", i+1, quiz.Title, len(questions), availabilityLabel(ctx, querier, quiz.ID))
 	}
 
 	// Get quiz selection
@@ -205,7 +730,63 @@ func takeQuiz(ctx context.Context, querier repo.Querier, scanner *bufio.Scanner)
 		userName = "Anonymous"
 	}
 
+	// Check the quiz's availability window, attempt limit and cooldown
+	eligibility, err := querier.GetAttemptEligibility(ctx, repo.GetAttemptEligibilityParams{
+		ID:       selectedQuiz.ID,
+		UserName: userName,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Practice attempts stay out of leaderboards and statistics
+	mode := policy.ModeExam
+	if strings.ToLower(getUserInput(scanner, "Practice run? It won't count on the leaderboard (y/N): ")) == "y" {
+		mode = policy.ModePractice
+	}
+
+	decision := policy.Check(eligibility)
+	if !decision.Allowed && (mode == policy.ModeExam || !decision.AllowsUnofficial()) {
+		fmt.Printf("\n⛔ Sorry, %s.\n", decision.Message)
+		if wait := decision.RetryAfter(); wait > 0 {
+			fmt.Printf("   You can try again in %s.\n", formatWait(wait))
+		}
+		return nil
+	}
+
+	unlocked, err := checkUnlocked(ctx, querier, selectedQuiz.ID, userName)
+	if err != nil || !unlocked {
+		return err
+	}
+	if decision.AttemptsRemaining != nil && mode == policy.ModeExam {
+		fmt.Printf("\n Attempts remaining: %d (including this one)\n", *decision.AttemptsRemaining)
+	}
+
+	// Start the attempt so hints can be recorded against it. The check
+	// above only refuses early, the attempt is started under the player's
+	// lock once it has been checked again
+	attempt, _, err := grading.Start(ctx, db, repo.StartQuizAttemptParams{
+		QuizID:         selectedQuiz.ID,
+		UserName:       userName,
+		TotalQuestions: int32(len(questions)),
+		Mode:           mode,
+	})
+	var refused grading.RefusedError
+	if errors.As(err, &refused) {
+		fmt.Printf("\n⛔ Sorry, %s.\n", refused.Decision.Message)
+		if wait := refused.Decision.RetryAfter(); wait > 0 {
+			fmt.Printf("   You can try again in %s.\n", formatWait(wait))
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
 	fmt.Printf("\n Starting Quiz: %s\n", selectedQuiz.Title)
+	if !attempt.Official {
+		fmt.Println(" Practice mode: this attempt won't count on leaderboards")
+	}
 	fmt.Printf(" Total Questions: %d\n", len(questions))
 	fmt.Println(strings.Repeat("=", 50))
 
@@ -213,24 +794,43 @@ func takeQuiz(ctx context.Context, querier repo.Querier, scanner *bufio.Scanner)
 	startTime := time.Now()
 
 	// Ask questions and collect answers
-	score := 0
+	reveal := policy.RevealAnswers(selectedQuiz.RevealPolicy, selectedQuiz.ClosesAt, decision.Now)
+	graded := make([]scoring.Question, 0, len(questions))
+	answers := make(map[string]string, len(questions))
+	rules := scoring.Rules{
+		WrongAnswerPenalty: selectedQuiz.WrongAnswerPenalty,
+		HintPenalty:        selectedQuiz.HintPenalty,
+	}
 
 	for i, q := range questions {
-		fmt.Printf("\n❓ Question %d of %d\n", i+1, len(questions))
+		fmt.Printf("\n❓ Question %d of %d (%g pts)\n", i+1, len(questions), q.Points)
 		fmt.Println(strings.Repeat("-", 50))
 		fmt.Println(q.QuestionText)
-		fmt.Printf("  A) %s\n", q.OptionA)
-		fmt.Printf("  B) %s\n", q.OptionB)
-		fmt.Printf("  C) %s\n", q.OptionC)
-		fmt.Printf("  D) %s\n", q.OptionD)
+		printOptions(q.OptionA, q.OptionB, q.OptionC, q.OptionD)
 
 		var answer string
+		hintsUsed := 0
 		for {
-			answer = strings.ToUpper(getUserInput(scanner, "\nYour answer (A/B/C/D): "))
-			if answer == "A" || answer == "B" || answer == "C" || answer == "D" {
+			prompt := "\nYour answer (A/B/C/D): "
+			if hintsUsed < int(q.HintCount) {
+				prompt = "\nYour answer (A/B/C/D, H for hint): "
+			}
+
+			answer = strings.ToUpper(getUserInput(scanner, prompt))
+			if scoring.ValidAnswer(answer, q.OptionA, q.OptionB, q.OptionC, q.OptionD) {
 				break
 			}
-			fmt.Println("❌ Invalid answer. Please enter A, B, C, or D.")
+			if answer == "H" && hintsUsed < int(q.HintCount) {
+				used, err := showHint(ctx, querier, attempt.ID, q)
+				if err != nil {
+					return err
+				}
+				hintsUsed = used
+				fmt.Printf("   This question is now worth %g pts\n",
+					scoring.AvailablePoints(q.Points, hintsUsed, rules.HintPenalty))
+				continue
+			}
+			fmt.Println("❌ Invalid answer. Please enter the letter of one of the options.")
 		}
 
 		// Get full question with correct answer
@@ -239,35 +839,84 @@ func takeQuiz(ctx context.Context, querier repo.Querier, scanner *bufio.Scanner)
 			continue
 		}
 
-		if answer == fullQuestion.CorrectAnswer {
-			score++
+		question := scoring.Question{
+			ID:            fullQuestion.ID,
+			CorrectAnswer: fullQuestion.CorrectAnswer,
+			Points:        fullQuestion.Points,
+			HintsUsed:     hintsUsed,
+		}
+		graded = append(graded, question)
+		answers[question.ID] = answer
+
+		// Each answer is marked straight away; the attempt is graded as a
+		// whole when it is submitted
+		result := scoring.GradeAnswer(question, answer, rules)
+
+		switch {
+		case result.Correct:
 			fmt.Println("✅ Correct!")
-		} else {
+		case reveal:
 			fmt.Printf("❌ Wrong! The correct answer was %s\n", fullQuestion.CorrectAnswer)
+		default:
+			fmt.Println("❌ Wrong!")
+		}
+		if result.Points < 0 {
+			fmt.Printf("   %g pts\n", result.Points)
+		}
+		if reveal && fullQuestion.Explanation != "" {
+			fmt.Printf("💡 %s\n", fullQuestion.Explanation)
 		}
 	}
 
 	duration := time.Since(startTime)
 
-	// Save attempt
-	_, err = querier.CreateQuizAttempt(ctx, repo.CreateQuizAttemptParams{
-		QuizID:         selectedQuiz.ID,
-		UserName:       userName,
-		Score:          int32(score),
-		TotalQuestions: int32(len(questions)),
+	// Save the attempt with its answers in one transaction
+	var submitted grading.Submitted
+	err = repo.ExecTx(ctx, db, func(q repo.Querier) error {
+		submitted, err = grading.Submit(ctx, q, grading.Submission{
+			Quiz:      selectedQuiz,
+			AttemptID: attempt.ID,
+			Questions: graded,
+			Answers:   answers,
+		})
+		return err
 	})
 	if err != nil {
 		return err
 	}
+	attempt = submitted.Attempt
+	score, maxPoints := submitted.Summary.Score, submitted.Summary.MaxPoints
+
+	err = grading.RecalculateDifficulty(ctx, querier, selectedQuiz.ID)
+	if err != nil {
+		return err
+	}
 
 	// Display results
-	percentage := float64(score) / float64(len(questions)) * 100
+	percentage := scoring.Percent(score, maxPoints)
 	fmt.Println("\n" + strings.Repeat("=", 50))
 	fmt.Println(" QUIZ COMPLETED!")
 	fmt.Println(strings.Repeat("=", 50))
 	fmt.Printf(" Player: %s\n", userName)
-	fmt.Printf(" Score: %d/%d (%.1f%%)\n", score, len(questions), percentage)
+	fmt.Printf(" Score: %g/%g pts (%.1f%%)\n", score, maxPoints, percentage)
 	fmt.Printf("⏱  Time: %s\n", formatDuration(duration))
+	if attempt.Official {
+		if percentile, err := querier.GetAttemptPercentile(ctx, attempt.ID); err == nil {
+			fmt.Printf(" Percentile: %.0f (compared with all attempts on this quiz)\n", percentile)
+		}
+	}
+
+	// Show pass/fail outcome and certificate
+	if selectedQuiz.PassPercent != nil {
+		if submitted.Passed {
+			fmt.Printf(" Result: PASSED (pass mark %.0f%%)\n", *selectedQuiz.PassPercent)
+			if submitted.Certificate != nil {
+				fmt.Printf(" Certificate code: %s\n", submitted.Certificate.VerificationCode)
+			}
+		} else {
+			fmt.Printf(" Result: NOT PASSED (pass mark %.0f%%)\n", *selectedQuiz.PassPercent)
+		}
+	}
 	
 	// Show performance message
 	if percentage == 100 {
@@ -282,8 +931,8 @@ func takeQuiz(ctx context.Context, querier repo.Querier, scanner *bufio.Scanner)
 
 	// Show ranking
 	attempts, _ := querier.GetQuizAttemptsByQuizID(ctx, selectedQuiz.ID)
-	for i, attempt := range attempts {
-		if attempt.UserName == userName && attempt.Score == int32(score) {
+	for i, a := range attempts {
+		if a.ID == attempt.ID {
 			fmt.Printf(" Your rank: #%d out of %d attempts\n", i+1, len(attempts))
 			break
 		}
@@ -342,7 +991,7 @@ func viewLeaderboard(ctx context.Context, querier repo.Querier, scanner *bufio.S
 	fmt.Println(strings.Repeat("-", 70))
 
 	for i, attempt := range attempts {
-		percentage := float64(attempt.Score) / float64(attempt.TotalQuestions) * 100
+		percentage := scoring.Percent(attempt.Score, attempt.MaxPoints)
 		rank := fmt.Sprintf("#%d", i+1)
 		medal := ""
 		switch i {
@@ -364,7 +1013,7 @@ func viewLeaderboard(ctx context.Context, querier repo.Querier, scanner *bufio.S
 		fmt.Printf("%-5s %-25s %-12s %-15s %-10s %s\n",
 			rank,
 			displayName,
-			fmt.Sprintf("%d/%d", attempt.Score, attempt.TotalQuestions),
+			fmt.Sprintf("%g/%g", attempt.Score, attempt.MaxPoints),
 			fmt.Sprintf("%.1f%%", percentage),
 			func() string {
 				if attempt.CreatedAt.Valid {
@@ -388,8 +1037,8 @@ func showGlobalLeaderboard(ctx context.Context, querier repo.Querier) error {
 
 	type PlayerStats struct {
 		Name          string
-		TotalScore    int
-		TotalQuestions int
+		TotalScore    float64
+		TotalPoints   float64
 		QuizzesTaken  int
 	}
 
@@ -404,14 +1053,14 @@ func showGlobalLeaderboard(ctx context.Context, querier repo.Querier) error {
 
 		for _, attempt := range attempts {
 			if stats, exists := playerMap[attempt.UserName]; exists {
-				stats.TotalScore += int(attempt.Score)
-				stats.TotalQuestions += int(attempt.TotalQuestions)
+				stats.TotalScore += attempt.Score
+				stats.TotalPoints += attempt.MaxPoints
 				stats.QuizzesTaken++
 			} else {
 				playerMap[attempt.UserName] = &PlayerStats{
 					Name:          attempt.UserName,
-					TotalScore:    int(attempt.Score),
-					TotalQuestions: int(attempt.TotalQuestions),
+					TotalScore:    attempt.Score,
+					TotalPoints:   attempt.MaxPoints,
 					QuizzesTaken:  1,
 				}
 			}
@@ -427,8 +1076,8 @@ func showGlobalLeaderboard(ctx context.Context, querier repo.Querier) error {
 	// Sort by percentage
 	for i := 0; i < len(players)-1; i++ {
 		for j := i + 1; j < len(players); j++ {
-			pct1 := float64(players[i].TotalScore) / float64(players[i].TotalQuestions)
-			pct2 := float64(players[j].TotalScore) / float64(players[j].TotalQuestions)
+			pct1 := scoring.Percent(players[i].TotalScore, players[i].TotalPoints)
+			pct2 := scoring.Percent(players[j].TotalScore, players[j].TotalPoints)
 			if pct2 > pct1 {
 				players[i], players[j] = players[j], players[i]
 			}
@@ -445,7 +1094,7 @@ func showGlobalLeaderboard(ctx context.Context, querier repo.Querier) error {
 			break
 		}
 
-		percentage := float64(stats.TotalScore) / float64(stats.TotalQuestions) * 100
+		percentage := scoring.Percent(stats.TotalScore, stats.TotalPoints)
 		rank := fmt.Sprintf("#%d", i+1)
 		medal := ""
 			switch i {
@@ -466,7 +1115,7 @@ func showGlobalLeaderboard(ctx context.Context, querier repo.Querier) error {
 		fmt.Printf("%-5s %-25s %-12s %-15s %-10d %s\n",
 			rank,
 			displayName,
-			fmt.Sprintf("%d/%d", stats.TotalScore, stats.TotalQuestions),
+			fmt.Sprintf("%g/%g", stats.TotalScore, stats.TotalPoints),
 			fmt.Sprintf("%.1f%%", percentage),
 			stats.QuizzesTaken,
 			medal,
@@ -484,38 +1133,11 @@ func viewMyHistory(ctx context.Context, querier repo.Querier, scanner *bufio.Sca
 		return nil
 	}
 
-	quizzes, err := querier.ListQuizzes(ctx)
+	userAttempts, err := querier.ListPlayerAttempts(ctx, userName)
 	if err != nil {
 		return err
 	}
 
-	type AttemptWithQuiz struct {
-		QuizTitle      string
-		Score          int32
-		TotalQuestions int32
-		CreatedAt      time.Time
-	}
-
-	var userAttempts []AttemptWithQuiz
-
-	for _, quiz := range quizzes {
-		attempts, err := querier.GetQuizAttemptsByQuizID(ctx, quiz.ID)
-		if err != nil {
-			continue
-		}
-
-		for _, attempt := range attempts {
-			if attempt.UserName == userName {
-				userAttempts = append(userAttempts, AttemptWithQuiz{
-					QuizTitle:      quiz.Title,
-					Score:          attempt.Score,
-					TotalQuestions: attempt.TotalQuestions,
-					CreatedAt:      attempt.CreatedAt.Time,
-				})
-			}
-		}
-	}
-
 	if len(userAttempts) == 0 {
 		fmt.Printf("\n No attempts found for '%s'\n", userName)
 		return nil
@@ -526,38 +1148,60 @@ func viewMyHistory(ctx context.Context, querier repo.Querier, scanner *bufio.Sca
 	fmt.Printf("%-30s %-12s %-15s %-12s\n", "Quiz", "Score", "Percentage", "Date")
 	fmt.Println(strings.Repeat("-", 70))
 
-	totalScore := 0
-	totalQuestions := 0
+	totalScore := 0.0
+	totalPoints := 0.0
 
 	for _, attempt := range userAttempts {
-		percentage := float64(attempt.Score) / float64(attempt.TotalQuestions) * 100
+		percentage := scoring.Percent(attempt.Score, attempt.MaxPoints)
 		
 		quizTitle := attempt.QuizTitle
 		if len(quizTitle) > 30 {
 			quizTitle = quizTitle[:27] + "..."
 		}
 
+		date := attempt.CreatedAt.Time.Format("Jan 02, 2006")
+		if !policy.Official(attempt.Mode) {
+			date += " (" + attempt.Mode + ")"
+		}
+
 		fmt.Printf("%-30s %-12s %-15s %-12s\n",
 			quizTitle,
-			fmt.Sprintf("%d/%d", attempt.Score, attempt.TotalQuestions),
+			fmt.Sprintf("%g/%g", attempt.Score, attempt.MaxPoints),
 			fmt.Sprintf("%.1f%%", percentage),
-			attempt.CreatedAt.Format("Jan 02, 2006"),
+			date,
 		)
 
-		totalScore += int(attempt.Score)
-		totalQuestions += int(attempt.TotalQuestions)
+		totalScore += attempt.Score
+		totalPoints += attempt.MaxPoints
 	}
 
 	fmt.Println(strings.Repeat("-", 70))
-	overallPct := float64(totalScore) / float64(totalQuestions) * 100
+	overallPct := scoring.Percent(totalScore, totalPoints)
 	fmt.Printf("%-30s %-12s %-15s\n",
 		"OVERALL",
-		fmt.Sprintf("%d/%d", totalScore, totalQuestions),
+		fmt.Sprintf("%g/%g", totalScore, totalPoints),
 		fmt.Sprintf("%.1f%%", overallPct),
 	)
 	fmt.Println(strings.Repeat("=", 70))
 	fmt.Printf("Total quizzes taken: %d\n", len(userAttempts))
 
+	streaks, err := querier.GetPlayerStreaks(ctx, userName)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Current streak: %d days (longest %d)\n", streaks.CurrentStreakDays, streaks.LongestStreakDays)
+
+	progress, err := querier.GetPlayerQuizProgress(ctx, userName)
+	if err != nil {
+		return err
+	}
+	fmt.Println("\n PROGRESS BY QUIZ")
+	fmt.Println(strings.Repeat("-", 70))
+	for _, p := range progress {
+		fmt.Printf("%-30s best %.1f%%, %+.1f%% since first try (%d attempts)\n",
+			p.QuizTitle, p.BestScorePercent, p.ImprovementPercent, p.Attempts)
+	}
+
 	return nil
 }
 
@@ -568,8 +1212,8 @@ func viewGlobalStats(ctx context.Context, querier repo.Querier) error {
 	}
 
 	totalAttempts := 0
-	totalScore := 0
-	totalQuestions := 0
+	totalScore := 0.0
+	totalPoints := 0.0
 	uniquePlayers := make(map[string]bool)
 
 	fmt.Println("\n GLOBAL STATISTICS")
@@ -583,8 +1227,8 @@ func viewGlobalStats(ctx context.Context, querier repo.Querier) error {
 
 		for _, attempt := range attempts {
 			totalAttempts++
-			totalScore += int(attempt.Score)
-			totalQuestions += int(attempt.TotalQuestions)
+			totalScore += attempt.Score
+			totalPoints += attempt.MaxPoints
 			uniquePlayers[attempt.UserName] = true
 		}
 	}
@@ -594,7 +1238,7 @@ func viewGlobalStats(ctx context.Context, querier repo.Querier) error {
 	fmt.Printf(" Total Attempts: %d\n", totalAttempts)
 	
 	if totalAttempts > 0 {
-		avgPercentage := float64(totalScore) / float64(totalQuestions) * 100
+		avgPercentage := scoring.Percent(totalScore, totalPoints)
 		fmt.Printf(" Average Score: %.1f%%\n", avgPercentage)
 		fmt.Printf(" Average Attempts per Quiz: %.1f\n", float64(totalAttempts)/float64(len(quizzes)))
 	}
@@ -646,6 +1290,73 @@ func getUserInput(scanner *bufio.Scanner, prompt string) string {
 	return strings.TrimSpace(scanner.Text())
 }
 
+// printOptions lists a question's options, skipping the unused ones of
+// true/false and three-option questions.
+func printOptions(options ...string) {
+	for i, option := range options {
+		if option != "" {
+			fmt.Printf("  %c) %s\n", 'A'+i, option)
+		}
+	}
+}
+
+// showHint reveals the next hint for a question and returns how many hints
+// have now been used on it.
+func showHint(ctx context.Context, querier repo.Querier, attemptID string, q repo.GetQuestionsByQuizIDRow) (int, error) {
+	used, err := querier.UseHint(ctx, repo.UseHintParams{
+		AttemptID:  attemptID,
+		QuestionID: q.ID,
+		MaxHints:   q.HintCount,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	fullQuestion, err := querier.GetQuestionByID(ctx, q.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	fmt.Printf("🔎 Hint %d of %d: %s\n", used, q.HintCount, fullQuestion.Hints[used-1])
+	return int(used), nil
+}
+
+// availabilityLabel describes when a quiz opens or whether it has closed. It
+// returns an empty string for quizzes that can be taken now.
+func availabilityLabel(ctx context.Context, querier repo.Querier, quizID string) string {
+	eligibility, err := querier.GetAttemptEligibility(ctx, repo.GetAttemptEligibilityParams{ID: quizID})
+	if err != nil {
+		return ""
+	}
+
+	decision := policy.Check(eligibility)
+	switch decision.Reason {
+	case policy.NotOpenYet:
+		return fmt.Sprintf(" [opens in %s]", formatWait(decision.RetryAfter()))
+	case policy.Closed:
+		return " [closed]"
+	}
+
+	return ""
+}
+
+// formatWait formats a waiting time using its two most significant units.
+func formatWait(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return formatDuration(d)
+	}
+}
+
 func formatDuration(d time.Duration) string {
 	minutes := int(d.Minutes())
 	seconds := int(d.Seconds()) % 60
@@ -0,0 +1,178 @@
+// Command sync makes the database match a directory of quiz files (see
+// package quizsync), so quizzes can be kept in git and changed through pull
+// requests:
+//
+//	go run ./cmd/sync plan --dir=quizzes
+//	go run ./cmd/sync apply --dir=quizzes [--force]
+//
+// plan shows what apply would create, update and delete without writing
+// anything. apply plans again and makes the changes in a single
+// transaction. Destructive changes to quizzes that have attempts, such as
+// deleting a question or changing its correct answer, are refused unless
+// --force is given. Quiz files are linted first (see package lint); apply
+// refuses files with error findings, with or without --force.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/lint"
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+	"github.com/Iknite-Space/sqlc-example-api/quizsync"
+	"github.com/ardanlabs/conf/v3"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+type DBConfig struct {
+	DBUser      string `conf:"env:DB_USER,required"`
+	DBPassword  string `conf:"env:DB_PASSWORD,required,mask"`
+	DBHost      string `conf:"env:DB_HOST,required"`
+	DBPort      uint16 `conf:"env:DB_PORT,required"`
+	DBName      string `conf:"env:DB_Name,required"`
+	TLSDisabled bool   `conf:"env:DB_TLS_DISABLED"`
+}
+
+type Config struct {
+	DB    DBConfig
+	Dir   string `conf:"env:SYNC_DIR,default:db/fixtures,help:directory of quiz files"`
+	Force bool   `conf:"help:apply destructive changes to quizzes that have attempts"`
+	Lint  string `conf:"env:LINT_CONFIG,help:YAML file of lint rule settings"`
+	Args  conf.Args
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ctx := context.Background()
+	config := Config{}
+
+	if _, err := os.Stat(".env"); err == nil {
+		err = godotenv.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load env file: %w", err)
+		}
+	}
+
+	help, err := conf.Parse("", &config)
+	if errors.Is(err, conf.ErrHelpWanted) {
+		fmt.Println(help)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	command := config.Args.Num(0)
+	if command != "plan" && command != "apply" {
+		return errors.New("usage: sync plan|apply [--dir=db/fixtures] [--force]")
+	}
+
+	// Validate every file before touching the database
+	quizzes, err := quizfile.ReadDir(config.Dir)
+	if err != nil {
+		return fmt.Errorf("invalid quiz files:\n%w", err)
+	}
+
+	rules, err := lint.LoadConfig(config.Lint)
+	if err != nil {
+		return err
+	}
+	var findings []lint.Finding
+	for _, q := range quizzes {
+		findings = append(findings, lint.Quiz(q, rules)...)
+	}
+	for _, f := range findings {
+		fmt.Println(f)
+	}
+	lintErrors := lint.Count(findings, lint.Error)
+	if lintErrors > 0 && command == "apply" {
+		return fmt.Errorf("%d lint errors in quiz files, fix them before applying", lintErrors)
+	}
+	if len(findings) > 0 {
+		fmt.Println()
+	}
+
+	db, err := pgxpool.New(ctx, getPostgresConnectionURL(config.DB))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	// The plan is built and applied in one transaction, so what is applied
+	// is exactly what was shown. A plan only reads, so it writes nothing.
+	var plan quizsync.Plan
+	applied := false
+	err = repo.ExecTx(ctx, db, func(querier repo.Querier) error {
+		plan, err = quizsync.Build(ctx, querier, quizzes)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Syncing %d quiz files from %s\n", len(quizzes), config.Dir)
+		if plan.Empty() {
+			fmt.Println("\nNo changes: the database matches the files.")
+			return nil
+		}
+		err = plan.Write(os.Stdout)
+		if err != nil {
+			return err
+		}
+
+		destructive := plan.Destructive()
+		if len(destructive) > 0 && !config.Force {
+			fmt.Printf("\n%d changes marked ! would delete or change the answers of questions that have been attempted.\n", len(destructive))
+			if command == "apply" {
+				return errors.New("refusing destructive changes, review them and apply with --force")
+			}
+		}
+
+		if command == "plan" {
+			if lintErrors > 0 {
+				fmt.Printf("\n%d lint errors must be fixed before apply.\n", lintErrors)
+			}
+			fmt.Println("\nNothing was written. Run sync apply to make these changes.")
+			return nil
+		}
+
+		applied = true
+		return quizsync.Apply(ctx, querier, plan)
+	})
+	if err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("\n✅ Applied: %d created, %d updated, %d deleted\n",
+		plan.Count(quizsync.Create), plan.Count(quizsync.Update), plan.Count(quizsync.Delete))
+	return nil
+}
+
+func getPostgresConnectionURL(config DBConfig) string {
+	queryValues := url.Values{}
+	if config.TLSDisabled {
+		queryValues.Add("sslmode", "disable")
+	} else {
+		queryValues.Add("sslmode", "require")
+	}
+
+	dbURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.DBUser, config.DBPassword),
+		Host:     fmt.Sprintf("%s:%d", config.DBHost, config.DBPort),
+		Path:     config.DBName,
+		RawQuery: queryValues.Encode(),
+	}
+
+	return dbURL.String()
+}
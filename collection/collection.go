@@ -0,0 +1,45 @@
+// Package collection decides which quizzes of a learning path a player has
+// unlocked.
+package collection
+
+// Result is a player's record on a quiz, counting official attempts only.
+type Result struct {
+	Attempts    int64
+	BestPercent float64
+}
+
+// Completed reports whether a result passes a quiz's own pass mark. Quizzes
+// without a pass mark are completed by any official attempt.
+func Completed(passPercent *float64, r Result) bool {
+	if r.Attempts == 0 {
+		return false
+	}
+	return passPercent == nil || r.BestPercent >= *passPercent
+}
+
+// Prerequisite requires a quiz to be passed before another unlocks.
+type Prerequisite struct {
+	QuizID string
+	// MinPercent is the score needed on the required quiz. When nil the
+	// required quiz's own pass mark, PassPercent, applies instead.
+	MinPercent  *float64
+	PassPercent *float64
+	Result      Result
+}
+
+// Met reports whether the player has satisfied p.
+func (p Prerequisite) Met() bool {
+	if p.MinPercent == nil {
+		return Completed(p.PassPercent, p.Result)
+	}
+	return p.Result.Attempts > 0 && p.Result.BestPercent >= *p.MinPercent
+}
+
+// RequiredPercent is the score needed on the required quiz, or nil when any
+// official attempt will do.
+func (p Prerequisite) RequiredPercent() *float64 {
+	if p.MinPercent != nil {
+		return p.MinPercent
+	}
+	return p.PassPercent
+}
@@ -0,0 +1,4 @@
+ALTER TABLE quiz_attempts DROP COLUMN IF EXISTS max_points;
+ALTER TABLE quiz_attempts ALTER COLUMN score TYPE INTEGER USING round(score)::integer;
+ALTER TABLE quizzes DROP COLUMN IF EXISTS wrong_answer_penalty;
+ALTER TABLE questions DROP COLUMN IF EXISTS points;
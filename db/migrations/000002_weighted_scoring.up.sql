@@ -0,0 +1,16 @@
+ALTER TABLE questions
+    ADD COLUMN points DOUBLE PRECISION NOT NULL DEFAULT 1 CHECK (points > 0);
+
+ALTER TABLE quizzes
+    ADD COLUMN wrong_answer_penalty DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (wrong_answer_penalty >= 0);
+
+ALTER TABLE quiz_attempts
+    ALTER COLUMN score TYPE DOUBLE PRECISION;
+
+ALTER TABLE quiz_attempts
+    ADD COLUMN max_points DOUBLE PRECISION;
+
+UPDATE quiz_attempts SET max_points = total_questions;
+
+ALTER TABLE quiz_attempts
+    ALTER COLUMN max_points SET NOT NULL;
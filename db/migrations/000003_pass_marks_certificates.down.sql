@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS certificates;
+ALTER TABLE quiz_attempts DROP COLUMN IF EXISTS passed;
+ALTER TABLE quizzes DROP COLUMN IF EXISTS pass_percent;
@@ -0,0 +1,12 @@
+ALTER TABLE quizzes
+    ADD COLUMN pass_percent DOUBLE PRECISION CHECK (pass_percent >= 0 AND pass_percent <= 100);
+
+ALTER TABLE quiz_attempts
+    ADD COLUMN passed BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE certificates (
+    id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid()::varchar(36),
+    attempt_id VARCHAR(36) NOT NULL UNIQUE REFERENCES quiz_attempts(id) ON DELETE CASCADE,
+    verification_code VARCHAR(32) NOT NULL UNIQUE,
+    issued_at TIMESTAMP DEFAULT now()
+);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS quiz_attempts_quiz_user_idx;
+ALTER TABLE quizzes
+    DROP CONSTRAINT IF EXISTS quizzes_window_check,
+    DROP COLUMN IF EXISTS closes_at,
+    DROP COLUMN IF EXISTS opens_at,
+    DROP COLUMN IF EXISTS cooldown_seconds,
+    DROP COLUMN IF EXISTS max_attempts;
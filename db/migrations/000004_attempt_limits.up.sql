@@ -0,0 +1,8 @@
+ALTER TABLE quizzes
+    ADD COLUMN max_attempts INTEGER CHECK (max_attempts > 0),
+    ADD COLUMN cooldown_seconds INTEGER NOT NULL DEFAULT 0 CHECK (cooldown_seconds >= 0),
+    ADD COLUMN opens_at TIMESTAMP,
+    ADD COLUMN closes_at TIMESTAMP,
+    ADD CONSTRAINT quizzes_window_check CHECK (opens_at IS NULL OR closes_at IS NULL OR opens_at < closes_at);
+
+CREATE INDEX quiz_attempts_quiz_user_idx ON quiz_attempts (quiz_id, user_name, created_at);
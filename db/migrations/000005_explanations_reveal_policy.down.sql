@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS attempt_answers;
+ALTER TABLE quizzes DROP COLUMN IF EXISTS reveal_policy;
+ALTER TABLE questions DROP COLUMN IF EXISTS explanation;
@@ -0,0 +1,17 @@
+ALTER TABLE questions
+    ADD COLUMN explanation TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE quizzes
+    ADD COLUMN reveal_policy VARCHAR(20) NOT NULL DEFAULT 'after_attempt'
+        CHECK (reveal_policy IN ('never', 'after_attempt', 'after_close'));
+
+CREATE TABLE attempt_answers (
+    attempt_id VARCHAR(36) NOT NULL REFERENCES quiz_attempts(id) ON DELETE CASCADE,
+    question_id VARCHAR(36) NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    answer VARCHAR(1) NOT NULL DEFAULT '' CHECK (answer IN ('', 'A', 'B', 'C', 'D')),
+    is_correct BOOLEAN NOT NULL,
+    points_awarded DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (attempt_id, question_id)
+);
+
+CREATE INDEX attempt_answers_question_idx ON attempt_answers (question_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS attempt_hints;
+DELETE FROM quiz_attempts WHERE status = 'in_progress';
+ALTER TABLE quiz_attempts DROP COLUMN IF EXISTS status;
+ALTER TABLE quizzes DROP COLUMN IF EXISTS hint_penalty;
+ALTER TABLE questions DROP COLUMN IF EXISTS hints;
@@ -0,0 +1,15 @@
+ALTER TABLE questions
+    ADD COLUMN hints TEXT[];
+
+ALTER TABLE quizzes
+    ADD COLUMN hint_penalty DOUBLE PRECISION NOT NULL DEFAULT 0.25 CHECK (hint_penalty >= 0 AND hint_penalty <= 1);
+
+ALTER TABLE quiz_attempts
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'submitted' CHECK (status IN ('in_progress', 'submitted'));
+
+CREATE TABLE attempt_hints (
+    attempt_id VARCHAR(36) NOT NULL REFERENCES quiz_attempts(id) ON DELETE CASCADE,
+    question_id VARCHAR(36) NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    hints_used INTEGER NOT NULL CHECK (hints_used > 0),
+    PRIMARY KEY (attempt_id, question_id)
+);
@@ -0,0 +1,23 @@
+CREATE TABLE tags (
+    id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid()::varchar(36),
+    name VARCHAR(50) NOT NULL,
+    slug VARCHAR(60) NOT NULL UNIQUE,
+    category VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE TABLE quiz_tags (
+    quiz_id VARCHAR(36) NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    tag_id VARCHAR(36) NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (quiz_id, tag_id)
+);
+
+CREATE INDEX quiz_tags_tag_idx ON quiz_tags (tag_id);
+
+CREATE TABLE question_tags (
+    question_id VARCHAR(36) NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    tag_id VARCHAR(36) NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (question_id, tag_id)
+);
+
+CREATE INDEX question_tags_tag_idx ON question_tags (tag_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS questions_search_idx;
+ALTER TABLE questions DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS quizzes_search_idx;
+ALTER TABLE quizzes DROP COLUMN IF EXISTS search_vector;
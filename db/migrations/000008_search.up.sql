@@ -0,0 +1,14 @@
+ALTER TABLE quizzes ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+) STORED;
+
+CREATE INDEX quizzes_search_idx ON quizzes USING GIN (search_vector);
+
+-- Only the question text is indexed; options, answers, explanations and
+-- hints stay out of search so results can't give answers away.
+ALTER TABLE questions ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('english', coalesce(question_text, ''))
+) STORED;
+
+CREATE INDEX questions_search_idx ON questions USING GIN (search_vector);
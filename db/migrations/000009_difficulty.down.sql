@@ -0,0 +1,9 @@
+ALTER TABLE quizzes
+    DROP COLUMN IF EXISTS response_count,
+    DROP COLUMN IF EXISTS empirical_difficulty,
+    DROP COLUMN IF EXISTS difficulty;
+
+ALTER TABLE questions
+    DROP COLUMN IF EXISTS response_count,
+    DROP COLUMN IF EXISTS empirical_difficulty,
+    DROP COLUMN IF EXISTS difficulty;
@@ -0,0 +1,33 @@
+-- difficulty is set by the author (1 = very easy, 5 = very hard).
+-- empirical_difficulty is the proportion of recorded answers that were
+-- correct, recalculated from attempt_answers after every submission.
+ALTER TABLE questions
+    ADD COLUMN difficulty INTEGER CHECK (difficulty BETWEEN 1 AND 5),
+    ADD COLUMN empirical_difficulty DOUBLE PRECISION CHECK (empirical_difficulty BETWEEN 0 AND 1),
+    ADD COLUMN response_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE quizzes
+    ADD COLUMN difficulty INTEGER CHECK (difficulty BETWEEN 1 AND 5),
+    ADD COLUMN empirical_difficulty DOUBLE PRECISION CHECK (empirical_difficulty BETWEEN 0 AND 1),
+    ADD COLUMN response_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE questions q
+SET empirical_difficulty = s.p_value,
+    response_count = s.responses
+FROM (
+    SELECT question_id, AVG(is_correct::int)::float8 AS p_value, COUNT(*)::int AS responses
+    FROM attempt_answers
+    GROUP BY question_id
+) s
+WHERE q.id = s.question_id;
+
+UPDATE quizzes z
+SET empirical_difficulty = s.p_value,
+    response_count = s.responses
+FROM (
+    SELECT q.quiz_id, AVG(aa.is_correct::int)::float8 AS p_value, COUNT(*)::int AS responses
+    FROM attempt_answers aa
+    JOIN questions q ON q.id = aa.question_id
+    GROUP BY q.quiz_id
+) s
+WHERE z.id = s.quiz_id;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS quiz_attempts_user_idx;
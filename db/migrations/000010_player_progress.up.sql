@@ -0,0 +1 @@
+CREATE INDEX quiz_attempts_user_idx ON quiz_attempts (user_name, created_at);
@@ -0,0 +1,9 @@
+ALTER TABLE quiz_attempts
+    DROP COLUMN IF EXISTS ability_se,
+    DROP COLUMN IF EXISTS ability,
+    DROP COLUMN IF EXISTS mode;
+
+ALTER TABLE questions
+    DROP COLUMN IF EXISTS irt_calibrated_at,
+    DROP COLUMN IF EXISTS irt_discrimination,
+    DROP COLUMN IF EXISTS irt_difficulty;
@@ -0,0 +1,14 @@
+-- Item response theory parameters, filled in by cmd/calibrate. Questions
+-- without them fall back to defaults derived from empirical_difficulty.
+ALTER TABLE questions
+    ADD COLUMN irt_difficulty DOUBLE PRECISION,
+    ADD COLUMN irt_discrimination DOUBLE PRECISION CHECK (irt_discrimination > 0),
+    ADD COLUMN irt_calibrated_at TIMESTAMP;
+
+-- Adaptive attempts pick questions one at a time and report an ability
+-- estimate (in standard deviations from the average player) with its
+-- standard error.
+ALTER TABLE quiz_attempts
+    ADD COLUMN mode VARCHAR(20) NOT NULL DEFAULT 'standard' CHECK (mode IN ('standard', 'adaptive')),
+    ADD COLUMN ability DOUBLE PRECISION,
+    ADD COLUMN ability_se DOUBLE PRECISION;
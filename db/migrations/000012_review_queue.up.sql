@@ -0,0 +1,16 @@
+-- Spaced repetition queue of questions a player got wrong, scheduled with
+-- the SM-2 algorithm.
+CREATE TABLE review_items (
+    user_name VARCHAR(100) NOT NULL,
+    question_id VARCHAR(36) NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    ease_factor DOUBLE PRECISION NOT NULL DEFAULT 2.5 CHECK (ease_factor >= 1.3),
+    interval_days INTEGER NOT NULL DEFAULT 0 CHECK (interval_days >= 0),
+    repetitions INTEGER NOT NULL DEFAULT 0 CHECK (repetitions >= 0),
+    lapses INTEGER NOT NULL DEFAULT 0 CHECK (lapses >= 0),
+    due_at TIMESTAMP NOT NULL DEFAULT now(),
+    last_reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (user_name, question_id)
+);
+
+CREATE INDEX review_items_due_idx ON review_items (user_name, due_at);
@@ -0,0 +1,11 @@
+DELETE FROM quiz_attempts WHERE mode IN ('practice', 'flashcard');
+
+ALTER TABLE quiz_attempts
+    DROP COLUMN IF EXISTS official,
+    DROP CONSTRAINT quiz_attempts_mode_check;
+
+UPDATE quiz_attempts SET mode = 'standard' WHERE mode = 'exam';
+
+ALTER TABLE quiz_attempts
+    ALTER COLUMN mode SET DEFAULT 'standard',
+    ADD CONSTRAINT quiz_attempts_mode_check CHECK (mode IN ('standard', 'adaptive'));
@@ -0,0 +1,12 @@
+-- Attempts are taken as an exam, as unofficial practice, as self-graded
+-- flashcards or adaptively. Only exam and adaptive attempts are official:
+-- practice and flashcard attempts stay out of leaderboards, statistics and
+-- attempt limits, but remain in the player's own history.
+ALTER TABLE quiz_attempts DROP CONSTRAINT quiz_attempts_mode_check;
+
+UPDATE quiz_attempts SET mode = 'exam' WHERE mode = 'standard';
+
+ALTER TABLE quiz_attempts
+    ALTER COLUMN mode SET DEFAULT 'exam',
+    ADD CONSTRAINT quiz_attempts_mode_check CHECK (mode IN ('exam', 'practice', 'flashcard', 'adaptive')),
+    ADD COLUMN official BOOLEAN GENERATED ALWAYS AS (mode IN ('exam', 'adaptive')) STORED;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS collection_prerequisites;
+DROP TABLE IF EXISTS collection_quizzes;
+DROP TABLE IF EXISTS collections;
@@ -0,0 +1,32 @@
+-- Learning paths: ordered sequences of quizzes where later quizzes unlock
+-- once their prerequisites are passed.
+CREATE TABLE collections (
+    id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid()::varchar(36),
+    title VARCHAR(255) NOT NULL,
+    slug VARCHAR(260) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE TABLE collection_quizzes (
+    collection_id VARCHAR(36) NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    quiz_id VARCHAR(36) NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL CHECK (position > 0),
+    PRIMARY KEY (collection_id, quiz_id)
+);
+
+CREATE INDEX collection_quizzes_quiz_idx ON collection_quizzes (quiz_id);
+
+-- A quiz is unlocked once every prerequisite is met: the required quiz must
+-- have an official attempt scoring at least min_percent, or passing its own
+-- pass mark when min_percent is NULL.
+CREATE TABLE collection_prerequisites (
+    collection_id VARCHAR(36) NOT NULL,
+    quiz_id VARCHAR(36) NOT NULL,
+    requires_quiz_id VARCHAR(36) NOT NULL,
+    min_percent DOUBLE PRECISION CHECK (min_percent BETWEEN 0 AND 100),
+    PRIMARY KEY (collection_id, quiz_id, requires_quiz_id),
+    FOREIGN KEY (collection_id, quiz_id) REFERENCES collection_quizzes (collection_id, quiz_id) ON DELETE CASCADE,
+    FOREIGN KEY (collection_id, requires_quiz_id) REFERENCES collection_quizzes (collection_id, quiz_id) ON DELETE CASCADE,
+    CHECK (quiz_id <> requires_quiz_id)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_quiz_external_id_key;
+ALTER TABLE questions DROP COLUMN IF EXISTS external_id;
+ALTER TABLE quizzes DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,6 @@
+-- Stable identifiers for content loaded from quiz files, so the seeder can
+-- update quizzes and questions in place instead of inserting duplicates.
+ALTER TABLE quizzes ADD COLUMN slug VARCHAR(260) UNIQUE;
+
+ALTER TABLE questions ADD COLUMN external_id VARCHAR(100);
+ALTER TABLE questions ADD CONSTRAINT questions_quiz_external_id_key UNIQUE (quiz_id, external_id);
@@ -0,0 +1,11 @@
+-- Spreadsheet uploads waiting for the author to confirm them. Rows hold the
+-- validated questions as JSON, so committing writes exactly what was
+-- previewed.
+CREATE TABLE question_imports (
+    id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid()::varchar(36),
+    quiz_id VARCHAR(36) NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    rows JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    committed_at TIMESTAMP
+);
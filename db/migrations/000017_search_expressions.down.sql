@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS questions_search_idx;
+DROP INDEX IF EXISTS quizzes_search_idx;
+DROP FUNCTION IF EXISTS question_search_vector(TEXT);
+DROP FUNCTION IF EXISTS quiz_search_vector(TEXT, TEXT);
+
+ALTER TABLE quizzes ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+) STORED;
+
+CREATE INDEX quizzes_search_idx ON quizzes USING GIN (search_vector);
+
+ALTER TABLE questions ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('english', coalesce(question_text, ''))
+) STORED;
+
+CREATE INDEX questions_search_idx ON questions USING GIN (search_vector);
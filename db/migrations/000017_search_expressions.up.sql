@@ -0,0 +1,24 @@
+-- Search vectors are computed by functions and indexed as expressions rather
+-- than stored in generated columns, so SELECT * on quizzes and questions no
+-- longer returns them.
+DROP INDEX IF EXISTS quizzes_search_idx;
+ALTER TABLE quizzes DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS questions_search_idx;
+ALTER TABLE questions DROP COLUMN IF EXISTS search_vector;
+
+CREATE FUNCTION quiz_search_vector(title TEXT, description TEXT) RETURNS TSVECTOR
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+           setweight(to_tsvector('english', coalesce(description, '')), 'B')
+$$;
+
+-- Only the question text is indexed; options, answers, explanations and
+-- hints stay out of search so results can't give answers away.
+CREATE FUNCTION question_search_vector(question_text TEXT) RETURNS TSVECTOR
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT to_tsvector('english', coalesce(question_text, ''))
+$$;
+
+CREATE INDEX quizzes_search_idx ON quizzes USING GIN (quiz_search_vector(title, description));
+CREATE INDEX questions_search_idx ON questions USING GIN (question_search_vector(question_text));
@@ -0,0 +1,11 @@
+-- Files shown with a question, such as the images of imported QTI items.
+-- They are small and few, so they are kept in the database with their
+-- question rather than in separate storage.
+CREATE TABLE question_media_files (
+    question_id VARCHAR(36) NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    data BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (question_id, name)
+);
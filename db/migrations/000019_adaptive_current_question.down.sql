@@ -0,0 +1 @@
+ALTER TABLE quiz_attempts DROP COLUMN IF EXISTS current_question_id;
@@ -0,0 +1,5 @@
+-- Adaptive attempts remember the question they are waiting on, so an answer
+-- is checked against the question that was served rather than one worked
+-- out again from calibrations that may have changed since.
+ALTER TABLE quiz_attempts
+    ADD COLUMN current_question_id VARCHAR(36) REFERENCES questions(id) ON DELETE SET NULL;
@@ -0,0 +1,11 @@
+-- Scanned answer sheets recorded as attempts, so uploading the same
+-- results file again does not record a student's sheet twice.
+CREATE TABLE paper_sheets (
+    attempt_id VARCHAR(36) PRIMARY KEY REFERENCES quiz_attempts(id) ON DELETE CASCADE,
+    quiz_id VARCHAR(36) NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    paper_code VARCHAR(16) NOT NULL,
+    user_name VARCHAR(100) NOT NULL,
+    variant VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (quiz_id, paper_code, user_name, variant)
+);
@@ -0,0 +1 @@
+ALTER TABLE quizzes DROP COLUMN IF EXISTS managed_by;
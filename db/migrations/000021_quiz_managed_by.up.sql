@@ -0,0 +1,6 @@
+-- Quizzes kept in quiz files are marked by the seeder and sync, which
+-- delete a marked quiz once its file is gone. Imported quizzes also have a
+-- slug but are not marked, so they are never deleted that way. Quizzes
+-- seeded before this migration are marked the next time they are seeded
+-- or synced.
+ALTER TABLE quizzes ADD COLUMN managed_by VARCHAR(20);
@@ -0,0 +1,7 @@
+-- name: GetItemResponses :many
+SELECT aa.attempt_id, aa.question_id, aa.answer, aa.is_correct, aa.points_awarded,
+       a.score, a.max_points
+FROM attempt_answers aa
+JOIN quiz_attempts a ON a.id = aa.attempt_id
+WHERE a.quiz_id = $1 AND a.status = 'submitted' AND a.official
+ORDER BY a.created_at, aa.attempt_id;
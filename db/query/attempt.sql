@@ -0,0 +1,74 @@
+-- name: CreateAttemptAnswers :exec
+INSERT INTO attempt_answers (attempt_id, question_id, answer, is_correct, points_awarded)
+SELECT @attempt_id :: varchar,
+       unnest(@question_ids :: varchar[]),
+       unnest(@answers :: varchar[]),
+       unnest(@correct :: boolean[]),
+       unnest(@points_awarded :: float[]);
+
+-- name: GetAttemptReview :one
+SELECT a.*, q.title AS quiz_title, q.reveal_policy, q.closes_at,
+       LOCALTIMESTAMP :: timestamp AS checked_at
+FROM quiz_attempts a
+JOIN quizzes q ON q.id = a.quiz_id
+WHERE a.id = $1;
+
+-- name: GetAttemptAnswers :many
+SELECT q.id AS question_id, q.question_text, q.option_a, q.option_b, q.option_c, q.option_d,
+       q.correct_answer, q.explanation, q.points,
+       aa.answer, aa.is_correct, aa.points_awarded
+FROM attempt_answers aa
+JOIN questions q ON q.id = aa.question_id
+WHERE aa.attempt_id = $1
+ORDER BY q.created_at;
+
+-- name: GetQuizAttemptByID :one
+SELECT * FROM quiz_attempts
+WHERE id = $1;
+
+-- name: StartQuizAttempt :one
+INSERT INTO quiz_attempts (quiz_id, user_name, score, max_points, total_questions, status, mode)
+VALUES ($1, $2, 0, 0, $3, 'in_progress', $4)
+RETURNING *;
+
+-- name: SubmitQuizAttempt :one
+UPDATE quiz_attempts
+SET score = $2,
+    max_points = $3,
+    total_questions = $4,
+    passed = $5,
+    status = 'submitted'
+WHERE id = $1 AND status = 'in_progress'
+RETURNING *;
+
+-- name: UseHint :one
+INSERT INTO attempt_hints (attempt_id, question_id, hints_used)
+VALUES (@attempt_id, @question_id, 1)
+ON CONFLICT (attempt_id, question_id)
+DO UPDATE SET hints_used = attempt_hints.hints_used + 1
+WHERE attempt_hints.hints_used < @max_hints :: int
+RETURNING hints_used;
+
+-- name: GetAttemptHints :many
+SELECT question_id, hints_used FROM attempt_hints
+WHERE attempt_id = $1;
+
+-- name: StartAdaptiveAttempt :one
+INSERT INTO quiz_attempts (quiz_id, user_name, score, max_points, total_questions, status, mode, ability, ability_se, current_question_id)
+VALUES ($1, $2, 0, 0, 0, 'in_progress', 'adaptive', 0, 1, $3)
+RETURNING *;
+
+-- name: UpdateAdaptiveAttempt :one
+-- Moves an attempt on from the question just answered to the next one, or
+-- to none once it is done. An attempt that has already moved on is left
+-- alone, so each question is answered once.
+UPDATE quiz_attempts
+SET score = @score,
+    max_points = @max_points,
+    total_questions = @total_questions,
+    ability = @ability,
+    ability_se = @ability_se,
+    current_question_id = @next_question_id
+WHERE id = @id AND status = 'in_progress'
+  AND current_question_id IS NOT DISTINCT FROM @answered_question_id
+RETURNING *;
@@ -0,0 +1,13 @@
+-- name: CreateCertificate :one
+INSERT INTO certificates (attempt_id, verification_code)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetCertificateByCode :one
+SELECT c.id, c.attempt_id, c.verification_code, c.issued_at,
+       a.quiz_id, a.user_name, a.score, a.max_points,
+       q.title AS quiz_title
+FROM certificates c
+JOIN quiz_attempts a ON a.id = c.attempt_id
+JOIN quizzes q ON q.id = a.quiz_id
+WHERE c.verification_code = $1;
@@ -0,0 +1,79 @@
+-- name: CreateCollection :one
+INSERT INTO collections (title, slug, description)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetCollectionBySlug :one
+SELECT * FROM collections
+WHERE slug = $1;
+
+-- name: DeleteCollection :exec
+DELETE FROM collections
+WHERE id = $1;
+
+-- name: ListCollections :many
+SELECT c.id, c.title, c.slug, c.description,
+       (SELECT COUNT(*) FROM collection_quizzes cq WHERE cq.collection_id = c.id) AS quiz_count
+FROM collections c
+ORDER BY c.title;
+
+-- name: AddCollectionQuiz :exec
+INSERT INTO collection_quizzes (collection_id, quiz_id, position)
+VALUES ($1, $2, $3)
+ON CONFLICT (collection_id, quiz_id) DO UPDATE
+SET position = EXCLUDED.position;
+
+-- name: RemoveCollectionQuiz :exec
+DELETE FROM collection_quizzes
+WHERE collection_id = $1 AND quiz_id = $2;
+
+-- name: ClearCollectionPrerequisites :exec
+DELETE FROM collection_prerequisites
+WHERE collection_id = $1 AND quiz_id = $2;
+
+-- name: AddCollectionPrerequisite :exec
+INSERT INTO collection_prerequisites (collection_id, quiz_id, requires_quiz_id, min_percent)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (collection_id, quiz_id, requires_quiz_id) DO UPDATE
+SET min_percent = EXCLUDED.min_percent;
+
+-- name: ListCollectionPrerequisites :many
+SELECT * FROM collection_prerequisites
+WHERE collection_id = $1;
+
+-- name: ListCollectionQuizzes :many
+SELECT cq.position, q.id AS quiz_id, q.title, q.pass_percent
+FROM collection_quizzes cq
+JOIN quizzes q ON q.id = cq.quiz_id
+WHERE cq.collection_id = $1
+ORDER BY cq.position, q.title;
+
+-- name: ListCollectionProgress :many
+-- Each quiz in the collection with the player's best official result.
+-- Practice attempts never unlock anything.
+SELECT cq.position, q.id AS quiz_id, q.title, q.pass_percent,
+       COUNT(a.id) AS attempts,
+       COALESCE(MAX(a.score / NULLIF(a.max_points, 0) * 100), 0) :: float AS best_score_percent
+FROM collection_quizzes cq
+JOIN quizzes q ON q.id = cq.quiz_id
+LEFT JOIN quiz_attempts a ON a.quiz_id = q.id AND a.user_name = $2
+    AND a.status = 'submitted' AND a.official
+WHERE cq.collection_id = $1
+GROUP BY cq.position, q.id, q.title, q.pass_percent
+ORDER BY cq.position, q.title;
+
+-- name: GetQuizPrerequisites :many
+-- Every prerequisite of a quiz across all collections it belongs to, with
+-- the player's best official result on the required quiz.
+SELECT c.slug AS collection_slug, c.title AS collection_title,
+       p.requires_quiz_id, q.title AS requires_quiz_title, q.pass_percent, p.min_percent,
+       COUNT(a.id) AS attempts,
+       COALESCE(MAX(a.score / NULLIF(a.max_points, 0) * 100), 0) :: float AS best_score_percent
+FROM collection_prerequisites p
+JOIN collections c ON c.id = p.collection_id
+JOIN quizzes q ON q.id = p.requires_quiz_id
+LEFT JOIN quiz_attempts a ON a.quiz_id = p.requires_quiz_id AND a.user_name = $2
+    AND a.status = 'submitted' AND a.official
+WHERE p.quiz_id = $1
+GROUP BY c.slug, c.title, p.requires_quiz_id, q.title, q.pass_percent, p.min_percent
+ORDER BY c.title, q.title;
@@ -0,0 +1,19 @@
+-- name: CreateQuestionImport :one
+INSERT INTO question_imports (quiz_id, file_name, rows)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ClaimQuestionImport :one
+-- Marks a previewed import as committed and returns it. Imports expire a day
+-- after they are previewed and can only be committed once.
+UPDATE question_imports
+SET committed_at = now()
+WHERE id = $1
+  AND quiz_id = $2
+  AND committed_at IS NULL
+  AND created_at > now() - INTERVAL '1 day'
+RETURNING *;
+
+-- name: DeleteStaleQuestionImports :exec
+DELETE FROM question_imports
+WHERE created_at < now() - INTERVAL '7 days';
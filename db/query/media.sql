@@ -0,0 +1,23 @@
+-- name: AddQuestionMediaFile :exec
+INSERT INTO question_media_files (question_id, name, content_type, data)
+VALUES ($1, $2, $3, $4);
+
+-- name: ClearQuestionMediaFiles :exec
+DELETE FROM question_media_files
+WHERE question_id = $1;
+
+-- name: ListQuestionMediaFiles :many
+-- Lists a question's files without their data.
+SELECT name, content_type, octet_length(data) :: int AS size, created_at
+FROM question_media_files
+WHERE question_id = $1
+ORDER BY name;
+
+-- name: GetQuestionMediaFiles :many
+SELECT * FROM question_media_files
+WHERE question_id = $1
+ORDER BY name;
+
+-- name: GetQuestionMediaFile :one
+SELECT * FROM question_media_files
+WHERE question_id = $1 AND name = $2;
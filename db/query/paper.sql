@@ -0,0 +1,11 @@
+-- name: GetPaperSheet :one
+-- The attempt a student's answer sheet for a printed paper was recorded as.
+SELECT attempt_id FROM paper_sheets
+WHERE quiz_id = @quiz_id
+  AND paper_code = @paper_code
+  AND user_name = @user_name
+  AND variant = @variant;
+
+-- name: AddPaperSheet :exec
+INSERT INTO paper_sheets (attempt_id, quiz_id, paper_code, user_name, variant)
+VALUES (@attempt_id, @quiz_id, @paper_code, @user_name, @variant);
@@ -0,0 +1,76 @@
+-- name: ListPlayerAttempts :many
+SELECT a.id, a.quiz_id, q.title AS quiz_title, a.score, a.max_points, a.passed, a.mode, a.created_at
+FROM quiz_attempts a
+JOIN quizzes q ON q.id = a.quiz_id
+WHERE a.user_name = $1 AND a.status = 'submitted'
+ORDER BY a.created_at;
+
+-- name: GetPlayerQuizProgress :many
+-- Improvement is the latest score percentage minus the first one.
+SELECT a.quiz_id, q.title AS quiz_title,
+       COUNT(*) AS attempts,
+       COALESCE(MAX(a.score / NULLIF(a.max_points, 0) * 100), 0) :: float AS best_score_percent,
+       COALESCE((array_agg(a.score / NULLIF(a.max_points, 0) * 100 ORDER BY a.created_at))[1], 0) :: float AS first_score_percent,
+       COALESCE((array_agg(a.score / NULLIF(a.max_points, 0) * 100 ORDER BY a.created_at DESC))[1], 0) :: float AS latest_score_percent,
+       COALESCE((array_agg(a.score / NULLIF(a.max_points, 0) * 100 ORDER BY a.created_at DESC))[1]
+              - (array_agg(a.score / NULLIF(a.max_points, 0) * 100 ORDER BY a.created_at))[1], 0) :: float AS improvement_percent,
+       COALESCE(bool_or(a.passed), false) :: bool AS passed,
+       MIN(a.created_at) :: timestamp AS first_attempt_at,
+       MAX(a.created_at) :: timestamp AS last_attempt_at
+FROM quiz_attempts a
+JOIN quizzes q ON q.id = a.quiz_id
+WHERE a.user_name = $1 AND a.status = 'submitted'
+GROUP BY a.quiz_id, q.title
+ORDER BY last_attempt_at DESC;
+
+-- name: GetPlayerStreaks :one
+-- A streak is a run of consecutive days with at least one submitted attempt.
+-- The current streak counts only if it reaches today or yesterday.
+WITH days AS (
+    SELECT DISTINCT created_at :: date AS day
+    FROM quiz_attempts
+    WHERE user_name = $1 AND status = 'submitted' AND created_at IS NOT NULL
+), runs AS (
+    SELECT COUNT(*) AS length, MAX(day) AS last_day
+    FROM (SELECT day, day - (ROW_NUMBER() OVER (ORDER BY day)) :: int AS grp FROM days) d
+    GROUP BY grp
+)
+SELECT COALESCE(SUM(length), 0) :: int AS active_days,
+       COALESCE(MAX(length), 0) :: int AS longest_streak_days,
+       COALESCE(MAX(length) FILTER (WHERE last_day >= LOCALTIMESTAMP :: date - 1), 0) :: int AS current_streak_days
+FROM runs;
+
+-- name: GetPlayerTagPerformance :many
+-- Answers count towards a question's own tags, or its quiz's tags when the
+-- question has none.
+WITH answers AS (
+    SELECT aa.question_id, aa.is_correct, qs.quiz_id
+    FROM attempt_answers aa
+    JOIN quiz_attempts a ON a.id = aa.attempt_id
+    JOIN questions qs ON qs.id = aa.question_id
+    WHERE a.user_name = $1 AND a.status = 'submitted'
+), tagged AS (
+    SELECT qt.tag_id, an.is_correct
+    FROM answers an
+    JOIN question_tags qt ON qt.question_id = an.question_id
+    UNION ALL
+    SELECT zt.tag_id, an.is_correct
+    FROM answers an
+    JOIN quiz_tags zt ON zt.quiz_id = an.quiz_id
+    WHERE NOT EXISTS (SELECT 1 FROM question_tags x WHERE x.question_id = an.question_id)
+)
+SELECT t.id, t.name, t.slug,
+       COUNT(*) AS answered,
+       (AVG(tagged.is_correct :: int) * 100) :: float AS correct_percent
+FROM tagged
+JOIN tags t ON t.id = tagged.tag_id
+GROUP BY t.id, t.name, t.slug
+ORDER BY correct_percent DESC, answered DESC, t.name;
+
+-- name: ListUnattemptedQuizzes :many
+SELECT q.* FROM quizzes q
+WHERE NOT EXISTS (
+    SELECT 1 FROM quiz_attempts a
+    WHERE a.quiz_id = q.id AND a.user_name = $1 AND a.status = 'submitted'
+)
+ORDER BY q.created_at DESC;
@@ -1,6 +1,6 @@
 -- name: CreateQuiz :one
-INSERT INTO quizzes (title, description)
-VALUES ($1, $2)
+INSERT INTO quizzes (title, description, wrong_answer_penalty, pass_percent, max_attempts, cooldown_seconds, opens_at, closes_at, reveal_policy, hint_penalty, difficulty)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING *;
 
 -- name: GetQuizByID :one
@@ -12,12 +12,14 @@ SELECT * FROM quizzes
 ORDER BY created_at DESC;
 
 -- name: CreateQuestion :one
-INSERT INTO questions (quiz_id, question_text, option_a, option_b, option_c, option_d, correct_answer)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO questions (quiz_id, question_text, option_a, option_b, option_c, option_d, correct_answer, points, explanation, hints, difficulty)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING *;
 
 -- name: GetQuestionsByQuizID :many
-SELECT id, quiz_id, question_text, option_a, option_b, option_c, option_d, created_at
+SELECT id, quiz_id, question_text, option_a, option_b, option_c, option_d, points,
+       COALESCE(cardinality(hints), 0) AS hint_count, created_at,
+       difficulty, empirical_difficulty, response_count
 FROM questions
 WHERE quiz_id = $1
 ORDER BY created_at;
@@ -27,19 +29,28 @@ SELECT * FROM questions
 WHERE id = $1;
 
 -- name: CreateQuizAttempt :one
-INSERT INTO quiz_attempts (quiz_id, user_name, score, total_questions)
-VALUES ($1, $2, $3, $4)
+INSERT INTO quiz_attempts (quiz_id, user_name, score, max_points, total_questions, passed, mode)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: GetQuizAttemptsByQuizID :many
 SELECT * FROM quiz_attempts
-WHERE quiz_id = $1
-ORDER BY score DESC, created_at DESC;
+WHERE quiz_id = $1 AND status = 'submitted' AND official
+ORDER BY score / NULLIF(max_points, 0) DESC, created_at DESC;
 
 -- name: UpdateQuiz :one
 UPDATE quizzes 
 SET title = $2,
-    description = $3
+    description = $3,
+    wrong_answer_penalty = $4,
+    pass_percent = $5,
+    max_attempts = $6,
+    cooldown_seconds = $7,
+    opens_at = $8,
+    closes_at = $9,
+    reveal_policy = $10,
+    hint_penalty = $11,
+    difficulty = $12
 WHERE id = $1
 RETURNING *;
 
@@ -54,7 +65,11 @@ SET question_text = $2,
     option_b = $4, 
     option_c = $5, 
     option_d = $6, 
-    correct_answer = $7
+    correct_answer = $7,
+    points = $8,
+    explanation = $9,
+    hints = $10,
+    difficulty = $11
 WHERE id = $1
 RETURNING *;
 
@@ -65,14 +80,196 @@ WHERE id = $1;
 
 -- name: ListQuizAttempts :many
 SELECT * FROM quiz_attempts
-WHERE quiz_id = $1
+WHERE quiz_id = $1 AND official
 ORDER BY created_at DESC;
 
 -- name: GetQuizStats :one
 SELECT 
     COUNT(*) as attemps_count,
-    AVG(score :: float / total_questions :: float * 100) as avg_score_percent,
+    COALESCE(AVG(score :: float / NULLIF(max_points, 0) :: float * 100), 0) :: float as avg_score_percent,
     MAX(score) as highest_score,
-    MIN(score) as lowest_score
+    MIN(score) as lowest_score,
+    COALESCE(AVG(passed :: int :: float * 100), 0) :: float as pass_rate_percent,
+    COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY score / NULLIF(max_points, 0) * 100), 0) :: float as median_score_percent,
+    COALESCE(stddev_samp(score / NULLIF(max_points, 0) * 100), 0) :: float as stddev_score_percent,
+    COALESCE(percentile_cont(0.25) WITHIN GROUP (ORDER BY score / NULLIF(max_points, 0) * 100), 0) :: float as p25_score_percent,
+    COALESCE(percentile_cont(0.75) WITHIN GROUP (ORDER BY score / NULLIF(max_points, 0) * 100), 0) :: float as p75_score_percent,
+    COALESCE(percentile_cont(0.90) WITHIN GROUP (ORDER BY score / NULLIF(max_points, 0) * 100), 0) :: float as p90_score_percent
 FROM quiz_attempts
+WHERE quiz_id = $1 AND status = 'submitted' AND official;
+
+-- name: GetQuizScoreHistogram :many
+-- Splits 0-100% into the requested number of equal buckets, including empty ones. A perfect
+-- score falls in the last bucket.
+WITH scores AS (
+    SELECT score / max_points * 100 AS pct
+    FROM quiz_attempts
+    WHERE quiz_id = @quiz_id AND status = 'submitted' AND official AND max_points > 0
+)
+SELECT b.bucket :: int AS bucket,
+       ((b.bucket - 1) * 100.0 / @buckets :: int) :: float AS lower_percent,
+       (b.bucket * 100.0 / @buckets :: int) :: float AS upper_percent,
+       COUNT(s.pct) AS attempts
+FROM generate_series(1, @buckets :: int) AS b(bucket)
+LEFT JOIN scores s
+    ON LEAST(GREATEST(width_bucket(s.pct, 0, 100, @buckets :: int), 1), @buckets :: int) = b.bucket
+GROUP BY b.bucket
+ORDER BY b.bucket;
+
+-- name: GetQuizScoreTrend :many
+-- Groups submitted attempts by period, 'day' or 'week'.
+SELECT date_trunc(@period :: text, created_at) :: timestamp AS period_start,
+       COUNT(*) AS attempts,
+       COALESCE(AVG(score / NULLIF(max_points, 0) * 100), 0) :: float AS avg_score_percent
+FROM quiz_attempts
+WHERE quiz_id = @quiz_id AND status = 'submitted' AND official AND created_at IS NOT NULL
+GROUP BY period_start
+ORDER BY period_start;
+
+-- name: GetAttemptPercentile :one
+-- Percentile rank of an attempt among submitted attempts on the same quiz:
+-- the share scoring lower plus half the share scoring the same.
+WITH mine AS (
+    SELECT quiz_id, score / NULLIF(max_points, 0) AS pct
+    FROM quiz_attempts
+    WHERE id = $1
+)
+SELECT COALESCE(
+         (COUNT(*) FILTER (WHERE a.score / NULLIF(a.max_points, 0) < m.pct)
+          + 0.5 * COUNT(*) FILTER (WHERE a.score / NULLIF(a.max_points, 0) = m.pct))
+         * 100.0 / NULLIF(COUNT(*), 0), 0) :: float AS percentile
+FROM quiz_attempts a
+JOIN mine m ON m.quiz_id = a.quiz_id
+WHERE a.status = 'submitted' AND a.official;
+
+-- name: GetAttemptEligibility :one
+SELECT q.max_attempts, q.cooldown_seconds, q.opens_at, q.closes_at,
+       COUNT(a.id) AS attempts_used,
+       MAX(a.created_at) :: timestamp AS last_attempt_at,
+       LOCALTIMESTAMP :: timestamp AS checked_at
+FROM quizzes q
+LEFT JOIN quiz_attempts a ON a.quiz_id = q.id AND a.user_name = $2 AND a.official
+WHERE q.id = $1
+GROUP BY q.id;
+
+-- name: LockAttempts :exec
+-- LockAttempts holds a player's attempts on a quiz until the transaction
+-- ends, so an eligibility check and the attempt it allows cannot interleave
+-- with another request's.
+SELECT pg_advisory_xact_lock(hashtext(@quiz_id :: varchar || '/' || @user_name :: varchar));
+
+-- name: RecalculateQuestionDifficulty :exec
+UPDATE questions q
+SET empirical_difficulty = s.p_value,
+    response_count = s.responses
+FROM (
+    SELECT aa.question_id, AVG(aa.is_correct::int)::float8 AS p_value, COUNT(*)::int AS responses
+    FROM attempt_answers aa
+    JOIN questions qs ON qs.id = aa.question_id
+    JOIN quiz_attempts a ON a.id = aa.attempt_id
+    WHERE qs.quiz_id = $1 AND a.official
+    GROUP BY aa.question_id
+) s
+WHERE q.id = s.question_id;
+
+-- name: RecalculateQuizDifficulty :exec
+UPDATE quizzes z
+SET empirical_difficulty = s.p_value,
+    response_count = s.responses
+FROM (
+    SELECT q.quiz_id, AVG(aa.is_correct::int)::float8 AS p_value, COUNT(*)::int AS responses
+    FROM attempt_answers aa
+    JOIN questions q ON q.id = aa.question_id
+    JOIN quiz_attempts a ON a.id = aa.attempt_id
+    WHERE q.quiz_id = $1 AND a.official
+    GROUP BY q.quiz_id
+) s
+WHERE z.id = s.quiz_id;
+
+-- name: ListQuestionsWithAnswers :many
+-- For authors only: includes correct answers, explanations and hints.
+SELECT * FROM questions
+WHERE quiz_id = $1
+ORDER BY created_at;
+
+-- name: UpdateQuestionCalibration :exec
+UPDATE questions
+SET irt_difficulty = $2,
+    irt_discrimination = $3,
+    irt_calibrated_at = LOCALTIMESTAMP
+WHERE id = $1;
+
+-- name: GetQuizBySlug :one
+-- Locks the quiz until the transaction ends, so it cannot change between
+-- comparing it with its file and writing it.
+SELECT * FROM quizzes
+WHERE slug = @slug :: varchar
+FOR UPDATE;
+
+-- name: ListFileManagedQuizzes :many
+-- Quizzes kept in quiz files by the seeder or sync, which may delete them
+-- once their file is gone. They are locked as GetQuizBySlug locks them.
+SELECT * FROM quizzes
+WHERE managed_by = 'files'
+ORDER BY slug
+FOR UPDATE;
+
+-- name: MarkQuizFileManaged :exec
+-- Marks a quiz as kept in a quiz file, see ListFileManagedQuizzes.
+UPDATE quizzes
+SET managed_by = 'files'
+WHERE id = @id;
+
+-- name: CountQuizAttempts :one
+-- Every attempt at a quiz, official or not and whether or not submitted.
+SELECT COUNT(*) FROM quiz_attempts
 WHERE quiz_id = $1;
+
+-- name: UpsertQuiz :one
+-- Availability windows are left alone when updating, so they can be managed
+-- separately from the quiz file.
+INSERT INTO quizzes (slug, title, description, wrong_answer_penalty, pass_percent, max_attempts, cooldown_seconds, reveal_policy, hint_penalty, difficulty)
+VALUES (@slug :: varchar, @title, @description, @wrong_answer_penalty, @pass_percent, @max_attempts, @cooldown_seconds, @reveal_policy, @hint_penalty, @difficulty)
+ON CONFLICT (slug) DO UPDATE
+SET title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    wrong_answer_penalty = EXCLUDED.wrong_answer_penalty,
+    pass_percent = EXCLUDED.pass_percent,
+    max_attempts = EXCLUDED.max_attempts,
+    cooldown_seconds = EXCLUDED.cooldown_seconds,
+    reveal_policy = EXCLUDED.reveal_policy,
+    hint_penalty = EXCLUDED.hint_penalty,
+    difficulty = EXCLUDED.difficulty
+RETURNING *;
+
+-- name: UpsertQuestion :one
+INSERT INTO questions (quiz_id, external_id, question_text, option_a, option_b, option_c, option_d, correct_answer, points, explanation, hints, difficulty)
+VALUES (@quiz_id, @external_id :: varchar, @question_text, @option_a, @option_b, @option_c, @option_d, @correct_answer, @points, @explanation, @hints, @difficulty)
+ON CONFLICT (quiz_id, external_id) DO UPDATE
+SET question_text = EXCLUDED.question_text,
+    option_a = EXCLUDED.option_a,
+    option_b = EXCLUDED.option_b,
+    option_c = EXCLUDED.option_c,
+    option_d = EXCLUDED.option_d,
+    correct_answer = EXCLUDED.correct_answer,
+    points = EXCLUDED.points,
+    explanation = EXCLUDED.explanation,
+    hints = EXCLUDED.hints,
+    difficulty = EXCLUDED.difficulty
+RETURNING *;
+
+-- name: SetQuizSlug :exec
+-- Gives a quiz created through the API a slug, so that bundles and quiz
+-- files can update it from then on.
+UPDATE quizzes
+SET slug = @slug :: varchar
+WHERE id = @id
+  AND slug IS NULL;
+
+-- name: SetQuestionExternalID :exec
+-- Gives a question created through the API an external ID, so that bundles
+-- and quiz files can update it from then on.
+UPDATE questions
+SET external_id = @external_id :: varchar
+WHERE id = @id
+  AND external_id IS NULL;
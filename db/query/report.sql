@@ -0,0 +1,14 @@
+-- name: ListGradebookQuizzes :many
+-- The quizzes with official attempts in the period, optionally only those
+-- of a collection: the columns of a gradebook.
+SELECT q.id, q.title
+FROM quizzes q
+WHERE EXISTS (
+        SELECT 1 FROM quiz_attempts a
+        WHERE a.quiz_id = q.id AND a.status = 'submitted' AND a.official
+          AND (sqlc.narg(created_from) :: timestamp IS NULL OR a.created_at >= sqlc.narg(created_from))
+          AND (sqlc.narg(created_to) :: timestamp IS NULL OR a.created_at < sqlc.narg(created_to))
+    )
+  AND (sqlc.narg(collection_id) :: varchar IS NULL
+       OR q.id IN (SELECT quiz_id FROM collection_quizzes WHERE collection_id = sqlc.narg(collection_id)))
+ORDER BY q.created_at, q.id;
@@ -0,0 +1,50 @@
+-- name: EnqueueMissedQuestions :exec
+-- Queues every question answered wrongly in an attempt for review now. A
+-- question already in the queue starts its schedule again.
+INSERT INTO review_items (user_name, question_id, due_at)
+SELECT a.user_name, aa.question_id, LOCALTIMESTAMP
+FROM attempt_answers aa
+JOIN quiz_attempts a ON a.id = aa.attempt_id
+WHERE aa.attempt_id = $1 AND NOT aa.is_correct
+ON CONFLICT (user_name, question_id) DO UPDATE
+SET repetitions = 0,
+    interval_days = 0,
+    lapses = review_items.lapses + 1,
+    due_at = LOCALTIMESTAMP;
+
+-- name: ListDueReviews :many
+SELECT r.question_id, q.quiz_id, z.title AS quiz_title, q.question_text,
+       q.option_a, q.option_b, q.option_c, q.option_d,
+       r.repetitions, r.interval_days, r.ease_factor, r.lapses, r.due_at
+FROM review_items r
+JOIN questions q ON q.id = r.question_id
+JOIN quizzes z ON z.id = q.quiz_id
+WHERE r.user_name = $1 AND r.due_at <= LOCALTIMESTAMP
+ORDER BY r.due_at, r.question_id
+LIMIT $2;
+
+-- name: CountReviews :one
+SELECT COUNT(*) FILTER (WHERE due_at <= LOCALTIMESTAMP) AS due,
+       COUNT(*) AS total,
+       MIN(due_at) FILTER (WHERE due_at > LOCALTIMESTAMP) :: timestamp AS next_due_at
+FROM review_items
+WHERE user_name = $1;
+
+-- name: GetReviewItem :one
+SELECT r.*, q.option_a, q.option_b, q.option_c, q.option_d, q.correct_answer, q.explanation,
+       z.reveal_policy, z.closes_at, LOCALTIMESTAMP :: timestamp AS checked_at
+FROM review_items r
+JOIN questions q ON q.id = r.question_id
+JOIN quizzes z ON z.id = q.quiz_id
+WHERE r.user_name = $1 AND r.question_id = $2;
+
+-- name: UpdateReviewItem :one
+UPDATE review_items
+SET ease_factor = $3,
+    interval_days = $4,
+    repetitions = $5,
+    lapses = $6,
+    due_at = LOCALTIMESTAMP + make_interval(days => $4),
+    last_reviewed_at = LOCALTIMESTAMP
+WHERE user_name = $1 AND question_id = $2
+RETURNING *;
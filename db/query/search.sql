@@ -0,0 +1,21 @@
+-- name: SearchQuizzes :many
+SELECT q.id, q.title, coalesce(q.description, '') :: text AS description,
+       ts_rank(quiz_search_vector(q.title, q.description), websearch_to_tsquery('english', @search))::float8 AS rank,
+       ts_headline('english', q.title || ' ' || coalesce(q.description, ''), websearch_to_tsquery('english', @search),
+                   'StartSel=<mark>, StopSel=</mark>, MaxFragments=2')::text AS snippet
+FROM quizzes q
+WHERE quiz_search_vector(q.title, q.description) @@ websearch_to_tsquery('english', @search)
+ORDER BY rank DESC, q.created_at DESC
+LIMIT @max_results;
+
+-- name: SearchQuestions :many
+-- Returns only the matching question text, never options or answers.
+SELECT qs.id, qs.quiz_id, z.title AS quiz_title,
+       ts_rank(question_search_vector(qs.question_text), websearch_to_tsquery('english', @search))::float8 AS rank,
+       ts_headline('english', qs.question_text, websearch_to_tsquery('english', @search),
+                   'StartSel=<mark>, StopSel=</mark>, MaxFragments=2')::text AS snippet
+FROM questions qs
+JOIN quizzes z ON z.id = qs.quiz_id
+WHERE question_search_vector(qs.question_text) @@ websearch_to_tsquery('english', @search)
+ORDER BY rank DESC, qs.created_at DESC
+LIMIT @max_results;
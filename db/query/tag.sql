@@ -0,0 +1,70 @@
+-- name: CreateTag :one
+INSERT INTO tags (name, slug, category)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetTagBySlug :one
+SELECT * FROM tags
+WHERE slug = $1;
+
+-- name: UpdateTag :one
+UPDATE tags
+SET name = $2,
+    slug = $3,
+    category = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteTag :exec
+DELETE FROM tags
+WHERE id = $1;
+
+-- name: ListTagsWithCounts :many
+SELECT t.id, t.name, t.slug, t.category,
+       (SELECT COUNT(*) FROM quiz_tags qt WHERE qt.tag_id = t.id) AS quiz_count,
+       (SELECT COUNT(*) FROM question_tags qs WHERE qs.tag_id = t.id) AS question_count
+FROM tags t
+ORDER BY t.category, t.name;
+
+-- name: AddQuizTag :exec
+INSERT INTO quiz_tags (quiz_id, tag_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveQuizTag :exec
+DELETE FROM quiz_tags
+WHERE quiz_id = $1 AND tag_id = $2;
+
+-- name: ListTagsForQuiz :many
+SELECT t.* FROM tags t
+JOIN quiz_tags qt ON qt.tag_id = t.id
+WHERE qt.quiz_id = $1
+ORDER BY t.name;
+
+-- name: AddQuestionTag :exec
+INSERT INTO question_tags (question_id, tag_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveQuestionTag :exec
+DELETE FROM question_tags
+WHERE question_id = $1 AND tag_id = $2;
+
+-- name: ListTagsForQuestion :many
+SELECT t.* FROM tags t
+JOIN question_tags qs ON qs.tag_id = t.id
+WHERE qs.question_id = $1
+ORDER BY t.name;
+
+-- name: ListQuizzesByTags :many
+SELECT q.* FROM quizzes q
+WHERE q.id IN (
+    SELECT qt.quiz_id
+    FROM quiz_tags qt
+    JOIN tags t ON t.id = qt.tag_id
+    WHERE t.slug = ANY(@slugs :: varchar[])
+    GROUP BY qt.quiz_id
+    HAVING NOT @match_all :: boolean
+        OR COUNT(DISTINCT t.id) = cardinality(@slugs :: varchar[])
+)
+ORDER BY q.created_at DESC;
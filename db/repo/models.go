@@ -8,30 +8,159 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Question struct {
-	ID            string           `json:"id"`
-	QuizID        string           `json:"quiz_id"`
-	QuestionText  string           `json:"question_text"`
-	OptionA       string           `json:"option_a"`
-	OptionB       string           `json:"option_b"`
-	OptionC       string           `json:"option_c"`
-	OptionD       string           `json:"option_d"`
-	CorrectAnswer string           `json:"correct_answer"`
-	CreatedAt     pgtype.Timestamp `json:"created_at"`
+type AttemptAnswer struct {
+	AttemptID     string  `json:"attempt_id"`
+	QuestionID    string  `json:"question_id"`
+	Answer        string  `json:"answer"`
+	IsCorrect     bool    `json:"is_correct"`
+	PointsAwarded float64 `json:"points_awarded"`
 }
 
-type Quiz struct {
+type AttemptHint struct {
+	AttemptID  string `json:"attempt_id"`
+	QuestionID string `json:"question_id"`
+	HintsUsed  int32  `json:"hints_used"`
+}
+
+type Certificate struct {
+	ID               string           `json:"id"`
+	AttemptID        string           `json:"attempt_id"`
+	VerificationCode string           `json:"verification_code"`
+	IssuedAt         pgtype.Timestamp `json:"issued_at"`
+}
+
+type Collection struct {
 	ID          string           `json:"id"`
 	Title       string           `json:"title"`
+	Slug        string           `json:"slug"`
 	Description string           `json:"description"`
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 }
 
+type CollectionPrerequisite struct {
+	CollectionID   string   `json:"collection_id"`
+	QuizID         string   `json:"quiz_id"`
+	RequiresQuizID string   `json:"requires_quiz_id"`
+	MinPercent     *float64 `json:"min_percent"`
+}
+
+type CollectionQuiz struct {
+	CollectionID string `json:"collection_id"`
+	QuizID       string `json:"quiz_id"`
+	Position     int32  `json:"position"`
+}
+
+type PaperSheet struct {
+	AttemptID string           `json:"attempt_id"`
+	QuizID    string           `json:"quiz_id"`
+	PaperCode string           `json:"paper_code"`
+	UserName  string           `json:"user_name"`
+	Variant   string           `json:"variant"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Question struct {
+	ID                  string           `json:"id"`
+	QuizID              string           `json:"quiz_id"`
+	QuestionText        string           `json:"question_text"`
+	OptionA             string           `json:"option_a"`
+	OptionB             string           `json:"option_b"`
+	OptionC             string           `json:"option_c"`
+	OptionD             string           `json:"option_d"`
+	CorrectAnswer       string           `json:"correct_answer"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	Points              float64          `json:"points"`
+	Explanation         string           `json:"explanation"`
+	Hints               []string         `json:"hints"`
+	Difficulty          *int32           `json:"difficulty"`
+	EmpiricalDifficulty *float64         `json:"empirical_difficulty"`
+	ResponseCount       int32            `json:"response_count"`
+	IrtDifficulty       *float64         `json:"irt_difficulty"`
+	IrtDiscrimination   *float64         `json:"irt_discrimination"`
+	IrtCalibratedAt     pgtype.Timestamp `json:"irt_calibrated_at"`
+	ExternalID          *string          `json:"external_id"`
+}
+
+type QuestionImport struct {
+	ID          string           `json:"id"`
+	QuizID      string           `json:"quiz_id"`
+	FileName    string           `json:"file_name"`
+	Rows        []byte           `json:"rows"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	CommittedAt pgtype.Timestamp `json:"committed_at"`
+}
+
+type QuestionMediaFile struct {
+	QuestionID  string           `json:"question_id"`
+	Name        string           `json:"name"`
+	ContentType string           `json:"content_type"`
+	Data        []byte           `json:"data"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type QuestionTag struct {
+	QuestionID string `json:"question_id"`
+	TagID      string `json:"tag_id"`
+}
+
+type Quiz struct {
+	ID                  string           `json:"id"`
+	Title               string           `json:"title"`
+	Description         string           `json:"description"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	WrongAnswerPenalty  float64          `json:"wrong_answer_penalty"`
+	PassPercent         *float64         `json:"pass_percent"`
+	MaxAttempts         *int32           `json:"max_attempts"`
+	CooldownSeconds     int32            `json:"cooldown_seconds"`
+	OpensAt             pgtype.Timestamp `json:"opens_at"`
+	ClosesAt            pgtype.Timestamp `json:"closes_at"`
+	RevealPolicy        string           `json:"reveal_policy"`
+	HintPenalty         float64          `json:"hint_penalty"`
+	Difficulty          *int32           `json:"difficulty"`
+	EmpiricalDifficulty *float64         `json:"empirical_difficulty"`
+	ResponseCount       int32            `json:"response_count"`
+	Slug                *string          `json:"slug"`
+	ManagedBy           *string          `json:"managed_by"`
+}
+
 type QuizAttempt struct {
-	ID             string           `json:"id"`
-	QuizID         string           `json:"quiz_id"`
+	ID                string           `json:"id"`
+	QuizID            string           `json:"quiz_id"`
+	UserName          string           `json:"user_name"`
+	Score             float64          `json:"score"`
+	TotalQuestions    int32            `json:"total_questions"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+	MaxPoints         float64          `json:"max_points"`
+	Passed            bool             `json:"passed"`
+	Status            string           `json:"status"`
+	Mode              string           `json:"mode"`
+	Ability           *float64         `json:"ability"`
+	AbilitySe         *float64         `json:"ability_se"`
+	Official          bool             `json:"official"`
+	CurrentQuestionID *string          `json:"current_question_id"`
+}
+
+type QuizTag struct {
+	QuizID string `json:"quiz_id"`
+	TagID  string `json:"tag_id"`
+}
+
+type ReviewItem struct {
 	UserName       string           `json:"user_name"`
-	Score          int32            `json:"score"`
-	TotalQuestions int32            `json:"total_questions"`
+	QuestionID     string           `json:"question_id"`
+	EaseFactor     float64          `json:"ease_factor"`
+	IntervalDays   int32            `json:"interval_days"`
+	Repetitions    int32            `json:"repetitions"`
+	Lapses         int32            `json:"lapses"`
+	DueAt          pgtype.Timestamp `json:"due_at"`
+	LastReviewedAt pgtype.Timestamp `json:"last_reviewed_at"`
 	CreatedAt      pgtype.Timestamp `json:"created_at"`
 }
+
+type Tag struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	Slug      string           `json:"slug"`
+	Category  string           `json:"category"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
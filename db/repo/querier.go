@@ -9,20 +9,139 @@ import (
 )
 
 type Querier interface {
+	AddCollectionPrerequisite(ctx context.Context, arg AddCollectionPrerequisiteParams) error
+	AddCollectionQuiz(ctx context.Context, arg AddCollectionQuizParams) error
+	AddPaperSheet(ctx context.Context, arg AddPaperSheetParams) error
+	AddQuestionMediaFile(ctx context.Context, arg AddQuestionMediaFileParams) error
+	AddQuestionTag(ctx context.Context, arg AddQuestionTagParams) error
+	AddQuizTag(ctx context.Context, arg AddQuizTagParams) error
+	// Marks a previewed import as committed and returns it. Imports expire a day
+	// after they are previewed and can only be committed once.
+	ClaimQuestionImport(ctx context.Context, arg ClaimQuestionImportParams) (QuestionImport, error)
+	ClearCollectionPrerequisites(ctx context.Context, arg ClearCollectionPrerequisitesParams) error
+	ClearQuestionMediaFiles(ctx context.Context, questionID string) error
+	// Every attempt at a quiz, official or not and whether or not submitted.
+	CountQuizAttempts(ctx context.Context, quizID string) (int64, error)
+	CountReviews(ctx context.Context, userName string) (CountReviewsRow, error)
+	CreateAttemptAnswers(ctx context.Context, arg CreateAttemptAnswersParams) error
+	CreateCertificate(ctx context.Context, arg CreateCertificateParams) (Certificate, error)
+	CreateCollection(ctx context.Context, arg CreateCollectionParams) (Collection, error)
 	CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error)
+	CreateQuestionImport(ctx context.Context, arg CreateQuestionImportParams) (QuestionImport, error)
 	CreateQuiz(ctx context.Context, arg CreateQuizParams) (Quiz, error)
 	CreateQuizAttempt(ctx context.Context, arg CreateQuizAttemptParams) (QuizAttempt, error)
+	CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error)
+	DeleteCollection(ctx context.Context, id string) error
 	DeleteQuestion(ctx context.Context, id string) error
 	DeleteQuiz(ctx context.Context, id string) error
+	DeleteStaleQuestionImports(ctx context.Context) error
+	DeleteTag(ctx context.Context, id string) error
+	// Queues every question answered wrongly in an attempt for review now. A
+	// question already in the queue starts its schedule again.
+	EnqueueMissedQuestions(ctx context.Context, attemptID string) error
+	GetAttemptAnswers(ctx context.Context, attemptID string) ([]GetAttemptAnswersRow, error)
+	GetAttemptEligibility(ctx context.Context, arg GetAttemptEligibilityParams) (GetAttemptEligibilityRow, error)
+	GetAttemptHints(ctx context.Context, attemptID string) ([]GetAttemptHintsRow, error)
+	// Percentile rank of an attempt among submitted attempts on the same quiz:
+	// the share scoring lower plus half the share scoring the same.
+	GetAttemptPercentile(ctx context.Context, id string) (float64, error)
+	GetAttemptReview(ctx context.Context, id string) (GetAttemptReviewRow, error)
+	GetCertificateByCode(ctx context.Context, verificationCode string) (GetCertificateByCodeRow, error)
+	GetCollectionBySlug(ctx context.Context, slug string) (Collection, error)
+	GetItemResponses(ctx context.Context, quizID string) ([]GetItemResponsesRow, error)
+	// The attempt a student's answer sheet for a printed paper was recorded as.
+	GetPaperSheet(ctx context.Context, arg GetPaperSheetParams) (string, error)
+	// Improvement is the latest score percentage minus the first one.
+	GetPlayerQuizProgress(ctx context.Context, userName string) ([]GetPlayerQuizProgressRow, error)
+	// A streak is a run of consecutive days with at least one submitted attempt.
+	// The current streak counts only if it reaches today or yesterday.
+	GetPlayerStreaks(ctx context.Context, userName string) (GetPlayerStreaksRow, error)
+	// Answers count towards a question's own tags, or its quiz's tags when the
+	// question has none.
+	GetPlayerTagPerformance(ctx context.Context, userName string) ([]GetPlayerTagPerformanceRow, error)
 	GetQuestionByID(ctx context.Context, id string) (Question, error)
+	GetQuestionMediaFile(ctx context.Context, arg GetQuestionMediaFileParams) (QuestionMediaFile, error)
+	GetQuestionMediaFiles(ctx context.Context, questionID string) ([]QuestionMediaFile, error)
 	GetQuestionsByQuizID(ctx context.Context, quizID string) ([]GetQuestionsByQuizIDRow, error)
+	GetQuizAttemptByID(ctx context.Context, id string) (QuizAttempt, error)
 	GetQuizAttemptsByQuizID(ctx context.Context, quizID string) ([]QuizAttempt, error)
 	GetQuizByID(ctx context.Context, id string) (Quiz, error)
+	// Locks the quiz until the transaction ends, so it cannot change between
+	// comparing it with its file and writing it.
+	GetQuizBySlug(ctx context.Context, slug string) (Quiz, error)
+	// Every prerequisite of a quiz across all collections it belongs to, with
+	// the player's best official result on the required quiz.
+	GetQuizPrerequisites(ctx context.Context, arg GetQuizPrerequisitesParams) ([]GetQuizPrerequisitesRow, error)
+	// Splits 0-100% into the requested number of equal buckets, including empty ones. A perfect
+	// score falls in the last bucket.
+	GetQuizScoreHistogram(ctx context.Context, arg GetQuizScoreHistogramParams) ([]GetQuizScoreHistogramRow, error)
+	// Groups submitted attempts by period, 'day' or 'week'.
+	GetQuizScoreTrend(ctx context.Context, arg GetQuizScoreTrendParams) ([]GetQuizScoreTrendRow, error)
 	GetQuizStats(ctx context.Context, quizID string) (GetQuizStatsRow, error)
+	GetReviewItem(ctx context.Context, arg GetReviewItemParams) (GetReviewItemRow, error)
+	GetTagBySlug(ctx context.Context, slug string) (Tag, error)
+	ListCollectionPrerequisites(ctx context.Context, collectionID string) ([]CollectionPrerequisite, error)
+	// Each quiz in the collection with the player's best official result.
+	// Practice attempts never unlock anything.
+	ListCollectionProgress(ctx context.Context, arg ListCollectionProgressParams) ([]ListCollectionProgressRow, error)
+	ListCollectionQuizzes(ctx context.Context, collectionID string) ([]ListCollectionQuizzesRow, error)
+	ListCollections(ctx context.Context) ([]ListCollectionsRow, error)
+	ListDueReviews(ctx context.Context, arg ListDueReviewsParams) ([]ListDueReviewsRow, error)
+	// Quizzes kept in quiz files by the seeder or sync, which may delete them
+	// once their file is gone. They are locked as GetQuizBySlug locks them.
+	ListFileManagedQuizzes(ctx context.Context) ([]Quiz, error)
+	// The quizzes with official attempts in the period, optionally only those
+	// of a collection: the columns of a gradebook.
+	ListGradebookQuizzes(ctx context.Context, arg ListGradebookQuizzesParams) ([]ListGradebookQuizzesRow, error)
+	ListPlayerAttempts(ctx context.Context, userName string) ([]ListPlayerAttemptsRow, error)
+	// Lists a question's files without their data.
+	ListQuestionMediaFiles(ctx context.Context, questionID string) ([]ListQuestionMediaFilesRow, error)
+	// For authors only: includes correct answers, explanations and hints.
+	ListQuestionsWithAnswers(ctx context.Context, quizID string) ([]Question, error)
 	ListQuizAttempts(ctx context.Context, quizID string) ([]QuizAttempt, error)
 	ListQuizzes(ctx context.Context) ([]Quiz, error)
+	ListQuizzesByTags(ctx context.Context, arg ListQuizzesByTagsParams) ([]Quiz, error)
+	ListTagsForQuestion(ctx context.Context, questionID string) ([]Tag, error)
+	ListTagsForQuiz(ctx context.Context, quizID string) ([]Tag, error)
+	ListTagsWithCounts(ctx context.Context) ([]ListTagsWithCountsRow, error)
+	ListUnattemptedQuizzes(ctx context.Context, userName string) ([]Quiz, error)
+	// LockAttempts holds a player's attempts on a quiz until the transaction
+	// ends, so an eligibility check and the attempt it allows cannot interleave
+	// with another request's.
+	LockAttempts(ctx context.Context, arg LockAttemptsParams) error
+	// Marks a quiz as kept in a quiz file, see ListFileManagedQuizzes.
+	MarkQuizFileManaged(ctx context.Context, id string) error
+	RecalculateQuestionDifficulty(ctx context.Context, quizID string) error
+	RecalculateQuizDifficulty(ctx context.Context, quizID string) error
+	RemoveCollectionQuiz(ctx context.Context, arg RemoveCollectionQuizParams) error
+	RemoveQuestionTag(ctx context.Context, arg RemoveQuestionTagParams) error
+	RemoveQuizTag(ctx context.Context, arg RemoveQuizTagParams) error
+	// Returns only the matching question text, never options or answers.
+	SearchQuestions(ctx context.Context, arg SearchQuestionsParams) ([]SearchQuestionsRow, error)
+	SearchQuizzes(ctx context.Context, arg SearchQuizzesParams) ([]SearchQuizzesRow, error)
+	// Gives a question created through the API an external ID, so that bundles
+	// and quiz files can update it from then on.
+	SetQuestionExternalID(ctx context.Context, arg SetQuestionExternalIDParams) error
+	// Gives a quiz created through the API a slug, so that bundles and quiz
+	// files can update it from then on.
+	SetQuizSlug(ctx context.Context, arg SetQuizSlugParams) error
+	StartAdaptiveAttempt(ctx context.Context, arg StartAdaptiveAttemptParams) (QuizAttempt, error)
+	StartQuizAttempt(ctx context.Context, arg StartQuizAttemptParams) (QuizAttempt, error)
+	SubmitQuizAttempt(ctx context.Context, arg SubmitQuizAttemptParams) (QuizAttempt, error)
+	// Moves an attempt on from the question just answered to the next one, or
+	// to none once it is done. An attempt that has already moved on is left
+	// alone, so each question is answered once.
+	UpdateAdaptiveAttempt(ctx context.Context, arg UpdateAdaptiveAttemptParams) (QuizAttempt, error)
 	UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error)
+	UpdateQuestionCalibration(ctx context.Context, arg UpdateQuestionCalibrationParams) error
 	UpdateQuiz(ctx context.Context, arg UpdateQuizParams) (Quiz, error)
+	UpdateReviewItem(ctx context.Context, arg UpdateReviewItemParams) (ReviewItem, error)
+	UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error)
+	UpsertQuestion(ctx context.Context, arg UpsertQuestionParams) (Question, error)
+	// Availability windows are left alone when updating, so they can be managed
+	// separately from the quiz file.
+	UpsertQuiz(ctx context.Context, arg UpsertQuizParams) (Quiz, error)
+	UseHint(ctx context.Context, arg UseHintParams) (int32, error)
 }
 
 var _ Querier = (*Queries)(nil)
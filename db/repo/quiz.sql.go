@@ -11,20 +11,37 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countQuizAttempts = `-- name: CountQuizAttempts :one
+SELECT COUNT(*) FROM quiz_attempts
+WHERE quiz_id = $1
+`
+
+// Every attempt at a quiz, official or not and whether or not submitted.
+func (q *Queries) CountQuizAttempts(ctx context.Context, quizID string) (int64, error) {
+	row := q.db.QueryRow(ctx, countQuizAttempts, quizID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createQuestion = `-- name: CreateQuestion :one
-INSERT INTO questions (quiz_id, question_text, option_a, option_b, option_c, option_d, correct_answer)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, quiz_id, question_text, option_a, option_b, option_c, option_d, correct_answer, created_at
+INSERT INTO questions (quiz_id, question_text, option_a, option_b, option_c, option_d, correct_answer, points, explanation, hints, difficulty)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, quiz_id, question_text, option_a, option_b, option_c, option_d, correct_answer, created_at, points, explanation, hints, difficulty, empirical_difficulty, response_count, irt_difficulty, irt_discrimination, irt_calibrated_at, external_id
 `
 
 type CreateQuestionParams struct {
-	QuizID        string `json:"quiz_id"`
-	QuestionText  string `json:"question_text"`
-	OptionA       string `json:"option_a"`
-	OptionB       string `json:"option_b"`
-	OptionC       string `json:"option_c"`
-	OptionD       string `json:"option_d"`
-	CorrectAnswer string `json:"correct_answer"`
+	QuizID        string   `json:"quiz_id"`
+	QuestionText  string   `json:"question_text"`
+	OptionA       string   `json:"option_a"`
+	OptionB       string   `json:"option_b"`
+	OptionC       string   `json:"option_c"`
+	OptionD       string   `json:"option_d"`
+	CorrectAnswer string   `json:"correct_answer"`
+	Points        float64  `json:"points"`
+	Explanation   string   `json:"explanation"`
+	Hints         []string `json:"hints"`
+	Difficulty    *int32   `json:"difficulty"`
 }
 
 func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error) {
@@ -36,6 +53,10 @@ func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams)
 		arg.OptionC,
 		arg.OptionD,
 		arg.CorrectAnswer,
+		arg.Points,
+		arg.Explanation,
+		arg.Hints,
+		arg.Difficulty,
 	)
 	var i Question
 	err := row.Scan(
@@ -48,44 +69,91 @@ func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams)
 		&i.OptionD,
 		&i.CorrectAnswer,
 		&i.CreatedAt,
+		&i.Points,
+		&i.Explanation,
+		&i.Hints,
+		&i.Difficulty,
+		&i.EmpiricalDifficulty,
+		&i.ResponseCount,
+		&i.IrtDifficulty,
+		&i.IrtDiscrimination,
+		&i.IrtCalibratedAt,
+		&i.ExternalID,
 	)
 	return i, err
 }
 
 const createQuiz = `-- name: CreateQuiz :one
-INSERT INTO quizzes (title, description)
-VALUES ($1, $2)
-RETURNING id, title, description, created_at
+INSERT INTO quizzes (title, description, wrong_answer_penalty, pass_percent, max_attempts, cooldown_seconds, opens_at, closes_at, reveal_policy, hint_penalty, difficulty)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, title, description, created_at, wrong_answer_penalty, pass_percent, max_attempts, cooldown_seconds, opens_at, closes_at, reveal_policy, hint_penalty, difficulty, empirical_difficulty, response_count, slug, managed_by
 `
 
 type CreateQuizParams struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title              string           `json:"title"`
+	Description        string           `json:"description"`
+	WrongAnswerPenalty float64          `json:"wrong_answer_penalty"`
+	PassPercent        *float64         `json:"pass_percent"`
+	MaxAttempts        *int32           `json:"max_attempts"`
+	CooldownSeconds    int32            `json:"cooldown_seconds"`
+	OpensAt            pgtype.Timestamp `json:"opens_at"`
+	ClosesAt           pgtype.Timestamp `json:"closes_at"`
+	RevealPolicy       string           `json:"reveal_policy"`
+	HintPenalty        float64          `json:"hint_penalty"`
+	Difficulty         *int32           `json:"difficulty"`
 }
 
 func (q *Queries) CreateQuiz(ctx context.Context, arg CreateQuizParams) (Quiz, error) {
-	row := q.db.QueryRow(ctx, createQuiz, arg.Title, arg.Description)
+	row := q.db.QueryRow(ctx, createQuiz,
+		arg.Title,
+		arg.Description,
+		arg.WrongAnswerPenalty,
+		arg.PassPercent,
+		arg.MaxAttempts,
+		arg.CooldownSeconds,
+		arg.OpensAt,
+		arg.ClosesAt,
+		arg.RevealPolicy,
+		arg.HintPenalty,
+		arg.Difficulty,
+	)
 	var i Quiz
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
 		&i.Description,
 		&i.CreatedAt,
+		&i.WrongAnswerPenalty,
+		&i.PassPercent,
+		&i.MaxAttempts,
+		&i.CooldownSeconds,
+		&i.OpensAt,
+		&i.ClosesAt,
+		&i.RevealPolicy,
+		&i.HintPenalty,
+		&i.Difficulty,
+		&i.EmpiricalDifficulty,
+		&i.ResponseCount,
+		&i.Slug,
+		&i.ManagedBy,
 	)
 	return i, err
 }
 
 const createQuizAttempt = `-- name: CreateQuizAttempt :one
-INSERT INTO quiz_attempts (quiz_id, user_name, score, total_questions)
-VALUES ($1, $2, $3, $4)
-RETURNING id, quiz_id, user_name, score, total_questions, created_at
+INSERT INTO quiz_attempts (quiz_id, user_name, score, max_points, total_questions, passed, mode)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, quiz_id, user_name, score, total_questions, created_at, max_points, passed, status, mode, ability, ability_se, official, current_question_id
 `
 
 type CreateQuizAttemptParams struct {
-	QuizID         string `json:"quiz_id"`
-	UserName       string `json:"user_name"`
-	Score          int32  `json:"score"`
-	TotalQuestions int32  `json:"total_questions"`
+	QuizID         string  `json:"quiz_id"`
+	UserName       string  `json:"user_name"`
+	Score          float64 `json:"score"`
+	MaxPoints      float64 `json:"max_points"`
+	TotalQuestions int32   `json:"total_questions"`
+	Passed         bool    `json:"passed"`
+	Mode           string  `json:"mode"`
 }
 
 func (q *Queries) CreateQuizAttempt(ctx context.Context, arg CreateQuizAttemptParams) (QuizAttempt, error) {
@@ -93,7 +161,10 @@ func (q *Queries) CreateQuizAttempt(ctx context.Context, arg CreateQuizAttemptPa
 		arg.QuizID,
 		arg.UserName,
 		arg.Score,
+		arg.MaxPoints,
 		arg.TotalQuestions,
+		arg.Passed,
+		arg.Mode,
 	)
 	var i QuizAttempt
 	err := row.Scan(
@@ -103,6 +174,14 @@ func (q *Queries) CreateQuizAttempt(ctx context.Context, arg CreateQuizAttemptPa
 		&i.Score,
 		&i.TotalQuestions,
 		&i.CreatedAt,
+		&i.MaxPoints,
+		&i.Passed,
+		&i.Status,
+		&i.Mode,
+		&i.Ability,
+		&i.AbilitySe,
+		&i.Official,
+		&i.CurrentQuestionID,
 	)
 	return i, err
 }
@@ -127,8 +206,73 @@ func (q *Queries) DeleteQuiz(ctx context.Context, id string) error {
 	return err
 }
 
+const getAttemptEligibility = `-- name: GetAttemptEligibility :one
+SELECT q.max_attempts, q.cooldown_seconds, q.opens_at, q.closes_at,
+       COUNT(a.id) AS attempts_used,
+       MAX(a.created_at) :: timestamp AS last_attempt_at,
+       LOCALTIMESTAMP :: timestamp AS checked_at
+FROM quizzes q
+LEFT JOIN quiz_attempts a ON a.quiz_id = q.id AND a.user_name = $2 AND a.official
+WHERE q.id = $1
+GROUP BY q.id
+`
+
+type GetAttemptEligibilityParams struct {
+	ID       string `json:"id"`
+	UserName string `json:"user_name"`
+}
+
+type GetAttemptEligibilityRow struct {
+	MaxAttempts     *int32           `json:"max_attempts"`
+	CooldownSeconds int32            `json:"cooldown_seconds"`
+	OpensAt         pgtype.Timestamp `json:"opens_at"`
+	ClosesAt        pgtype.Timestamp `json:"closes_at"`
+	AttemptsUsed    int64            `json:"attempts_used"`
+	LastAttemptAt   pgtype.Timestamp `json:"last_attempt_at"`
+	CheckedAt       pgtype.Timestamp `json:"checked_at"`
+}
+
+func (q *Queries) GetAttemptEligibility(ctx context.Context, arg GetAttemptEligibilityParams) (GetAttemptEligibilityRow, error) {
+	row := q.db.QueryRow(ctx, getAttemptEligibility, arg.ID, arg.UserName)
+	var i GetAttemptEligibilityRow
+	err := row.Scan(
+		&i.MaxAttempts,
+		&i.CooldownSeconds,
+		&i.OpensAt,
+		&i.ClosesAt,
+		&i.AttemptsUsed,
+		&i.LastAttemptAt,
+		&i.CheckedAt,
+	)
+	return i, err
+}
+
+const getAttemptPercentile = `-- name: GetAttemptPercentile :one
+WITH mine AS (
+    SELECT quiz_id, score / NULLIF(max_points, 0) AS pct
+    FROM quiz_attempts
+    WHERE id = $1
+)
+SELECT COALESCE(
+         (COUNT(*) FILTER (WHERE a.score / NULLIF(a.max_points, 0) < m.pct)
+          + 0.5 * COUNT(*) FILTER (WHERE a.score / NULLIF(a.max_points, 0) = m.pct))
+         * 100.0 / NULLIF(COUNT(*), 0), 0) :: float AS percentile
+FROM quiz_attempts a
+JOIN mine m ON m.quiz_id = a.quiz_id
+WHERE a.status = 'submitted' AND a.official
+`
+
+// Percentile rank of an attempt among submitted attempts on the same quiz:
+// the share scoring lower plus half the share scoring the same.
+func (q *Queries) GetAttemptPercentile(ctx context.Context, id string) (float64, error) {
+	row := q.db.QueryRow(ctx, getAttemptPercentile, id)
+	var percentile float64
+	err := row.Scan(&percentile)
+	return percentile, err
+}
+
 const getQuestionByID = `-- name: GetQuestionByID :one
-SELECT id, quiz_id, question_text, option_a, option_b, option_c, option_d, correct_answer, created_at FROM questions
+SELECT id, quiz_id, question_text, option_a, option_b, option_c, option_d, correct_answer, created_at, points, explanation, hints, difficulty, empirical_difficulty, response_count, irt_difficulty, irt_discrimination, irt_calibrated_at, external_id FROM questions
 WHERE id = $1
 `
 
@@ -145,26 +289,43 @@ func (q *Queries) GetQuestionByID(ctx context.Context, id string) (Question, err
 		&i.OptionD,
 		&i.CorrectAnswer,
 		&i.CreatedAt,
+		&i.Points,
+		&i.Explanation,
+		&i.Hints,
+		&i.Difficulty,
+		&i.EmpiricalDifficulty,
+		&i.ResponseCount,
+		&i.IrtDifficulty,
+		&i.IrtDiscrimination,
+		&i.IrtCalibratedAt,
+		&i.ExternalID,
 	)
 	return i, err
 }
 
 const getQuestionsByQuizID = `-- name: GetQuestionsByQuizID :many
-SELECT id, quiz_id, question_text, option_a, option_b, option_c, option_d, created_at
+SELECT id, quiz_id, question_text, option_a, option_b, option_c, option_d, points,
+       COALESCE(cardinality(hints), 0) AS hint_count, created_at,
+       difficulty, empirical_difficulty, response_count
 FROM questions
 WHERE quiz_id = $1
 ORDER BY created_at
 `
 
 type GetQuestionsByQuizIDRow struct {
-	ID           string           `json:"id"`
-	QuizID       string           `json:"quiz_id"`
-	QuestionText string           `json:"question_text"`
-	OptionA      string           `json:"option_a"`
-	OptionB      string           `json:"option_b"`
-	OptionC      string           `json:"option_c"`
-	OptionD      string           `json:"option_d"`
-	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	ID                  string           `json:"id"`
+	QuizID              string           `json:"quiz_id"`
+	QuestionText        string           `json:"question_text"`
+	OptionA             string           `json:"option_a"`
+	OptionB             string           `json:"option_b"`
+	OptionC             string           `json:"option_c"`
+	OptionD             string           `json:"option_d"`
+	Points              float64          `json:"points"`
+	HintCount           int32            `json:"hint_count"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	Difficulty          *int32           `json:"difficulty"`
+	EmpiricalDifficulty *float64         `json:"empirical_difficulty"`
+	ResponseCount       int32            `json:"response_count"`
 }
 
 func (q *Queries) GetQuestionsByQuizID(ctx context.Context, quizID string) ([]GetQuestionsByQuizIDRow, error) {
@@ -184,7 +345,12 @@ func (q *Queries) GetQuestionsByQuizID(ctx context.Context, quizID string) ([]Ge
 			&i.OptionB,
 			&i.OptionC,
 			&i.OptionD,
+			&i.Points,
+			&i.HintCount,
 			&i.CreatedAt,
+			&i.Difficulty,
+			&i.EmpiricalDifficulty,
+			&i.ResponseCount,
 		); err != nil {
 			return nil, err
 		}
@@ -197,9 +363,9 @@ func (q *Queries) GetQuestionsByQuizID(ctx context.Context, quizID string) ([]Ge
 }
 
 const getQuizAttemptsByQuizID = `-- name: GetQuizAttemptsByQuizID :many
-SELECT id, quiz_id, user_name, score, total_questions, created_at FROM quiz_attempts
-WHERE quiz_id = $1
-ORDER BY score DESC, created_at DESC
+SELECT id, quiz_id, user_name, score, total_questions, created_at, max_points, passed, status, mode, ability, ability_se, official, current_question_id FROM quiz_attempts
+WHERE quiz_id = $1 AND status = 'submitted' AND official
+ORDER BY score / NULLIF(max_points, 0) DESC, created_at DESC
 `
 
 func (q *Queries) GetQuizAttemptsByQuizID(ctx context.Context, quizID string) ([]QuizAttempt, error) {
@@ -218,6 +384,14 @@ func (q *Queries) GetQuizAttemptsByQuizID(ctx context.Context, quizID string) ([
 			&i.Score,
 			&i.TotalQuestions,
 			&i.CreatedAt,
+			&i.MaxPoints,
+			&i.Passed,
+			&i.Status,
+			&i.Mode,
+			&i.Ability,
+			&i.AbilitySe,
+			&i.Official,
+			&i.CurrentQuestionID,
 		); err != nil {
 			return nil, err
 		}
@@ -230,7 +404,7 @@ func (q *Queries) GetQuizAttemptsByQuizID(ctx context.Context, quizID string) ([
 }
 
 const getQuizByID = `-- name: GetQuizByID :one
-SELECT id, title, description, created_at FROM quizzes
+SELECT id, title, description, created_at, wrong_answer_penalty, pass_percent, max_attempts, cooldown_seconds, opens_at, closes_at, reveal_policy, hint_penalty, difficulty, empirical_difficulty, response_count, slug, managed_by FROM quizzes
 WHERE id = $1
 `
 
@@ -242,25 +416,185 @@ func (q *Queries) GetQuizByID(ctx context.Context, id string) (Quiz, error) {
 		&i.Title,
 		&i.Description,
 		&i.CreatedAt,
+		&i.WrongAnswerPenalty,
+		&i.PassPercent,
+		&i.MaxAttempts,
+		&i.CooldownSeconds,
+		&i.OpensAt,
+		&i.ClosesAt,
+		&i.RevealPolicy,
+		&i.HintPenalty,
+		&i.Difficulty,
+		&i.EmpiricalDifficulty,
+		&i.ResponseCount,
+		&i.Slug,
+		&i.ManagedBy,
+	)
+	return i, err
+}
+
+const getQuizBySlug = `-- name: GetQuizBySlug :one
+SELECT id, title, description, created_at, wrong_answer_penalty, pass_percent, max_attempts, cooldown_seconds, opens_at, closes_at, reveal_policy, hint_penalty, difficulty, empirical_difficulty, response_count, slug, managed_by FROM quizzes
+WHERE slug = $1 :: varchar
+FOR UPDATE
+`
+
+// Locks the quiz until the transaction ends, so it cannot change between
+// comparing it with its file and writing it.
+func (q *Queries) GetQuizBySlug(ctx context.Context, slug string) (Quiz, error) {
+	row := q.db.QueryRow(ctx, getQuizBySlug, slug)
+	var i Quiz
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.CreatedAt,
+		&i.WrongAnswerPenalty,
+		&i.PassPercent,
+		&i.MaxAttempts,
+		&i.CooldownSeconds,
+		&i.OpensAt,
+		&i.ClosesAt,
+		&i.RevealPolicy,
+		&i.HintPenalty,
+		&i.Difficulty,
+		&i.EmpiricalDifficulty,
+		&i.ResponseCount,
+		&i.Slug,
+		&i.ManagedBy,
 	)
 	return i, err
 }
 
+const getQuizScoreHistogram = `-- name: GetQuizScoreHistogram :many
+WITH scores AS (
+    SELECT score / max_points * 100 AS pct
+    FROM quiz_attempts
+    WHERE quiz_id = $1 AND status = 'submitted' AND official AND max_points > 0
+)
+SELECT b.bucket :: int AS bucket,
+       ((b.bucket - 1) * 100.0 / $2 :: int) :: float AS lower_percent,
+       (b.bucket * 100.0 / $2 :: int) :: float AS upper_percent,
+       COUNT(s.pct) AS attempts
+FROM generate_series(1, $2 :: int) AS b(bucket)
+LEFT JOIN scores s
+    ON LEAST(GREATEST(width_bucket(s.pct, 0, 100, $2 :: int), 1), $2 :: int) = b.bucket
+GROUP BY b.bucket
+ORDER BY b.bucket
+`
+
+type GetQuizScoreHistogramParams struct {
+	QuizID  string `json:"quiz_id"`
+	Buckets int32  `json:"buckets"`
+}
+
+type GetQuizScoreHistogramRow struct {
+	Bucket       int32   `json:"bucket"`
+	LowerPercent float64 `json:"lower_percent"`
+	UpperPercent float64 `json:"upper_percent"`
+	Attempts     int64   `json:"attempts"`
+}
+
+// Splits 0-100% into the requested number of equal buckets, including empty ones. A perfect
+// score falls in the last bucket.
+func (q *Queries) GetQuizScoreHistogram(ctx context.Context, arg GetQuizScoreHistogramParams) ([]GetQuizScoreHistogramRow, error) {
+	rows, err := q.db.Query(ctx, getQuizScoreHistogram, arg.QuizID, arg.Buckets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetQuizScoreHistogramRow{}
+	for rows.Next() {
+		var i GetQuizScoreHistogramRow
+		if err := rows.Scan(
+			&i.Bucket,
+			&i.LowerPercent,
+			&i.UpperPercent,
+			&i.Attempts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getQuizScoreTrend = `-- name: GetQuizScoreTrend :many
+SELECT date_trunc($1 :: text, created_at) :: timestamp AS period_start,
+       COUNT(*) AS attempts,
+       COALESCE(AVG(score / NULLIF(max_points, 0) * 100), 0) :: float AS avg_score_percent
+FROM quiz_attempts
+WHERE quiz_id = $2 AND status = 'submitted' AND official AND created_at IS NOT NULL
+GROUP BY period_start
+ORDER BY period_start
+`
+
+type GetQuizScoreTrendParams struct {
+	Period string `json:"period"`
+	QuizID string `json:"quiz_id"`
+}
+
+type GetQuizScoreTrendRow struct {
+	PeriodStart     pgtype.Timestamp `json:"period_start"`
+	Attempts        int64            `json:"attempts"`
+	AvgScorePercent float64          `json:"avg_score_percent"`
+}
+
+// Groups submitted attempts by period, 'day' or 'week'.
+func (q *Queries) GetQuizScoreTrend(ctx context.Context, arg GetQuizScoreTrendParams) ([]GetQuizScoreTrendRow, error) {
+	rows, err := q.db.Query(ctx, getQuizScoreTrend, arg.Period, arg.QuizID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetQuizScoreTrendRow{}
+	for rows.Next() {
+		var i GetQuizScoreTrendRow
+		if err := rows.Scan(
+			&i.PeriodStart,
+			&i.Attempts,
+			&i.AvgScorePercent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getQuizStats = `-- name: GetQuizStats :one
 SELECT 
     COUNT(*) as attemps_count,
-    AVG(score :: float / total_questions :: float * 100) as avg_score_percent,
+    COALESCE(AVG(score :: float / NULLIF(max_points, 0) :: float * 100), 0) :: float as avg_score_percent,
     MAX(score) as highest_score,
-    MIN(score) as lowest_score
+    MIN(score) as lowest_score,
+    COALESCE(AVG(passed :: int :: float * 100), 0) :: float as pass_rate_percent,
+    COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY score / NULLIF(max_points, 0) * 100), 0) :: float as median_score_percent,
+    COALESCE(stddev_samp(score / NULLIF(max_points, 0) * 100), 0) :: float as stddev_score_percent,
+    COALESCE(percentile_cont(0.25) WITHIN GROUP (ORDER BY score / NULLIF(max_points, 0) * 100), 0) :: float as p25_score_percent,
+    COALESCE(percentile_cont(0.75) WITHIN GROUP (ORDER BY score / NULLIF(max_points, 0) * 100), 0) :: float as p75_score_percent,
+    COALESCE(percentile_cont(0.90) WITHIN GROUP (ORDER BY score / NULLIF(max_points, 0) * 100), 0) :: float as p90_score_percent
 FROM quiz_attempts
-WHERE quiz_id = $1
+WHERE quiz_id = $1 AND status = 'submitted' AND official
 `
 
 type GetQuizStatsRow struct {
-	AttempsCount    int64       `json:"attemps_count"`
-	AvgScorePercent float64     `json:"avg_score_percent"`
-	HighestScore    interface{} `json:"highest_score"`
-	LowestScore     interface{} `json:"lowest_score"`
+	AttempsCount       int64       `json:"attemps_count"`
+	AvgScorePercent    float64     `json:"avg_score_percent"`
+	HighestScore       interface{} `json:"highest_score"`
+	LowestScore        interface{} `json:"lowest_score"`
+	PassRatePercent    float64     `json:"pass_rate_percent"`
+	MedianScorePercent float64     `json:"median_score_percent"`
+	StddevScorePercent float64     `json:"stddev_score_percent"`
+	P25ScorePercent    float64     `json:"p25_score_percent"`
+	P75ScorePercent    float64     `json:"p75_score_percent"`
+	P90ScorePercent    float64     `json:"p90_score_percent"`
 }
 
 func (q *Queries) GetQuizStats(ctx context.Context, quizID string) (GetQuizStatsRow, error) {
@@ -271,13 +605,113 @@ func (q *Queries) GetQuizStats(ctx context.Context, quizID string) (GetQuizStats
 		&i.AvgScorePercent,
 		&i.HighestScore,
 		&i.LowestScore,
+		&i.PassRatePercent,
+		&i.MedianScorePercent,
+		&i.StddevScorePercent,
+		&i.P25ScorePercent,
+		&i.P75ScorePercent,
+		&i.P90ScorePercent,
 	)
 	return i, err
 }
 
-const listQuizAttempts = `-- name: ListQuizAttempts :many
-SELECT id, quiz_id, user_name, score, total_questions, created_at FROM quiz_attempts
+const listFileManagedQuizzes = `-- name: ListFileManagedQuizzes :many
+SELECT id, title, description, created_at, wrong_answer_penalty, pass_percent, max_attempts, cooldown_seconds, opens_at, closes_at, reveal_policy, hint_penalty, difficulty, empirical_difficulty, response_count, slug, managed_by FROM quizzes
+WHERE managed_by = 'files'
+ORDER BY slug
+FOR UPDATE
+`
+
+// Quizzes kept in quiz files by the seeder or sync, which may delete them
+// once their file is gone. They are locked as GetQuizBySlug locks them.
+func (q *Queries) ListFileManagedQuizzes(ctx context.Context) ([]Quiz, error) {
+	rows, err := q.db.Query(ctx, listFileManagedQuizzes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Quiz{}
+	for rows.Next() {
+		var i Quiz
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.CreatedAt,
+			&i.WrongAnswerPenalty,
+			&i.PassPercent,
+			&i.MaxAttempts,
+			&i.CooldownSeconds,
+			&i.OpensAt,
+			&i.ClosesAt,
+			&i.RevealPolicy,
+			&i.HintPenalty,
+			&i.Difficulty,
+			&i.EmpiricalDifficulty,
+			&i.ResponseCount,
+			&i.Slug,
+			&i.ManagedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQuestionsWithAnswers = `-- name: ListQuestionsWithAnswers :many
+SELECT id, quiz_id, question_text, option_a, option_b, option_c, option_d, correct_answer, created_at, points, explanation, hints, difficulty, empirical_difficulty, response_count, irt_difficulty, irt_discrimination, irt_calibrated_at, external_id FROM questions
 WHERE quiz_id = $1
+ORDER BY created_at
+`
+
+// For authors only: includes correct answers, explanations and hints.
+func (q *Queries) ListQuestionsWithAnswers(ctx context.Context, quizID string) ([]Question, error) {
+	rows, err := q.db.Query(ctx, listQuestionsWithAnswers, quizID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Question{}
+	for rows.Next() {
+		var i Question
+		if err := rows.Scan(
+			&i.ID,
+			&i.QuizID,
+			&i.QuestionText,
+			&i.OptionA,
+			&i.OptionB,
+			&i.OptionC,
+			&i.OptionD,
+			&i.CorrectAnswer,
+			&i.CreatedAt,
+			&i.Points,
+			&i.Explanation,
+			&i.Hints,
+			&i.Difficulty,
+			&i.EmpiricalDifficulty,
+			&i.ResponseCount,
+			&i.IrtDifficulty,
+			&i.IrtDiscrimination,
+			&i.IrtCalibratedAt,
+			&i.ExternalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQuizAttempts = `-- name: ListQuizAttempts :many
+SELECT id, quiz_id, user_name, score, total_questions, created_at, max_points, passed, status, mode, ability, ability_se, official, current_question_id FROM quiz_attempts
+WHERE quiz_id = $1 AND official
 ORDER BY created_at DESC
 `
 
@@ -297,6 +731,14 @@ func (q *Queries) ListQuizAttempts(ctx context.Context, quizID string) ([]QuizAt
 			&i.Score,
 			&i.TotalQuestions,
 			&i.CreatedAt,
+			&i.MaxPoints,
+			&i.Passed,
+			&i.Status,
+			&i.Mode,
+			&i.Ability,
+			&i.AbilitySe,
+			&i.Official,
+			&i.CurrentQuestionID,
 		); err != nil {
 			return nil, err
 		}
@@ -309,7 +751,7 @@ func (q *Queries) ListQuizAttempts(ctx context.Context, quizID string) ([]QuizAt
 }
 
 const listQuizzes = `-- name: ListQuizzes :many
-SELECT id, title, description, created_at FROM quizzes
+SELECT id, title, description, created_at, wrong_answer_penalty, pass_percent, max_attempts, cooldown_seconds, opens_at, closes_at, reveal_policy, hint_penalty, difficulty, empirical_difficulty, response_count, slug, managed_by FROM quizzes
 ORDER BY created_at DESC
 `
 
@@ -327,6 +769,19 @@ func (q *Queries) ListQuizzes(ctx context.Context) ([]Quiz, error) {
 			&i.Title,
 			&i.Description,
 			&i.CreatedAt,
+			&i.WrongAnswerPenalty,
+			&i.PassPercent,
+			&i.MaxAttempts,
+			&i.CooldownSeconds,
+			&i.OpensAt,
+			&i.ClosesAt,
+			&i.RevealPolicy,
+			&i.HintPenalty,
+			&i.Difficulty,
+			&i.EmpiricalDifficulty,
+			&i.ResponseCount,
+			&i.Slug,
+			&i.ManagedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -338,6 +793,113 @@ func (q *Queries) ListQuizzes(ctx context.Context) ([]Quiz, error) {
 	return items, nil
 }
 
+const lockAttempts = `-- name: LockAttempts :exec
+SELECT pg_advisory_xact_lock(hashtext($1 :: varchar || '/' || $2 :: varchar))
+`
+
+type LockAttemptsParams struct {
+	QuizID   string `json:"quiz_id"`
+	UserName string `json:"user_name"`
+}
+
+// LockAttempts holds a player's attempts on a quiz until the transaction
+// ends, so an eligibility check and the attempt it allows cannot interleave
+// with another request's.
+func (q *Queries) LockAttempts(ctx context.Context, arg LockAttemptsParams) error {
+	_, err := q.db.Exec(ctx, lockAttempts, arg.QuizID, arg.UserName)
+	return err
+}
+
+const markQuizFileManaged = `-- name: MarkQuizFileManaged :exec
+UPDATE quizzes
+SET managed_by = 'files'
+WHERE id = $1
+`
+
+// Marks a quiz as kept in a quiz file, see ListFileManagedQuizzes.
+func (q *Queries) MarkQuizFileManaged(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, markQuizFileManaged, id)
+	return err
+}
+
+const recalculateQuestionDifficulty = `-- name: RecalculateQuestionDifficulty :exec
+UPDATE questions q
+SET empirical_difficulty = s.p_value,
+    response_count = s.responses
+FROM (
+    SELECT aa.question_id, AVG(aa.is_correct::int)::float8 AS p_value, COUNT(*)::int AS responses
+    FROM attempt_answers aa
+    JOIN questions qs ON qs.id = aa.question_id
+    JOIN quiz_attempts a ON a.id = aa.attempt_id
+    WHERE qs.quiz_id = $1 AND a.official
+    GROUP BY aa.question_id
+) s
+WHERE q.id = s.question_id
+`
+
+func (q *Queries) RecalculateQuestionDifficulty(ctx context.Context, quizID string) error {
+	_, err := q.db.Exec(ctx, recalculateQuestionDifficulty, quizID)
+	return err
+}
+
+const recalculateQuizDifficulty = `-- name: RecalculateQuizDifficulty :exec
+UPDATE quizzes z
+SET empirical_difficulty = s.p_value,
+    response_count = s.responses
+FROM (
+    SELECT q.quiz_id, AVG(aa.is_correct::int)::float8 AS p_value, COUNT(*)::int AS responses
+    FROM attempt_answers aa
+    JOIN questions q ON q.id = aa.question_id
+    JOIN quiz_attempts a ON a.id = aa.attempt_id
+    WHERE q.quiz_id = $1 AND a.official
+    GROUP BY q.quiz_id
+) s
+WHERE z.id = s.quiz_id
+`
+
+func (q *Queries) RecalculateQuizDifficulty(ctx context.Context, quizID string) error {
+	_, err := q.db.Exec(ctx, recalculateQuizDifficulty, quizID)
+	return err
+}
+
+const setQuestionExternalID = `-- name: SetQuestionExternalID :exec
+UPDATE questions
+SET external_id = $1 :: varchar
+WHERE id = $2
+  AND external_id IS NULL
+`
+
+type SetQuestionExternalIDParams struct {
+	ExternalID string `json:"external_id"`
+	ID         string `json:"id"`
+}
+
+// Gives a question created through the API an external ID, so that bundles
+// and quiz files can update it from then on.
+func (q *Queries) SetQuestionExternalID(ctx context.Context, arg SetQuestionExternalIDParams) error {
+	_, err := q.db.Exec(ctx, setQuestionExternalID, arg.ExternalID, arg.ID)
+	return err
+}
+
+const setQuizSlug = `-- name: SetQuizSlug :exec
+UPDATE quizzes
+SET slug = $1 :: varchar
+WHERE id = $2
+  AND slug IS NULL
+`
+
+type SetQuizSlugParams struct {
+	Slug string `json:"slug"`
+	ID   string `json:"id"`
+}
+
+// Gives a quiz created through the API a slug, so that bundles and quiz
+// files can update it from then on.
+func (q *Queries) SetQuizSlug(ctx context.Context, arg SetQuizSlugParams) error {
+	_, err := q.db.Exec(ctx, setQuizSlug, arg.Slug, arg.ID)
+	return err
+}
+
 const updateQuestion = `-- name: UpdateQuestion :one
 UPDATE questions
 SET question_text = $2, 
@@ -345,19 +907,27 @@ SET question_text = $2,
     option_b = $4, 
     option_c = $5, 
     option_d = $6, 
-    correct_answer = $7
+    correct_answer = $7,
+    points = $8,
+    explanation = $9,
+    hints = $10,
+    difficulty = $11
 WHERE id = $1
-RETURNING id, quiz_id, question_text, option_a, option_b, option_c, option_d, correct_answer, created_at
+RETURNING id, quiz_id, question_text, option_a, option_b, option_c, option_d, correct_answer, created_at, points, explanation, hints, difficulty, empirical_difficulty, response_count, irt_difficulty, irt_discrimination, irt_calibrated_at, external_id
 `
 
 type UpdateQuestionParams struct {
-	ID            string `json:"id"`
-	QuestionText  string `json:"question_text"`
-	OptionA       string `json:"option_a"`
-	OptionB       string `json:"option_b"`
-	OptionC       string `json:"option_c"`
-	OptionD       string `json:"option_d"`
-	CorrectAnswer string `json:"correct_answer"`
+	ID            string   `json:"id"`
+	QuestionText  string   `json:"question_text"`
+	OptionA       string   `json:"option_a"`
+	OptionB       string   `json:"option_b"`
+	OptionC       string   `json:"option_c"`
+	OptionD       string   `json:"option_d"`
+	CorrectAnswer string   `json:"correct_answer"`
+	Points        float64  `json:"points"`
+	Explanation   string   `json:"explanation"`
+	Hints         []string `json:"hints"`
+	Difficulty    *int32   `json:"difficulty"`
 }
 
 func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error) {
@@ -369,6 +939,10 @@ func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams)
 		arg.OptionC,
 		arg.OptionD,
 		arg.CorrectAnswer,
+		arg.Points,
+		arg.Explanation,
+		arg.Hints,
+		arg.Difficulty,
 	)
 	var i Question
 	err := row.Scan(
@@ -381,32 +955,244 @@ func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams)
 		&i.OptionD,
 		&i.CorrectAnswer,
 		&i.CreatedAt,
+		&i.Points,
+		&i.Explanation,
+		&i.Hints,
+		&i.Difficulty,
+		&i.EmpiricalDifficulty,
+		&i.ResponseCount,
+		&i.IrtDifficulty,
+		&i.IrtDiscrimination,
+		&i.IrtCalibratedAt,
+		&i.ExternalID,
 	)
 	return i, err
 }
 
+const updateQuestionCalibration = `-- name: UpdateQuestionCalibration :exec
+UPDATE questions
+SET irt_difficulty = $2,
+    irt_discrimination = $3,
+    irt_calibrated_at = LOCALTIMESTAMP
+WHERE id = $1
+`
+
+type UpdateQuestionCalibrationParams struct {
+	ID                string   `json:"id"`
+	IrtDifficulty     *float64 `json:"irt_difficulty"`
+	IrtDiscrimination *float64 `json:"irt_discrimination"`
+}
+
+func (q *Queries) UpdateQuestionCalibration(ctx context.Context, arg UpdateQuestionCalibrationParams) error {
+	_, err := q.db.Exec(ctx, updateQuestionCalibration, arg.ID, arg.IrtDifficulty, arg.IrtDiscrimination)
+	return err
+}
+
 const updateQuiz = `-- name: UpdateQuiz :one
 UPDATE quizzes 
 SET title = $2,
-    description = $3
+    description = $3,
+    wrong_answer_penalty = $4,
+    pass_percent = $5,
+    max_attempts = $6,
+    cooldown_seconds = $7,
+    opens_at = $8,
+    closes_at = $9,
+    reveal_policy = $10,
+    hint_penalty = $11,
+    difficulty = $12
 WHERE id = $1
-RETURNING id, title, description, created_at
+RETURNING id, title, description, created_at, wrong_answer_penalty, pass_percent, max_attempts, cooldown_seconds, opens_at, closes_at, reveal_policy, hint_penalty, difficulty, empirical_difficulty, response_count, slug, managed_by
 `
 
 type UpdateQuizParams struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	ID                 string           `json:"id"`
+	Title              string           `json:"title"`
+	Description        string           `json:"description"`
+	WrongAnswerPenalty float64          `json:"wrong_answer_penalty"`
+	PassPercent        *float64         `json:"pass_percent"`
+	MaxAttempts        *int32           `json:"max_attempts"`
+	CooldownSeconds    int32            `json:"cooldown_seconds"`
+	OpensAt            pgtype.Timestamp `json:"opens_at"`
+	ClosesAt           pgtype.Timestamp `json:"closes_at"`
+	RevealPolicy       string           `json:"reveal_policy"`
+	HintPenalty        float64          `json:"hint_penalty"`
+	Difficulty         *int32           `json:"difficulty"`
 }
 
 func (q *Queries) UpdateQuiz(ctx context.Context, arg UpdateQuizParams) (Quiz, error) {
-	row := q.db.QueryRow(ctx, updateQuiz, arg.ID, arg.Title, arg.Description)
+	row := q.db.QueryRow(ctx, updateQuiz,
+		arg.ID,
+		arg.Title,
+		arg.Description,
+		arg.WrongAnswerPenalty,
+		arg.PassPercent,
+		arg.MaxAttempts,
+		arg.CooldownSeconds,
+		arg.OpensAt,
+		arg.ClosesAt,
+		arg.RevealPolicy,
+		arg.HintPenalty,
+		arg.Difficulty,
+	)
+	var i Quiz
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.CreatedAt,
+		&i.WrongAnswerPenalty,
+		&i.PassPercent,
+		&i.MaxAttempts,
+		&i.CooldownSeconds,
+		&i.OpensAt,
+		&i.ClosesAt,
+		&i.RevealPolicy,
+		&i.HintPenalty,
+		&i.Difficulty,
+		&i.EmpiricalDifficulty,
+		&i.ResponseCount,
+		&i.Slug,
+		&i.ManagedBy,
+	)
+	return i, err
+}
+
+const upsertQuestion = `-- name: UpsertQuestion :one
+INSERT INTO questions (quiz_id, external_id, question_text, option_a, option_b, option_c, option_d, correct_answer, points, explanation, hints, difficulty)
+VALUES ($1, $2 :: varchar, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (quiz_id, external_id) DO UPDATE
+SET question_text = EXCLUDED.question_text,
+    option_a = EXCLUDED.option_a,
+    option_b = EXCLUDED.option_b,
+    option_c = EXCLUDED.option_c,
+    option_d = EXCLUDED.option_d,
+    correct_answer = EXCLUDED.correct_answer,
+    points = EXCLUDED.points,
+    explanation = EXCLUDED.explanation,
+    hints = EXCLUDED.hints,
+    difficulty = EXCLUDED.difficulty
+RETURNING id, quiz_id, question_text, option_a, option_b, option_c, option_d, correct_answer, created_at, points, explanation, hints, difficulty, empirical_difficulty, response_count, irt_difficulty, irt_discrimination, irt_calibrated_at, external_id
+`
+
+type UpsertQuestionParams struct {
+	QuizID        string   `json:"quiz_id"`
+	ExternalID    string   `json:"external_id"`
+	QuestionText  string   `json:"question_text"`
+	OptionA       string   `json:"option_a"`
+	OptionB       string   `json:"option_b"`
+	OptionC       string   `json:"option_c"`
+	OptionD       string   `json:"option_d"`
+	CorrectAnswer string   `json:"correct_answer"`
+	Points        float64  `json:"points"`
+	Explanation   string   `json:"explanation"`
+	Hints         []string `json:"hints"`
+	Difficulty    *int32   `json:"difficulty"`
+}
+
+func (q *Queries) UpsertQuestion(ctx context.Context, arg UpsertQuestionParams) (Question, error) {
+	row := q.db.QueryRow(ctx, upsertQuestion,
+		arg.QuizID,
+		arg.ExternalID,
+		arg.QuestionText,
+		arg.OptionA,
+		arg.OptionB,
+		arg.OptionC,
+		arg.OptionD,
+		arg.CorrectAnswer,
+		arg.Points,
+		arg.Explanation,
+		arg.Hints,
+		arg.Difficulty,
+	)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.QuizID,
+		&i.QuestionText,
+		&i.OptionA,
+		&i.OptionB,
+		&i.OptionC,
+		&i.OptionD,
+		&i.CorrectAnswer,
+		&i.CreatedAt,
+		&i.Points,
+		&i.Explanation,
+		&i.Hints,
+		&i.Difficulty,
+		&i.EmpiricalDifficulty,
+		&i.ResponseCount,
+		&i.IrtDifficulty,
+		&i.IrtDiscrimination,
+		&i.IrtCalibratedAt,
+		&i.ExternalID,
+	)
+	return i, err
+}
+
+const upsertQuiz = `-- name: UpsertQuiz :one
+INSERT INTO quizzes (slug, title, description, wrong_answer_penalty, pass_percent, max_attempts, cooldown_seconds, reveal_policy, hint_penalty, difficulty)
+VALUES ($1 :: varchar, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (slug) DO UPDATE
+SET title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    wrong_answer_penalty = EXCLUDED.wrong_answer_penalty,
+    pass_percent = EXCLUDED.pass_percent,
+    max_attempts = EXCLUDED.max_attempts,
+    cooldown_seconds = EXCLUDED.cooldown_seconds,
+    reveal_policy = EXCLUDED.reveal_policy,
+    hint_penalty = EXCLUDED.hint_penalty,
+    difficulty = EXCLUDED.difficulty
+RETURNING id, title, description, created_at, wrong_answer_penalty, pass_percent, max_attempts, cooldown_seconds, opens_at, closes_at, reveal_policy, hint_penalty, difficulty, empirical_difficulty, response_count, slug, managed_by
+`
+
+type UpsertQuizParams struct {
+	Slug               string   `json:"slug"`
+	Title              string   `json:"title"`
+	Description        string   `json:"description"`
+	WrongAnswerPenalty float64  `json:"wrong_answer_penalty"`
+	PassPercent        *float64 `json:"pass_percent"`
+	MaxAttempts        *int32   `json:"max_attempts"`
+	CooldownSeconds    int32    `json:"cooldown_seconds"`
+	RevealPolicy       string   `json:"reveal_policy"`
+	HintPenalty        float64  `json:"hint_penalty"`
+	Difficulty         *int32   `json:"difficulty"`
+}
+
+// Availability windows are left alone when updating, so they can be managed
+// separately from the quiz file.
+func (q *Queries) UpsertQuiz(ctx context.Context, arg UpsertQuizParams) (Quiz, error) {
+	row := q.db.QueryRow(ctx, upsertQuiz,
+		arg.Slug,
+		arg.Title,
+		arg.Description,
+		arg.WrongAnswerPenalty,
+		arg.PassPercent,
+		arg.MaxAttempts,
+		arg.CooldownSeconds,
+		arg.RevealPolicy,
+		arg.HintPenalty,
+		arg.Difficulty,
+	)
 	var i Quiz
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
 		&i.Description,
 		&i.CreatedAt,
+		&i.WrongAnswerPenalty,
+		&i.PassPercent,
+		&i.MaxAttempts,
+		&i.CooldownSeconds,
+		&i.OpensAt,
+		&i.ClosesAt,
+		&i.RevealPolicy,
+		&i.HintPenalty,
+		&i.Difficulty,
+		&i.EmpiricalDifficulty,
+		&i.ResponseCount,
+		&i.Slug,
+		&i.ManagedBy,
 	)
 	return i, err
 }
@@ -0,0 +1,154 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Streaming queries hand each row to a callback as pgx reads it, instead of
+// collecting the rows into a slice as the generated :many queries do, so
+// exports of any size run in constant memory. sqlc cannot generate them, so
+// they are written here in the style of the generated code.
+
+// Streamer runs the streaming queries. *Queries satisfies it.
+type Streamer interface {
+	StreamAttemptExport(ctx context.Context, arg StreamAttemptExportParams, fn func(StreamAttemptExportRow) error) error
+	StreamGradebook(ctx context.Context, arg StreamGradebookParams, fn func(StreamGradebookRow) error) error
+}
+
+var _ Streamer = (*Queries)(nil)
+
+// Official attempts, oldest first, with their answers when with_answers
+// is set. An attempt has one row per answer, in question order, or a single
+// row with no answer when it has none.
+const streamAttemptExport = `
+SELECT a.id, a.quiz_id, q.title AS quiz_title, a.user_name, a.mode,
+       a.score, a.max_points, a.passed, a.total_questions, a.created_at,
+       aa.question_id, qu.question_text, aa.answer, aa.is_correct, aa.points_awarded
+FROM quiz_attempts a
+JOIN quizzes q ON q.id = a.quiz_id
+LEFT JOIN attempt_answers aa ON aa.attempt_id = a.id AND $4 :: boolean
+LEFT JOIN questions qu ON qu.id = aa.question_id
+WHERE a.status = 'submitted' AND a.official
+  AND ($1 :: varchar IS NULL OR a.quiz_id = $1)
+  AND ($2 :: timestamp IS NULL OR a.created_at >= $2)
+  AND ($3 :: timestamp IS NULL OR a.created_at < $3)
+ORDER BY a.created_at, a.id, qu.created_at, qu.id
+`
+
+type StreamAttemptExportParams struct {
+	QuizID      *string          `json:"quiz_id"`
+	CreatedFrom pgtype.Timestamp `json:"created_from"`
+	CreatedTo   pgtype.Timestamp `json:"created_to"`
+	WithAnswers bool             `json:"with_answers"`
+}
+
+type StreamAttemptExportRow struct {
+	ID             string           `json:"id"`
+	QuizID         string           `json:"quiz_id"`
+	QuizTitle      string           `json:"quiz_title"`
+	UserName       string           `json:"user_name"`
+	Mode           string           `json:"mode"`
+	Score          float64          `json:"score"`
+	MaxPoints      float64          `json:"max_points"`
+	Passed         bool             `json:"passed"`
+	TotalQuestions int32            `json:"total_questions"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	QuestionID     *string          `json:"question_id"`
+	QuestionText   *string          `json:"question_text"`
+	Answer         *string          `json:"answer"`
+	IsCorrect      *bool            `json:"is_correct"`
+	PointsAwarded  *float64         `json:"points_awarded"`
+}
+
+func (q *Queries) StreamAttemptExport(ctx context.Context, arg StreamAttemptExportParams, fn func(StreamAttemptExportRow) error) error {
+	rows, err := q.db.Query(ctx, streamAttemptExport,
+		arg.QuizID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.WithAnswers,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var i StreamAttemptExportRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.QuizID,
+			&i.QuizTitle,
+			&i.UserName,
+			&i.Mode,
+			&i.Score,
+			&i.MaxPoints,
+			&i.Passed,
+			&i.TotalQuestions,
+			&i.CreatedAt,
+			&i.QuestionID,
+			&i.QuestionText,
+			&i.Answer,
+			&i.IsCorrect,
+			&i.PointsAwarded,
+		); err != nil {
+			return err
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Each player's best official result on each quiz in the period, ordered by
+// player so that a player's results arrive together.
+const streamGradebook = `
+SELECT a.user_name, a.quiz_id,
+       COUNT(*) AS attempts,
+       COALESCE(MAX(a.score / NULLIF(a.max_points, 0) * 100), 0) :: float AS best_score_percent
+FROM quiz_attempts a
+WHERE a.status = 'submitted' AND a.official
+  AND ($1 :: timestamp IS NULL OR a.created_at >= $1)
+  AND ($2 :: timestamp IS NULL OR a.created_at < $2)
+  AND ($3 :: varchar IS NULL
+       OR a.quiz_id IN (SELECT quiz_id FROM collection_quizzes WHERE collection_id = $3))
+GROUP BY a.user_name, a.quiz_id
+ORDER BY a.user_name, a.quiz_id
+`
+
+type StreamGradebookParams struct {
+	CreatedFrom  pgtype.Timestamp `json:"created_from"`
+	CreatedTo    pgtype.Timestamp `json:"created_to"`
+	CollectionID *string          `json:"collection_id"`
+}
+
+type StreamGradebookRow struct {
+	UserName         string  `json:"user_name"`
+	QuizID           string  `json:"quiz_id"`
+	Attempts         int64   `json:"attempts"`
+	BestScorePercent float64 `json:"best_score_percent"`
+}
+
+func (q *Queries) StreamGradebook(ctx context.Context, arg StreamGradebookParams, fn func(StreamGradebookRow) error) error {
+	rows, err := q.db.Query(ctx, streamGradebook, arg.CreatedFrom, arg.CreatedTo, arg.CollectionID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var i StreamGradebookRow
+		if err := rows.Scan(
+			&i.UserName,
+			&i.QuizID,
+			&i.Attempts,
+			&i.BestScorePercent,
+		); err != nil {
+			return err
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TxBeginner starts database transactions. *pgxpool.Pool satisfies it.
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// ExecTx runs fn with a querier bound to a new transaction. The transaction
+// is committed if fn returns nil and rolled back otherwise.
+func ExecTx(ctx context.Context, db TxBeginner, fn func(Querier) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rolling back after a commit does nothing, and a failed rollback
+	// leaves nothing to undo
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = fn(New(tx))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,71 @@
+// Package difficulty describes how hard quizzes and questions are, both as
+// set by their author and as measured from recorded answers.
+package difficulty
+
+// Author-set difficulty ranges from Min (very easy) to Max (very hard).
+const (
+	Min = 1
+	Max = 5
+)
+
+var labels = [...]string{"very easy", "easy", "medium", "hard", "very hard"}
+
+// Valid reports whether d is an acceptable author-set difficulty. A nil
+// difficulty means the author did not set one.
+func Valid(d *int32) bool {
+	return d == nil || (*d >= Min && *d <= Max)
+}
+
+// Label names an author-set difficulty, e.g. 3 is "medium".
+func Label(d int32) string {
+	if d < Min || d > Max {
+		return ""
+	}
+	return labels[d-Min]
+}
+
+// Empirical names a measured difficulty, the proportion of answers that were
+// correct. Easy items are answered correctly most of the time.
+func Empirical(p float64) string {
+	switch {
+	case p >= 0.9:
+		return "very easy"
+	case p >= 0.7:
+		return "easy"
+	case p >= 0.4:
+		return "medium"
+	case p >= 0.2:
+		return "hard"
+	default:
+		return "very hard"
+	}
+}
+
+// Range filters on an author-set difficulty and on the measured proportion
+// correct. Nil bounds are open.
+type Range struct {
+	Min, Max               *int32
+	MinCorrect, MaxCorrect *float64
+}
+
+// Empty reports whether r filters nothing.
+func (r Range) Empty() bool {
+	return r.Min == nil && r.Max == nil && r.MinCorrect == nil && r.MaxCorrect == nil
+}
+
+// Contains reports whether an item with the given author-set and measured
+// difficulty falls inside r. Items without a value for a bounded dimension
+// are excluded.
+func (r Range) Contains(d *int32, p *float64) bool {
+	if r.Min != nil || r.Max != nil {
+		if d == nil || (r.Min != nil && *d < *r.Min) || (r.Max != nil && *d > *r.Max) {
+			return false
+		}
+	}
+	if r.MinCorrect != nil || r.MaxCorrect != nil {
+		if p == nil || (r.MinCorrect != nil && *p < *r.MinCorrect) || (r.MaxCorrect != nil && *p > *r.MaxCorrect) {
+			return false
+		}
+	}
+	return true
+}
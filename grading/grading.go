@@ -0,0 +1,136 @@
+// Package grading records graded quiz attempts. Every way of taking a quiz,
+// the API, adaptive attempts, paper exams and the CLI, submits through
+// Submit, so attempts are stored, queued for review and certified alike.
+// Attempts started before they are submitted go through Start, which
+// checks the quiz's attempt policy under the player's lock.
+package grading
+
+import (
+	"context"
+
+	"github.com/Iknite-Space/sqlc-example-api/certificate"
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/scoring"
+)
+
+// Submission is an attempt to grade and record.
+type Submission struct {
+	Quiz repo.Quiz
+	// AttemptID submits an attempt that was started earlier. Without it a
+	// new attempt is created for UserName in Mode.
+	AttemptID string
+	UserName  string
+	Mode      string
+	// Questions are the questions graded, with the hints used on each.
+	Questions []scoring.Question
+	// Answers are keyed by question ID. A missing answer is a skipped
+	// question.
+	Answers map[string]string
+	// Stored is set when the answers were already recorded one at a time,
+	// as adaptive attempts do.
+	Stored bool
+}
+
+// Submitted is a recorded attempt.
+type Submitted struct {
+	Attempt repo.QuizAttempt
+	Summary scoring.Summary
+	Passed  bool
+	// Certificate is issued for passed official attempts only.
+	Certificate *repo.Certificate
+}
+
+// Submit grades a submission and records the attempt with its answers,
+// queues the missed questions for review and issues a certificate when an
+// official attempt passes. q should be bound to a transaction, so an
+// attempt is never recorded without its answers.
+//
+// A started attempt that has already been submitted returns pgx.ErrNoRows.
+func Submit(ctx context.Context, q repo.Querier, s Submission) (Submitted, error) {
+	summary := scoring.Grade(s.Questions, s.Answers, scoring.Rules{
+		WrongAnswerPenalty: s.Quiz.WrongAnswerPenalty,
+		HintPenalty:        s.Quiz.HintPenalty,
+	})
+	result := Submitted{
+		Summary: summary,
+		Passed:  scoring.Passed(summary.Score, summary.MaxPoints, s.Quiz.PassPercent),
+	}
+
+	var err error
+	if s.AttemptID != "" {
+		result.Attempt, err = q.SubmitQuizAttempt(ctx, repo.SubmitQuizAttemptParams{
+			ID:             s.AttemptID,
+			Score:          summary.Score,
+			MaxPoints:      summary.MaxPoints,
+			TotalQuestions: int32(len(s.Questions)),
+			Passed:         result.Passed,
+		})
+	} else {
+		result.Attempt, err = q.CreateQuizAttempt(ctx, repo.CreateQuizAttemptParams{
+			QuizID:         s.Quiz.ID,
+			UserName:       s.UserName,
+			Score:          summary.Score,
+			MaxPoints:      summary.MaxPoints,
+			TotalQuestions: int32(len(s.Questions)),
+			Passed:         result.Passed,
+			Mode:           s.Mode,
+		})
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if !s.Stored {
+		err = q.CreateAttemptAnswers(ctx, AnswersParams(result.Attempt.ID, summary, s.Answers))
+		if err != nil {
+			return result, err
+		}
+	}
+
+	err = q.EnqueueMissedQuestions(ctx, result.Attempt.ID)
+	if err != nil {
+		return result, err
+	}
+
+	if !result.Passed || !result.Attempt.Official {
+		return result, nil
+	}
+	cert, err := certificate.Issue(ctx, q, result.Attempt.ID)
+	if err != nil {
+		return result, err
+	}
+	result.Certificate = &cert
+	return result, nil
+}
+
+// AnswersParams collects a graded attempt's answers for storage.
+func AnswersParams(attemptID string, summary scoring.Summary, answers map[string]string) repo.CreateAttemptAnswersParams {
+	params := repo.CreateAttemptAnswersParams{
+		AttemptID:     attemptID,
+		QuestionIds:   make([]string, 0, len(summary.Results)),
+		Answers:       make([]string, 0, len(summary.Results)),
+		Correct:       make([]bool, 0, len(summary.Results)),
+		PointsAwarded: make([]float64, 0, len(summary.Results)),
+	}
+
+	for _, r := range summary.Results {
+		params.QuestionIds = append(params.QuestionIds, r.QuestionID)
+		params.Answers = append(params.Answers, answers[r.QuestionID])
+		params.Correct = append(params.Correct, r.Correct)
+		params.PointsAwarded = append(params.PointsAwarded, r.Points)
+	}
+
+	return params
+}
+
+// RecalculateDifficulty refreshes the measured difficulty of a quiz and its
+// questions from the answers recorded so far. It is run after an attempt's
+// transaction commits, since it rewrites every question of the quiz.
+func RecalculateDifficulty(ctx context.Context, q repo.Querier, quizID string) error {
+	err := q.RecalculateQuestionDifficulty(ctx, quizID)
+	if err != nil {
+		return err
+	}
+
+	return q.RecalculateQuizDifficulty(ctx, quizID)
+}
@@ -0,0 +1,64 @@
+package grading
+
+import (
+	"context"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/policy"
+)
+
+// RefusedError is returned when the locked eligibility check refuses an
+// attempt.
+type RefusedError struct {
+	Decision policy.Decision
+}
+
+func (e RefusedError) Error() string {
+	return e.Decision.Message
+}
+
+// CheckEligibility takes the player's attempt lock on the quiz and checks
+// the quiz's attempt policy for an attempt in mode. Run it in the
+// transaction that creates the attempt, so parallel starts cannot go over
+// the attempt limit or skip the cooldown. A refused attempt returns a
+// RefusedError.
+func CheckEligibility(ctx context.Context, q repo.Querier, quizID, userName, mode string) (policy.Decision, error) {
+	err := q.LockAttempts(ctx, repo.LockAttemptsParams{QuizID: quizID, UserName: userName})
+	if err != nil {
+		return policy.Decision{}, err
+	}
+
+	eligibility, err := q.GetAttemptEligibility(ctx, repo.GetAttemptEligibilityParams{
+		ID:       quizID,
+		UserName: userName,
+	})
+	if err != nil {
+		return policy.Decision{}, err
+	}
+
+	decision := policy.Check(eligibility)
+	if !decision.Allowed && (policy.Official(mode) || !decision.AllowsUnofficial()) {
+		return decision, RefusedError{decision}
+	}
+	return decision, nil
+}
+
+// Start starts an attempt that is submitted later, once CheckEligibility
+// allows it. The check and the start run in one transaction under the
+// player's lock. Every way of starting an attempt, the API and the CLI,
+// goes through Start.
+func Start(ctx context.Context, db repo.TxBeginner, params repo.StartQuizAttemptParams) (repo.QuizAttempt, policy.Decision, error) {
+	var attempt repo.QuizAttempt
+	var decision policy.Decision
+	err := repo.ExecTx(ctx, db, func(q repo.Querier) error {
+		var err error
+		decision, err = CheckEligibility(ctx, q, params.QuizID, params.UserName, params.Mode)
+		if err != nil {
+			return err
+		}
+
+		attempt, err = q.StartQuizAttempt(ctx, params)
+		return err
+	})
+	return attempt, decision, err
+}
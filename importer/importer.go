@@ -0,0 +1,473 @@
+// Package importer reads questions from CSV files, Excel workbooks, Moodle
+// question banks, Open Trivia DB dumps and Kahoot spreadsheets so they can be
+// added to a quiz.
+//
+// In spreadsheets the first row names the columns, using the field names of the quiz file
+// format (see package quizfile): question_text, option_a, option_b and
+// correct_answer are required, and option_c, option_d, id, points,
+// explanation, hints, difficulty and tags are optional. Hints and tags hold
+// several values separated by "|". Moodle XML and GIFT files are read with
+// package moodle, and Open Trivia DB JSON dumps and workbooks made from
+// Kahoot's quiz template with package trivia.
+//
+// Importing is done in two steps. Read parses and validates a file without
+// touching the database, Plan shows what saving the rows would change, and
+// Apply writes them.
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Iknite-Space/sqlc-example-api/bundle"
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/moodle"
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+	"github.com/Iknite-Space/sqlc-example-api/trivia"
+	"github.com/Iknite-Space/sqlc-example-api/xlsx"
+)
+
+// MaxSize is the largest file Read accepts.
+const MaxSize = 5 << 20
+
+// Row is a question read from a file, with the line it was read from.
+type Row struct {
+	Line     int               `json:"line"`
+	Question quizfile.Question `json:"question"`
+}
+
+// Problem is something wrong with a line of a file.
+type Problem struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (p Problem) Error() string {
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// Sheet is a file of questions. Its rows can only be saved when it has no
+// problems. Warnings report content that was skipped or dropped, such as
+// Moodle question types quizzes do not have, and do not stop an import.
+type Sheet struct {
+	Rows     []Row     `json:"rows"`
+	Problems []Problem `json:"problems"`
+	Warnings []Problem `json:"warnings"`
+}
+
+// Err joins the sheet's problems, or returns nil when there are none.
+func (s Sheet) Err() error {
+	errs := make([]error, len(s.Problems))
+	for i, p := range s.Problems {
+		errs[i] = p
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	required = []string{"question_text", "option_a", "option_b", "correct_answer"}
+	optional = []string{"option_c", "option_d", "id", "points", "explanation", "hints", "difficulty", "tags"}
+)
+
+// Supported reports whether name has an extension Read understands.
+func Supported(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv", ".xlsx", ".xml", ".gift", ".txt", ".json":
+		return true
+	}
+	return false
+}
+
+// Read parses a CSV, XLSX, Moodle XML, GIFT or Open Trivia DB file, chosen
+// by the extension of name; .txt files are read as GIFT, which is how Moodle
+// exports it, and .json files as Open Trivia DB dumps. The returned error is
+// for files that cannot be read at all; problems with individual rows are
+// reported in the sheet.
+func Read(name string, r io.Reader) (Sheet, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if err != nil {
+		return Sheet{}, err
+	}
+	if len(data) > MaxSize {
+		return Sheet{}, fmt.Errorf("file is larger than %d MB", MaxSize>>20)
+	}
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return ReadCSV(bytes.NewReader(data))
+	case ".xlsx":
+		return ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	case ".xml":
+		return readMoodle(bytes.NewReader(data), moodle.ReadXML)
+	case ".gift", ".txt":
+		return readMoodle(bytes.NewReader(data), moodle.ReadGIFT)
+	case ".json":
+		return readTrivia(trivia.ReadOpenTDB(bytes.NewReader(data)))
+	}
+	return Sheet{}, fmt.Errorf("%s: unsupported file type, want .csv, .xlsx, .xml, .gift, .txt or .json", name)
+}
+
+// ReadCSV parses CSV text. A leading byte order mark, which Excel writes,
+// is ignored.
+func ReadCSV(r io.Reader) (Sheet, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Sheet{}, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+
+	var rows []xlsx.Row
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Sheet{}, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		rows = append(rows, xlsx.Row{Number: line, Cells: record})
+	}
+
+	return parse(rows), nil
+}
+
+// ReadXLSX parses the first worksheet of an Excel workbook, or reads it as
+// a Kahoot quiz when it follows Kahoot's template. Lines are the row numbers
+// Excel shows.
+func ReadXLSX(r io.ReaderAt, size int64) (Sheet, error) {
+	rows, err := xlsx.Read(r, size)
+	if err != nil {
+		return Sheet{}, err
+	}
+	if trivia.IsKahoot(rows) {
+		return readTrivia(trivia.ReadKahoot(rows))
+	}
+	return parse(rows), nil
+}
+
+// readMoodle reads a Moodle question bank. Questions that cannot be
+// converted are skipped with a warning.
+func readMoodle(r io.Reader, read func(io.Reader) ([]moodle.Question, []moodle.Issue, error)) (Sheet, error) {
+	questions, issues, err := read(r)
+	if err != nil {
+		return Sheet{}, err
+	}
+	converted, more := moodle.ToQuizfile(questions)
+	issues = append(issues, more...)
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+
+	var s Sheet
+	for _, issue := range issues {
+		message := issue.Message
+		if issue.Question != "" {
+			message = issue.Question + ": " + message
+		}
+		s.Warnings = append(s.Warnings, Problem{Line: issue.Line, Message: message})
+	}
+
+	ids := make(map[string]int)
+	for _, c := range converted {
+		s.add(c.Line, c.Question, ids)
+	}
+	if len(s.Rows) == 0 && len(s.Problems) == 0 {
+		s.Problems = append(s.Problems, Problem{Line: 1, Message: "no questions that can be imported"})
+	}
+
+	return s, nil
+}
+
+// readTrivia makes a sheet of trivia questions. Questions that were skipped
+// are warnings.
+func readTrivia(items []trivia.Item, issues []trivia.Issue, err error) (Sheet, error) {
+	if err != nil {
+		return Sheet{}, err
+	}
+
+	var s Sheet
+	for _, issue := range issues {
+		s.Warnings = append(s.Warnings, Problem{Line: issue.Line, Message: issue.Message})
+	}
+
+	ids := make(map[string]int)
+	for _, item := range items {
+		s.add(item.Line, item.Question, ids)
+	}
+	if len(s.Rows) == 0 && len(s.Problems) == 0 {
+		s.Problems = append(s.Problems, Problem{Line: 1, Message: "no questions that can be imported"})
+	}
+
+	return s, nil
+}
+
+// add validates a question and adds it to the sheet. ids records the line
+// each question ID was first used on.
+func (s *Sheet) add(line int, q quizfile.Question, ids map[string]int) {
+	for _, err := range q.Problems() {
+		s.Problems = append(s.Problems, Problem{Line: line, Message: err.Error()})
+	}
+	if first, ok := ids[q.ID]; ok {
+		s.Problems = append(s.Problems, Problem{Line: line, Message: fmt.Sprintf("id %q is already used on line %d", q.ID, first)})
+	} else {
+		ids[q.ID] = line
+	}
+
+	s.Rows = append(s.Rows, Row{Line: line, Question: q})
+}
+
+func parse(rows []xlsx.Row) Sheet {
+	var s Sheet
+	fail := func(line int, format string, args ...any) {
+		s.Problems = append(s.Problems, Problem{Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Skip blank lines before the header
+	for len(rows) > 0 && blank(rows[0].Cells) {
+		rows = rows[1:]
+	}
+	if len(rows) == 0 {
+		fail(1, "file is empty")
+		return s
+	}
+
+	header := rows[0]
+	columns := make(map[string]int)
+	for i, name := range header.Cells {
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+		if name == "" {
+			continue
+		}
+		if !known(name) {
+			fail(header.Number, "unknown column %q", name)
+			continue
+		}
+		if _, ok := columns[name]; ok {
+			fail(header.Number, "column %q appears more than once", name)
+			continue
+		}
+		columns[name] = i
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			fail(header.Number, "missing column %q", name)
+		}
+	}
+	if len(s.Problems) > 0 {
+		return s
+	}
+
+	ids := make(map[string]int)
+	for _, row := range rows[1:] {
+		if blank(row.Cells) {
+			continue
+		}
+
+		cell := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(row.Cells) {
+				return ""
+			}
+			return strings.TrimSpace(row.Cells[i])
+		}
+
+		q := quizfile.Question{
+			ID:            cell("id"),
+			QuestionText:  cell("question_text"),
+			OptionA:       cell("option_a"),
+			OptionB:       cell("option_b"),
+			OptionC:       cell("option_c"),
+			OptionD:       cell("option_d"),
+			CorrectAnswer: strings.ToUpper(cell("correct_answer")),
+			Points:        1,
+			Explanation:   cell("explanation"),
+			Hints:         list(cell("hints")),
+			Tags:          list(cell("tags")),
+		}
+		if q.ID == "" {
+			q.ID = quizfile.QuestionID(q.QuestionText)
+		}
+
+		// Cells that are not numbers are reported here and left at their
+		// defaults, so validation does not report them again
+		if v := cell("points"); v != "" {
+			points, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				fail(row.Number, "points %q is not a number", v)
+			} else {
+				q.Points = points
+			}
+		}
+		if v := cell("difficulty"); v != "" {
+			// Spreadsheets store whole numbers as decimals, such as "3.0"
+			d, err := strconv.ParseFloat(v, 64)
+			if err != nil || d != float64(int32(d)) {
+				fail(row.Number, "difficulty %q is not a whole number", v)
+			} else {
+				difficulty := int32(d)
+				q.Difficulty = &difficulty
+			}
+		}
+
+		s.add(row.Number, q, ids)
+	}
+
+	if len(s.Rows) == 0 && len(s.Problems) == 0 {
+		fail(header.Number, "no questions below the header")
+	}
+
+	return s
+}
+
+func known(column string) bool {
+	for _, names := range [][]string{required, optional} {
+		for _, name := range names {
+			if name == column {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func blank(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// list splits a cell holding values separated by "|".
+func list(cell string) []string {
+	var values []string
+	for _, v := range strings.Split(cell, "|") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Actions a row's question will take when applied. Skip is for questions
+// the quiz already has under another ID, such as questions added through
+// the API, so that importing them does not add duplicates.
+const (
+	Create = "create"
+	Update = "update"
+	Skip   = "skip"
+)
+
+// Change is a row and what applying it will do.
+type Change struct {
+	Row
+	Action string `json:"action"`
+}
+
+// Plan reports, for each row, whether applying it creates a new question,
+// updates the quiz's question with the same ID or is skipped because the
+// quiz has a question with the same text. Text is compared ignoring case
+// and spacing. Nothing is written.
+func Plan(ctx context.Context, querier repo.Querier, quizID string, rows []Row) ([]Change, error) {
+	questions, err := querier.ListQuestionsWithAnswers(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(questions))
+	texts := make(map[string]bool, len(questions))
+	for _, q := range questions {
+		if q.ExternalID != nil {
+			existing[*q.ExternalID] = true
+		}
+		texts[normalize(q.QuestionText)] = true
+	}
+
+	changes := make([]Change, len(rows))
+	for i, row := range rows {
+		changes[i] = Change{Row: row, Action: Create}
+		switch {
+		case existing[row.Question.ID]:
+			changes[i].Action = Update
+		case texts[normalize(row.Question.QuestionText)]:
+			changes[i].Action = Skip
+		}
+	}
+	return changes, nil
+}
+
+func normalize(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// Summary counts the questions an import created, updated and skipped.
+type Summary struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+// Apply saves rows to a quiz. Rows update the question with the same ID,
+// so importing an edited file again does not add duplicates, and rows the
+// quiz already has a question for are skipped (see Plan). It makes
+// several writes, so run it in a transaction (see repo.ExecTx) to import
+// all or nothing.
+func Apply(ctx context.Context, querier repo.Querier, quizID string, rows []Row) (Summary, error) {
+	var summary Summary
+
+	changes, err := Plan(ctx, querier, quizID, rows)
+	if err != nil {
+		return summary, err
+	}
+
+	for _, change := range changes {
+		if change.Action == Skip {
+			summary.Skipped++
+			continue
+		}
+
+		q := change.Question
+		question, err := querier.UpsertQuestion(ctx, repo.UpsertQuestionParams{
+			QuizID:        quizID,
+			ExternalID:    q.ID,
+			QuestionText:  q.QuestionText,
+			OptionA:       q.OptionA,
+			OptionB:       q.OptionB,
+			OptionC:       q.OptionC,
+			OptionD:       q.OptionD,
+			CorrectAnswer: q.CorrectAnswer,
+			Points:        q.Points,
+			Explanation:   q.Explanation,
+			Hints:         q.Hints,
+			Difficulty:    q.Difficulty,
+		})
+		if err != nil {
+			return summary, fmt.Errorf("line %d: %w", change.Line, err)
+		}
+
+		err = bundle.SetQuestionTags(ctx, querier, question.ID, q.Tags)
+		if err != nil {
+			return summary, fmt.Errorf("line %d: %w", change.Line, err)
+		}
+
+		if change.Action == Create {
+			summary.Created++
+		} else {
+			summary.Updated++
+		}
+	}
+
+	return summary, nil
+}
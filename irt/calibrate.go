@@ -0,0 +1,183 @@
+package irt
+
+import (
+	"math"
+	"sort"
+)
+
+// Model selects which item parameters Calibrate estimates.
+type Model string
+
+const (
+	// Rasch estimates difficulty only, fixing discrimination at 1.
+	Rasch Model = "rasch"
+	// TwoPL estimates both difficulty and discrimination.
+	TwoPL Model = "2pl"
+)
+
+// Calibration settings. The priors are weak and only matter for sparse data.
+const (
+	emIterations     = 50
+	newtonIterations = 5
+	quadratureStep   = 0.2
+
+	minDiscrimination = 0.2
+	maxDiscrimination = 4
+	maxDifficulty     = 6
+
+	// difficultyPriorVar is the variance of the normal prior on difficulty
+	// and discriminationPriorVar that of the log-normal prior on
+	// discrimination.
+	difficultyPriorVar     = 4.0
+	discriminationPriorVar = 0.5
+)
+
+// Observation is one player's scored answer to one item.
+type Observation struct {
+	Person  string
+	ItemID  string
+	Correct bool
+}
+
+// Calibrated is an item with its estimated parameters and the number of
+// responses the estimate is based on.
+type Calibrated struct {
+	Item
+	Responses int
+}
+
+type scored struct {
+	item    int
+	correct bool
+}
+
+// Calibrate estimates item parameters from observations by marginal maximum
+// likelihood (the Bock-Aitkin EM algorithm). Player abilities are integrated
+// out over a standard normal distribution rather than estimated one by one,
+// which keeps estimates unbiased for short quizzes. Items are returned
+// sorted by ID.
+func Calibrate(observations []Observation, model Model) []Calibrated {
+	persons := make(map[string]int)
+	items := make(map[string]int)
+	var ids []string
+	var answers [][]scored
+	for _, o := range observations {
+		if _, ok := persons[o.Person]; !ok {
+			persons[o.Person] = len(answers)
+			answers = append(answers, nil)
+		}
+		if _, ok := items[o.ItemID]; !ok {
+			items[o.ItemID] = len(ids)
+			ids = append(ids, o.ItemID)
+		}
+		p := persons[o.Person]
+		answers[p] = append(answers[p], scored{items[o.ItemID], o.Correct})
+	}
+
+	params := make([]Item, len(ids))
+	counts := make([]int, len(ids))
+	correct := make([]int, len(ids))
+	for _, o := range observations {
+		i := items[o.ItemID]
+		counts[i]++
+		if o.Correct {
+			correct[i]++
+		}
+	}
+	for i, id := range ids {
+		p := float64(correct[i]) / float64(counts[i])
+		params[i] = DefaultItem(id, &p)
+	}
+
+	var nodes, prior []float64
+	for t := minTheta; t <= maxTheta+quadratureStep/2; t += quadratureStep {
+		nodes = append(nodes, t)
+		prior = append(prior, -t*t/2)
+	}
+
+	for iter := 0; iter < emIterations; iter++ {
+		// E step: expected number of players at each ability node who
+		// answered each item, and who answered it correctly.
+		n := make([][]float64, len(params))
+		r := make([][]float64, len(params))
+		for i := range params {
+			n[i] = make([]float64, len(nodes))
+			r[i] = make([]float64, len(nodes))
+		}
+		post := make([]float64, len(nodes))
+		for _, as := range answers {
+			top := math.Inf(-1)
+			for k, t := range nodes {
+				lw := prior[k]
+				for _, a := range as {
+					p := Probability(t, params[a.item])
+					if a.correct {
+						lw += math.Log(p)
+					} else {
+						lw += math.Log(1 - p)
+					}
+				}
+				post[k] = lw
+				top = math.Max(top, lw)
+			}
+			var total float64
+			for k := range post {
+				post[k] = math.Exp(post[k] - top)
+				total += post[k]
+			}
+			for _, a := range as {
+				for k := range post {
+					w := post[k] / total
+					n[a.item][k] += w
+					if a.correct {
+						r[a.item][k] += w
+					}
+				}
+			}
+		}
+
+		// M step: Newton steps on each item's parameters.
+		for i := range params {
+			for step := 0; step < newtonIterations; step++ {
+				params[i] = maximize(params[i], nodes, n[i], r[i], model)
+			}
+		}
+	}
+
+	out := make([]Calibrated, len(ids))
+	for i := range ids {
+		out[i] = Calibrated{Item: params[i], Responses: counts[i]}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+
+	return out
+}
+
+// maximize takes one Newton step towards the parameters of item that best
+// explain r correct answers out of n at each ability node.
+func maximize(item Item, nodes, n, r []float64, model Model) Item {
+	a, b := item.Discrimination, item.Difficulty
+
+	gb, hb := -b/difficultyPriorVar, 1/difficultyPriorVar
+	ga, ha := -math.Log(a)/(a*discriminationPriorVar), 1/(a*a*discriminationPriorVar)
+	for k, t := range nodes {
+		p := Probability(t, item)
+		residual := r[k] - n[k]*p
+		info := n[k] * p * (1 - p)
+		gb -= a * residual
+		hb += a * a * info
+		ga += (t - b) * residual
+		ha += (t - b) * (t - b) * info
+	}
+
+	item.Difficulty = clamp(b+gb/hb, -maxDifficulty, maxDifficulty)
+	if model == TwoPL {
+		item.Discrimination = clamp(a+ga/ha, minDiscrimination, maxDiscrimination)
+	}
+
+	return item
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
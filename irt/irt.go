@@ -0,0 +1,123 @@
+// Package irt implements the item response theory used for adaptive
+// testing: the two-parameter logistic (2PL) model, ability estimation and
+// item selection. The Rasch model is the special case where every item has
+// a discrimination of 1.
+package irt
+
+import (
+	"math"
+)
+
+// Stopping rules for adaptive attempts.
+const (
+	// DefaultStopSE ends an adaptive attempt once the ability estimate's
+	// standard error falls to this value.
+	DefaultStopSE = 0.3
+	// MaxItems caps the number of questions in an adaptive attempt.
+	MaxItems = 30
+)
+
+// Ability is estimated on a grid spanning this range, where 0 is an
+// average player and each unit is one standard deviation.
+const (
+	minTheta  = -4.0
+	maxTheta  = 4.0
+	thetaStep = 0.05
+)
+
+// Item holds the calibrated parameters of a question.
+type Item struct {
+	ID             string  `json:"id"`
+	Discrimination float64 `json:"discrimination"`
+	Difficulty     float64 `json:"difficulty"`
+}
+
+// Response is a scored answer to an item.
+type Response struct {
+	Item    Item
+	Correct bool
+}
+
+// DefaultItem returns parameters for a question that has not been
+// calibrated yet. When the proportion of correct answers p is known, the
+// difficulty is derived from it; otherwise the item is assumed average.
+func DefaultItem(id string, p *float64) Item {
+	item := Item{ID: id, Discrimination: 1}
+	if p != nil {
+		q := math.Min(math.Max(*p, 0.02), 0.98)
+		item.Difficulty = math.Log((1 - q) / q)
+	}
+	return item
+}
+
+// Probability is the chance that a player of ability theta answers item
+// correctly.
+func Probability(theta float64, item Item) float64 {
+	return 1 / (1 + math.Exp(-item.Discrimination*(theta-item.Difficulty)))
+}
+
+// Information is the Fisher information item provides at ability theta.
+// Items are most informative near their own difficulty.
+func Information(theta float64, item Item) float64 {
+	p := Probability(theta, item)
+	return item.Discrimination * item.Discrimination * p * (1 - p)
+}
+
+// EstimateAbility returns the expected a posteriori (EAP) ability estimate
+// for responses and its standard error, using a standard normal prior.
+// Unlike maximum likelihood it stays finite when every answer is right or
+// every answer is wrong. With no responses it returns the prior, 0 and 1.
+func EstimateAbility(responses []Response) (theta, se float64) {
+	var nodes, logWeights []float64
+	top := math.Inf(-1)
+	for t := minTheta; t <= maxTheta+thetaStep/2; t += thetaStep {
+		logWeight := -t * t / 2
+		for _, r := range responses {
+			p := Probability(t, r.Item)
+			if r.Correct {
+				logWeight += math.Log(p)
+			} else {
+				logWeight += math.Log(1 - p)
+			}
+		}
+		nodes = append(nodes, t)
+		logWeights = append(logWeights, logWeight)
+		top = math.Max(top, logWeight)
+	}
+
+	// Weights are taken relative to the largest, as in Calibrate, so long
+	// response patterns do not underflow to zero
+	var total, mean, second float64
+	for k, t := range nodes {
+		w := math.Exp(logWeights[k] - top)
+		total += w
+		mean += w * t
+		second += w * t * t
+	}
+
+	mean /= total
+	return mean, math.Sqrt(math.Max(second/total-mean*mean, 0))
+}
+
+// NextItem picks the unanswered item from pool that is most informative at
+// ability theta. Ties go to the earlier item in pool, so the choice is
+// deterministic. It returns false once every item has been answered.
+func NextItem(theta float64, pool []Item, answered map[string]bool) (Item, bool) {
+	var best Item
+	bestInfo := -1.0
+	for _, item := range pool {
+		if answered[item.ID] {
+			continue
+		}
+		if info := Information(theta, item); info > bestInfo {
+			best, bestInfo = item, info
+		}
+	}
+	return best, bestInfo >= 0
+}
+
+// Done reports whether an adaptive attempt that has administered n of
+// poolSize items should stop with standard error se.
+func Done(se float64, n, poolSize int) bool {
+	return se <= DefaultStopSE || n >= MaxItems || n >= poolSize
+}
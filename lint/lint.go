@@ -0,0 +1,225 @@
+// Package lint checks quiz content for authoring mistakes that validation
+// lets through: duplicate or blank options, a correct answer that is nearly
+// always the same letter, near-duplicate questions, misused "all of the
+// above" options and overlong options.
+//
+// Rules report findings with a severity. Errors are mistakes that should
+// not reach players: they block syncing quiz files (see cmd/sync) and
+// publishing through the API, whether by import or by creating and
+// editing quizzes and questions. Warnings and infos are reported for an
+// author to judge. Each rule's severity and limits can be changed, or the
+// rule turned off, with a YAML configuration file:
+//
+//	rules:
+//	  answer-balance:
+//	    severity: error
+//	    max_share: 0.4
+//	  long-options:
+//	    max_length: 100
+//	  all-of-the-above: off
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity is how serious a finding is.
+type Severity string
+
+// Severities, from most to least serious. Off turns a rule off.
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+	Info    Severity = "info"
+	Off     Severity = "off"
+)
+
+func validSeverity(s Severity) bool {
+	return s == Error || s == Warning || s == Info || s == Off
+}
+
+// Finding is a problem a rule found in a quiz or one of its questions.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Quiz is the quiz's slug and File the quiz file it was read from, if
+	// any.
+	Quiz string `json:"quiz"`
+	File string `json:"file,omitempty"`
+	// Question is the question's ID, empty for findings about the whole
+	// quiz. Number is its position in the quiz, from 1.
+	Question string `json:"question,omitempty"`
+	Number   int    `json:"number,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (f Finding) String() string {
+	where := f.Quiz
+	if f.File != "" {
+		where = f.File
+	}
+	if f.Number > 0 {
+		where += fmt.Sprintf(": question %d (%s)", f.Number, f.Question)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", where, f.Severity, f.Message, f.Rule)
+}
+
+// RuleConfig configures a rule. Limits left at zero take the rule's
+// default, and each rule only reads the limits it documents.
+type RuleConfig struct {
+	Severity Severity `yaml:"severity" json:"severity"`
+	// MaxShare is the largest share of questions, from 0 to 1, that can
+	// have the same correct letter (answer-balance).
+	MaxShare float64 `yaml:"max_share,omitempty" json:"max_share,omitempty"`
+	// MinQuestions is the number of questions a quiz needs before its
+	// answers are checked for balance (answer-balance).
+	MinQuestions int `yaml:"min_questions,omitempty" json:"min_questions,omitempty"`
+	// Similarity is how alike, from 0 to 1, two questions' words must be
+	// to be reported (near-duplicate-questions).
+	Similarity float64 `yaml:"similarity,omitempty" json:"similarity,omitempty"`
+	// MaxLength is the longest an option can be, in characters
+	// (long-options).
+	MaxLength int `yaml:"max_length,omitempty" json:"max_length,omitempty"`
+}
+
+var ruleFields = []string{"severity", "max_share", "min_questions", "similarity", "max_length"}
+
+// UnmarshalYAML also accepts a bare severity, such as "all-of-the-above:
+// off", and rejects misspelt limits.
+func (r *RuleConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		r.Severity = Severity(node.Value)
+		return nil
+	}
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if !slices.Contains(ruleFields, key.Value) {
+				return fmt.Errorf("line %d: unknown setting %q, want one of %s", key.Line, key.Value, strings.Join(ruleFields, ", "))
+			}
+		}
+	}
+	type plain RuleConfig
+	return node.Decode((*plain)(r))
+}
+
+// Config is the severity and limits of every rule, by name.
+type Config struct {
+	Rules map[string]RuleConfig `yaml:"rules" json:"rules"`
+}
+
+// DefaultConfig turns every rule on with its default severity and limits.
+func DefaultConfig() Config {
+	c := Config{Rules: make(map[string]RuleConfig, len(rules))}
+	for _, r := range rules {
+		c.Rules[r.name] = r.defaults
+	}
+	return c
+}
+
+// LoadConfig reads a configuration file. Rules it leaves out, and settings
+// a rule leaves out, keep their defaults. An empty path is the default
+// configuration.
+func LoadConfig(path string) (Config, error) {
+	c := DefaultConfig()
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+
+	var file Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err = dec.Decode(&file)
+	if err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for name, set := range file.Rules {
+		current, ok := c.Rules[name]
+		if !ok {
+			return c, fmt.Errorf("%s: unknown rule %q", path, name)
+		}
+		if set.Severity != "" {
+			if !validSeverity(set.Severity) {
+				return c, fmt.Errorf("%s: %s: severity must be error, warning, info or off", path, name)
+			}
+			current.Severity = set.Severity
+		}
+		if set.MaxShare < 0 || set.MaxShare > 1 || set.Similarity < 0 || set.Similarity > 1 {
+			return c, fmt.Errorf("%s: %s: max_share and similarity must be between 0 and 1", path, name)
+		}
+		if set.MaxShare != 0 {
+			current.MaxShare = set.MaxShare
+		}
+		if set.MinQuestions != 0 {
+			current.MinQuestions = set.MinQuestions
+		}
+		if set.Similarity != 0 {
+			current.Similarity = set.Similarity
+		}
+		if set.MaxLength != 0 {
+			current.MaxLength = set.MaxLength
+		}
+		c.Rules[name] = current
+	}
+
+	return c, nil
+}
+
+// Quiz runs every rule that is on over a quiz. Findings are in question
+// order, with findings about the whole quiz first.
+func Quiz(q quizfile.Quiz, c Config) []Finding {
+	var findings []Finding
+	for _, r := range rules {
+		set, ok := c.Rules[r.name]
+		if !ok {
+			set = r.defaults
+		}
+		if set.Severity == Off {
+			continue
+		}
+
+		report := func(number int, format string, args ...any) {
+			f := Finding{
+				Rule:     r.name,
+				Severity: set.Severity,
+				Quiz:     q.Slug,
+				File:     q.Path,
+				Number:   number,
+				Message:  fmt.Sprintf(format, args...),
+			}
+			if number > 0 {
+				f.Question = q.Questions[number-1].ID
+			}
+			findings = append(findings, f)
+		}
+		r.check(q, set, report)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Number < findings[j].Number })
+	return findings
+}
+
+// Count returns the number of findings with the given severity.
+func Count(findings []Finding, s Severity) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity == s {
+			n++
+		}
+	}
+	return n
+}
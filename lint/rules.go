@@ -0,0 +1,198 @@
+package lint
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+)
+
+var letters = []string{"A", "B", "C", "D"}
+
+// rule checks a quiz, reporting findings against question numbers from 1,
+// or 0 for the whole quiz.
+type rule struct {
+	name     string
+	defaults RuleConfig
+	check    func(q quizfile.Quiz, c RuleConfig, report func(number int, format string, args ...any))
+}
+
+// rules are run in this order.
+var rules = []rule{
+	{"blank-options", RuleConfig{Severity: Error}, blankOptions},
+	{"duplicate-options", RuleConfig{Severity: Error}, duplicateOptions},
+	{"all-of-the-above", RuleConfig{Severity: Warning}, allOfTheAbove},
+	{"long-options", RuleConfig{Severity: Warning, MaxLength: 150}, longOptions},
+	{"answer-balance", RuleConfig{Severity: Warning, MaxShare: 0.5, MinQuestions: 8}, answerBalance},
+	{"near-duplicate-questions", RuleConfig{Severity: Warning, Similarity: 0.8}, nearDuplicates},
+}
+
+// options returns a question's filled options by letter.
+func options(q quizfile.Question) map[string]string {
+	filled := make(map[string]string)
+	for _, l := range letters {
+		if text := q.Option(l); text != "" {
+			filled[l] = text
+		}
+	}
+	return filled
+}
+
+// placeholders are distractors that were never written. Short words that
+// can be real answers, such as "x" in algebra or "Na" in chemistry, are
+// left out, since blank options are an error.
+var placeholders = map[string]bool{
+	"": true, "-": true, "--": true, "?": true, "...": true, "xxx": true,
+	"n/a": true, "tbd": true, "todo": true, "placeholder": true,
+}
+
+var numberedOption = regexp.MustCompile(`^(option|answer|choice) ?[a-d1-4]$`)
+
+// blankOptions reports options that are whitespace or a placeholder, such
+// as "TBD" or "Option C".
+func blankOptions(q quizfile.Quiz, _ RuleConfig, report func(int, string, ...any)) {
+	for i, question := range q.Questions {
+		for _, l := range letters {
+			text := question.Option(l)
+			if text == "" {
+				continue
+			}
+			key := strings.ToLower(strings.TrimSpace(text))
+			if placeholders[key] || numberedOption.MatchString(key) {
+				report(i+1, "option %s %q is blank or a placeholder", l, text)
+			}
+		}
+	}
+}
+
+// duplicateOptions reports options that read the same, ignoring case,
+// spacing and closing punctuation.
+func duplicateOptions(q quizfile.Quiz, _ RuleConfig, report func(int, string, ...any)) {
+	for i, question := range q.Questions {
+		seen := make(map[string]string)
+		for _, l := range letters {
+			text := question.Option(l)
+			if text == "" {
+				continue
+			}
+			key := strings.TrimRight(normalize(text), ".!?;:")
+			if first, ok := seen[key]; ok {
+				report(i+1, "options %s and %s are the same: %q", first, l, text)
+				continue
+			}
+			seen[key] = l
+		}
+	}
+}
+
+var aboveOption = regexp.MustCompile(`^(all|none|both|neither) (of )?(the )?above\.?$`)
+
+// allOfTheAbove reports "all of the above" style options that are not the
+// last option, that sit next to their opposite, or that are one of only two
+// options.
+func allOfTheAbove(q quizfile.Quiz, _ RuleConfig, report func(int, string, ...any)) {
+	for i, question := range q.Questions {
+		filled := options(question)
+		var above []string
+		last := ""
+		for _, l := range letters {
+			if _, ok := filled[l]; ok {
+				last = l
+			}
+			if aboveOption.MatchString(normalize(filled[l])) {
+				above = append(above, l)
+			}
+		}
+		if len(above) == 0 {
+			continue
+		}
+
+		switch {
+		case len(filled) == 2:
+			report(i+1, "%q needs other options above it, but the question only has two", filled[above[0]])
+		case len(above) > 1:
+			report(i+1, "options %s and %s both refer to the options above", above[0], above[1])
+		case above[0] != last:
+			report(i+1, "%q is option %s, it must be the last option (%s)", filled[above[0]], above[0], last)
+		}
+	}
+}
+
+// longOptions reports options longer than the rule's max_length.
+func longOptions(q quizfile.Quiz, c RuleConfig, report func(int, string, ...any)) {
+	for i, question := range q.Questions {
+		for _, l := range letters {
+			if n := utf8.RuneCountInString(question.Option(l)); n > c.MaxLength {
+				report(i+1, "option %s is %d characters long, the limit is %d", l, n, c.MaxLength)
+			}
+		}
+	}
+}
+
+// answerBalance reports a letter that is the correct answer to more than
+// max_share of the quiz's questions, which players learn to guess.
+func answerBalance(q quizfile.Quiz, c RuleConfig, report func(int, string, ...any)) {
+	if len(q.Questions) < c.MinQuestions {
+		return
+	}
+	counts := make(map[string]int)
+	for _, question := range q.Questions {
+		counts[question.CorrectAnswer]++
+	}
+	for _, l := range letters {
+		share := float64(counts[l]) / float64(len(q.Questions))
+		if share > c.MaxShare {
+			report(0, "%s is the correct answer to %d of %d questions (%.0f%%), spread answers across the options", l, counts[l], len(q.Questions), share*100)
+		}
+	}
+}
+
+// nearDuplicates reports pairs of questions whose words are at least the
+// rule's similarity alike, measured as the share of their distinct words
+// they have in common. The later question of a pair is reported.
+func nearDuplicates(q quizfile.Quiz, c RuleConfig, report func(int, string, ...any)) {
+	words := make([]map[string]bool, len(q.Questions))
+	for i, question := range q.Questions {
+		words[i] = wordSet(question.QuestionText)
+	}
+
+	for j := range q.Questions {
+		for i := range j {
+			if s := similarity(words[i], words[j]); s >= c.Similarity {
+				report(j+1, "question is %.0f%% similar to question %d (%s)", s*100, i+1, q.Questions[i].ID)
+				break
+			}
+		}
+	}
+}
+
+func wordSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		set[w] = true
+	}
+	return set
+}
+
+// similarity is the Jaccard index of two word sets.
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// normalize ignores case and spacing.
+func normalize(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
@@ -0,0 +1,345 @@
+package moodle
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+)
+
+// Converted is a question converted to a quiz question, with the line it
+// was read from.
+type Converted struct {
+	Line     int
+	Question quizfile.Question
+}
+
+var letters = []string{"A", "B", "C", "D"}
+
+// ToQuizfile maps questions onto quiz questions, which have two to four
+// options and one right answer. Multiple choice questions that fit, and
+// true/false questions, are converted. Short answer and numerical questions
+// become multiple choice, with wrong options taken from the bank or
+// generated around the answer, and are reported so the options can be
+// checked. Questions that do not fit are skipped, and details that are
+// dropped, such as partial credit, reported.
+//
+// Question IDs are made from the question names, numbered when names repeat,
+// so the same file imported again updates the same questions.
+func ToQuizfile(questions []Question) ([]Converted, []Issue) {
+	var converted []Converted
+	var issues []Issue
+	ids := make(map[string]int)
+	categories := false
+	accepted := shortAnswers(questions)
+
+	for _, q := range questions {
+		question, problems, ok := q.quizQuestion(accepted)
+		issues = append(issues, problems...)
+		if !ok {
+			continue
+		}
+
+		if q.Category != "" && !categories {
+			categories = true
+			issues = append(issues, issue(q, "question categories are not kept, tag the questions instead"))
+		}
+
+		name := q.Name
+		if name == "" {
+			name = q.Text
+		}
+		id := quizfile.QuestionID(name)
+		ids[id]++
+		if n := ids[id]; n > 1 {
+			suffix := fmt.Sprintf("-%d", n)
+			if len(id)+len(suffix) > quizfile.MaxIDLength {
+				id = strings.TrimRight(id[:quizfile.MaxIDLength-len(suffix)], "-")
+			}
+			id += suffix
+		}
+		question.ID = id
+
+		converted = append(converted, Converted{Line: q.Line, Question: question})
+	}
+
+	return converted, issues
+}
+
+// quizQuestion converts a question. accepted are the right answers of the
+// bank's short answer questions, which other short answer questions borrow
+// as wrong options.
+func (q Question) quizQuestion(accepted []string) (quizfile.Question, []Issue, bool) {
+	var issues []Issue
+	report := func(format string, args ...any) {
+		issues = append(issues, issue(q, format, args...))
+	}
+
+	question := quizfile.Question{
+		QuestionText: q.Text,
+		Points:       q.Points,
+		Explanation:  q.Feedback,
+		Hints:        q.Hints,
+		Tags:         q.Tags,
+	}
+
+	switch q.Kind {
+	case ShortAnswer, Numerical:
+		choices, right, problem := q.typedOptions(accepted)
+		if problem != "" {
+			report("%s", problem)
+			return question, issues, false
+		}
+		options := []*string{&question.OptionA, &question.OptionB, &question.OptionC, &question.OptionD}
+		var wrong []string
+		for i, choice := range choices {
+			*options[i] = choice
+			if i != right {
+				wrong = append(wrong, strconv.Quote(choice))
+			}
+		}
+		question.CorrectAnswer = letters[right]
+		if len(q.Answers) > 1 {
+			report("only the right option earns points, the question's other answers and their credit are dropped")
+		}
+		report("%s question becomes multiple choice with %q as the right option and %s as wrong options, check them",
+			q.Kind, choices[right], strings.Join(wrong, ", "))
+	case TrueFalse:
+		question.OptionA, question.OptionB = "True", "False"
+		for _, a := range q.Answers {
+			if a.Fraction > 0 {
+				question.CorrectAnswer = "B"
+				if a.Text == "true" {
+					question.CorrectAnswer = "A"
+				}
+			}
+		}
+	case MultiChoice:
+		if !q.Single {
+			report("questions with several right answers are not supported")
+			return question, issues, false
+		}
+		if len(q.Answers) < 2 || len(q.Answers) > len(letters) {
+			report("has %d answers, quiz questions have 2 to %d", len(q.Answers), len(letters))
+			return question, issues, false
+		}
+
+		options := []*string{&question.OptionA, &question.OptionB, &question.OptionC, &question.OptionD}
+		partial := false
+		for i, a := range q.Answers {
+			*options[i] = a.Text
+			switch {
+			case a.Fraction == 100 && question.CorrectAnswer == "":
+				question.CorrectAnswer = letters[i]
+			case a.Fraction != 0:
+				partial = true
+			}
+		}
+		if question.CorrectAnswer == "" {
+			report("has no answer worth full marks")
+			return question, issues, false
+		}
+		if partial {
+			report("partial credit and penalties on single answers are dropped, use the quiz's wrong answer penalty")
+		}
+	default:
+		report("%s questions are not supported", q.Kind)
+		return question, issues, false
+	}
+
+	// Quiz questions have one explanation, so answer feedback only survives
+	// as the explanation when the question has no general feedback
+	feedback := false
+	for _, a := range q.Answers {
+		if a.Feedback == "" {
+			continue
+		}
+		if question.Explanation == "" && a.Fraction == 100 {
+			question.Explanation = a.Feedback
+			continue
+		}
+		feedback = true
+	}
+	if feedback {
+		report("feedback on individual answers is dropped")
+	}
+
+	if question.Points == 0 {
+		question.Points = 1
+	}
+	return question, issues, true
+}
+
+// maxWrong is how many wrong options a typed answer gets, besides the right
+// one.
+const maxWrong = 3
+
+// typedOptions turns a short answer or numerical question into options and
+// the index of the right one. Short answer questions take their own wrong
+// answers first, then the right answers of other short answer questions,
+// and list the options alphabetically. Numerical questions get numbers
+// around the answer, outside its tolerance, in order. It returns why when
+// no options can be made.
+func (q Question) typedOptions(accepted []string) ([]string, int, string) {
+	var right *Answer
+	for i, a := range q.Answers {
+		if a.Fraction == 100 {
+			right = &q.Answers[i]
+			break
+		}
+	}
+	if right == nil || right.Text == "" {
+		return nil, 0, "has no answer worth full marks"
+	}
+
+	if q.Kind == Numerical {
+		options, i := numericalOptions(q.Name, *right)
+		return options, i, ""
+	}
+
+	if strings.Contains(right.Text, "*") {
+		return nil, 0, fmt.Sprintf("the right answer %q uses a wildcard, which cannot be shown as an option", right.Text)
+	}
+	// Answers this question accepts, even for part marks, are never wrong
+	taken := []string{}
+	for _, a := range q.Answers {
+		if a.Fraction > 0 {
+			taken = append(taken, strings.ToLower(a.Text))
+		}
+	}
+	var wrong []string
+	add := func(option string) {
+		if len(wrong) < maxWrong && option != "" && !strings.Contains(option, "*") && !slices.Contains(taken, strings.ToLower(option)) {
+			wrong = append(wrong, option)
+			taken = append(taken, strings.ToLower(option))
+		}
+	}
+	for _, a := range q.Answers {
+		if a.Fraction <= 0 {
+			add(a.Text)
+		}
+	}
+	for _, option := range accepted {
+		add(option)
+	}
+	if len(wrong) == 0 {
+		return nil, 0, "shortanswer question has no wrong answers to offer, and the bank no other short answer questions to borrow them from"
+	}
+
+	options := append(wrong, right.Text)
+	slices.SortFunc(options, func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) })
+	return options, slices.Index(options, right.Text), ""
+}
+
+// numericalOptions makes options of a numerical answer and numbers around
+// it, in steps wider than the answer's tolerance, and returns the index of
+// the answer. How many fall below the answer is picked from the question's
+// name, so the right option is not always in the same place.
+func numericalOptions(name string, right Answer) ([]string, int) {
+	value, _ := strconv.ParseFloat(right.Text, 64)
+	decimals := 0
+	if _, fraction, ok := strings.Cut(right.Text, "."); ok {
+		decimals = len(fraction)
+	}
+	unit := math.Pow(10, -float64(decimals))
+	step := unit * (math.Floor(2*right.Tolerance/unit) + 1)
+
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	below := int(h.Sum32() % (maxWrong + 1))
+	// Positive answers keep positive options
+	if value >= 0 {
+		below = min(below, int(math.Floor(value/step+1e-9)))
+	}
+
+	var options []string
+	for i := -below; i <= maxWrong-below; i++ {
+		if i == 0 {
+			options = append(options, right.Text)
+			continue
+		}
+		options = append(options, strconv.FormatFloat(value+float64(i)*step, 'f', decimals, 64))
+	}
+	return options, below
+}
+
+// shortAnswers lists the first right answer of each short answer question.
+func shortAnswers(questions []Question) []string {
+	var out []string
+	for _, q := range questions {
+		if q.Kind != ShortAnswer {
+			continue
+		}
+		for _, a := range q.Answers {
+			if a.Fraction == 100 {
+				out = append(out, a.Text)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// FromQuiz converts a quiz's questions, in a category named after the quiz
+// so Moodle files them together.
+func FromQuiz(quiz quizfile.Quiz) []Question {
+	questions := FromQuizfile(quiz.Questions)
+	for i := range questions {
+		questions[i].Category = "$course$/top/" + quiz.Title
+	}
+	return questions
+}
+
+// FromQuizfile converts quiz questions to Moodle questions. Questions whose
+// options are True and False become true/false questions, and the rest
+// multiple choice.
+func FromQuizfile(questions []quizfile.Question) []Question {
+	out := make([]Question, len(questions))
+	for i, q := range questions {
+		mq := Question{
+			Kind:     MultiChoice,
+			Name:     q.ID,
+			Text:     q.QuestionText,
+			Feedback: q.Explanation,
+			Points:   q.Points,
+			Single:   true,
+			Hints:    q.Hints,
+			Tags:     q.Tags,
+		}
+
+		if isTrueFalseOptions(q) {
+			mq.Kind = TrueFalse
+			mq.Answers = []Answer{{Text: "true"}, {Text: "false"}}
+			if q.CorrectAnswer == "A" {
+				mq.Answers[0].Fraction = 100
+			} else {
+				mq.Answers[1].Fraction = 100
+			}
+			out[i] = mq
+			continue
+		}
+
+		for _, letter := range letters {
+			option := q.Option(letter)
+			if option == "" {
+				continue
+			}
+			answer := Answer{Text: option}
+			if letter == q.CorrectAnswer {
+				answer.Fraction = 100
+			}
+			mq.Answers = append(mq.Answers, answer)
+		}
+		out[i] = mq
+	}
+	return out
+}
+
+func isTrueFalseOptions(q quizfile.Question) bool {
+	return strings.EqualFold(q.OptionA, "true") && strings.EqualFold(q.OptionB, "false") &&
+		q.OptionC == "" && q.OptionD == ""
+}
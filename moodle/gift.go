@@ -0,0 +1,561 @@
+package moodle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// giftSpecial are the characters GIFT escapes with a backslash.
+const giftSpecial = `~=#{}:`
+
+// ReadGIFT reads the questions of a GIFT file. Matching, essay and
+// description questions are skipped and reported. GIFT has no points or
+// hints, so questions read from it have neither; tags are read from Moodle's
+// "// [tag:name]" comments.
+func ReadGIFT(r io.Reader) ([]Question, []Issue, error) {
+	var questions []Question
+	var issues []Issue
+	category := ""
+
+	for _, block := range giftBlocks(r) {
+		if block.err != nil {
+			return nil, nil, block.err
+		}
+
+		if strings.HasPrefix(block.text, "$CATEGORY:") {
+			first, rest, _ := strings.Cut(block.text, "\n")
+			category = strings.TrimSpace(strings.TrimPrefix(first, "$CATEGORY:"))
+			if strings.TrimSpace(rest) == "" {
+				continue
+			}
+			// The question follows without a blank line
+			block.text = strings.TrimSpace(rest)
+			block.line++
+		}
+
+		q, problems := parseGIFT(block)
+		issues = append(issues, problems...)
+		if q == nil {
+			continue
+		}
+		q.Category = category
+		questions = append(questions, *q)
+	}
+
+	return questions, issues, nil
+}
+
+type giftBlock struct {
+	line int
+	text string
+	tags []string
+	err  error
+}
+
+// giftBlocks splits a GIFT file into questions, which are separated by blank
+// lines. Comment lines are dropped, apart from Moodle's tag comments.
+func giftBlocks(r io.Reader) []giftBlock {
+	var blocks []giftBlock
+	var current giftBlock
+	var lines []string
+
+	flush := func() {
+		if len(lines) > 0 {
+			current.text = strings.TrimSpace(strings.Join(lines, "\n"))
+			blocks = append(blocks, current)
+		}
+		current = giftBlock{}
+		lines = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := scanner.Text()
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "//"):
+			if current.line == 0 {
+				current.line = n
+			}
+			current.tags = append(current.tags, giftTags(trimmed)...)
+		default:
+			if len(lines) == 0 && current.line == 0 {
+				current.line = n
+			}
+			lines = append(lines, line)
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		blocks = append(blocks, giftBlock{err: err})
+	}
+	return blocks
+}
+
+// giftTags reads the tags of a "// [tag:name] [tag:other]" comment.
+func giftTags(comment string) []string {
+	var tags []string
+	for {
+		start := strings.Index(comment, "[tag:")
+		if start < 0 {
+			return tags
+		}
+		comment = comment[start+len("[tag:"):]
+		end := strings.Index(comment, "]")
+		if end < 0 {
+			return tags
+		}
+		if tag := strings.TrimSpace(comment[:end]); tag != "" {
+			tags = append(tags, tag)
+		}
+		comment = comment[end+1:]
+	}
+}
+
+func parseGIFT(block giftBlock) (*Question, []Issue) {
+	q := Question{Single: true, Tags: block.tags, Line: block.line}
+	s := block.text
+
+	var issues []Issue
+	report := func(format string, args ...any) {
+		issues = append(issues, issue(q, format, args...))
+	}
+
+	if strings.HasPrefix(s, "::") {
+		end := indexUnescaped(s[2:], "::")
+		if end >= 0 {
+			q.Name = strings.TrimSpace(unescapeGIFT(s[2 : 2+end]))
+			s = s[2+end+2:]
+		}
+	}
+
+	start := indexUnescaped(s, "{")
+	if start < 0 {
+		if q.Name == "" {
+			q.Name = shorten(unescapeGIFT(s))
+		}
+		report("description questions, which have no answers, are not supported")
+		return nil, issues
+	}
+	end := indexUnescaped(s[start:], "}")
+	if end < 0 {
+		if q.Name == "" {
+			q.Name = shorten(unescapeGIFT(s))
+		}
+		report("the answers are missing a closing }")
+		return nil, issues
+	}
+	end += start
+
+	before, answers, after := s[:start], s[start+1:end], s[end+1:]
+	text, images := giftText(before)
+	if rest, more := giftText(after); rest != "" {
+		// Missing word questions have the answers in the middle of the text
+		text += " _____ " + rest
+		images = images || more
+	}
+	q.Text = text
+	if q.Name == "" {
+		q.Name = shorten(q.Text)
+	}
+	if images {
+		report("embedded images are dropped")
+	}
+
+	// General feedback follows ####
+	if i := indexUnescaped(answers, "####"); i >= 0 {
+		q.Feedback = strings.TrimSpace(unescapeGIFT(answers[i+4:]))
+		answers = answers[:i]
+	}
+	answers = strings.TrimSpace(answers)
+
+	switch {
+	case answers == "":
+		report("essay questions are not supported")
+		return nil, issues
+	case strings.HasPrefix(answers, "#"):
+		q.Kind = Numerical
+		err := q.parseNumerical(answers[1:])
+		if err != nil {
+			report("%s", err)
+			return nil, issues
+		}
+	case isTrueFalse(answers):
+		q.Kind = TrueFalse
+		q.parseTrueFalse(answers)
+	default:
+		for _, part := range splitAnswers(answers) {
+			if indexUnescaped(part.text, "->") >= 0 {
+				report("matching questions are not supported")
+				return nil, issues
+			}
+			text, feedback := splitFeedback(part.text)
+			fraction, text := giftFraction(text)
+			if fraction == nil {
+				value := 0.0
+				if part.mark == '=' {
+					value = 100
+				}
+				fraction = &value
+			}
+			q.Answers = append(q.Answers, Answer{Text: text, Fraction: *fraction, Feedback: feedback})
+		}
+
+		// Only right answers make a short answer question, and only
+		// weighted wrong ones a question where several answers are chosen
+		marks := markers(splitAnswers(answers))
+		q.Kind = MultiChoice
+		if !strings.Contains(marks, "~") {
+			q.Kind = ShortAnswer
+		} else if !strings.Contains(marks, "=") {
+			q.Single = false
+		}
+	}
+
+	return &q, issues
+}
+
+// giftText converts the question text of a GIFT question, which may start
+// with a format such as [html], to plain text.
+func giftText(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	format := ""
+	if strings.HasPrefix(s, "[") {
+		if end := strings.Index(s, "]"); end > 0 {
+			format = s[1:end]
+			s = s[end+1:]
+		}
+	}
+
+	s = unescapeGIFT(strings.TrimSpace(s))
+	if format != "html" {
+		return s, false
+	}
+	return plainText(s)
+}
+
+func isTrueFalse(s string) bool {
+	value, _ := splitFeedback(s)
+	switch value {
+	case "T", "TRUE", "F", "FALSE":
+		return true
+	}
+	return false
+}
+
+// parseTrueFalse reads {T}, {FALSE#wrong#right} and so on. The first
+// feedback is shown for the wrong answer and the second for the right one.
+func (q *Question) parseTrueFalse(s string) {
+	parts := splitUnescaped(s, "#")
+	correct := strings.TrimSpace(parts[0])
+	isTrue := correct == "T" || correct == "TRUE"
+
+	var wrong, right string
+	if len(parts) > 1 {
+		wrong = strings.TrimSpace(unescapeGIFT(parts[1]))
+	}
+	if len(parts) > 2 {
+		right = strings.TrimSpace(unescapeGIFT(parts[2]))
+	}
+
+	t := Answer{Text: "true", Feedback: wrong}
+	f := Answer{Text: "false", Feedback: wrong}
+	if isTrue {
+		t.Fraction, t.Feedback = 100, right
+	} else {
+		f.Fraction, f.Feedback = 100, right
+	}
+	q.Answers = []Answer{t, f}
+}
+
+// parseNumerical reads the answers of a numerical question, such as
+// {#42:0.5}, {#1..5} or {#=42:0 =%50%42:2#close}.
+func (q *Question) parseNumerical(s string) error {
+	parts := splitAnswers(s)
+	if len(parts) == 0 || len(parts) == 1 && parts[0].mark == 0 {
+		parts = []answerPart{{mark: '=', text: s}}
+	}
+
+	for _, part := range parts {
+		text, feedback := splitFeedback(part.text)
+		fraction, text := giftFraction(text)
+		answer := Answer{Fraction: 100, Feedback: feedback}
+		if fraction != nil {
+			answer.Fraction = *fraction
+		}
+
+		if lo, hi, ok := strings.Cut(text, ".."); ok {
+			min, err1 := strconv.ParseFloat(strings.TrimSpace(lo), 64)
+			max, err2 := strconv.ParseFloat(strings.TrimSpace(hi), 64)
+			if err1 != nil || err2 != nil {
+				return fmt.Errorf("numerical range %q is not two numbers", text)
+			}
+			answer.Text = strconv.FormatFloat((min+max)/2, 'f', -1, 64)
+			answer.Tolerance = (max - min) / 2
+		} else {
+			value, tolerance, _ := strings.Cut(text, ":")
+			_, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return fmt.Errorf("numerical answer %q is not a number", text)
+			}
+			answer.Text = strings.TrimSpace(value)
+			if tolerance != "" {
+				answer.Tolerance, err = strconv.ParseFloat(strings.TrimSpace(tolerance), 64)
+				if err != nil {
+					return fmt.Errorf("numerical tolerance %q is not a number", tolerance)
+				}
+			}
+		}
+
+		q.Answers = append(q.Answers, answer)
+	}
+
+	return nil
+}
+
+type answerPart struct {
+	mark byte
+	text string
+}
+
+// splitAnswers splits answers at their unescaped = and ~ marks.
+func splitAnswers(s string) []answerPart {
+	var parts []answerPart
+	var mark byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '=', '~':
+			if text := strings.TrimSpace(s[start:i]); text != "" || mark != 0 {
+				parts = append(parts, answerPart{mark: mark, text: text})
+			}
+			mark = s[i]
+			start = i + 1
+		}
+	}
+	if text := strings.TrimSpace(s[start:]); text != "" || mark != 0 {
+		parts = append(parts, answerPart{mark: mark, text: text})
+	}
+	return parts
+}
+
+func markers(parts []answerPart) string {
+	var b strings.Builder
+	for _, p := range parts {
+		b.WriteByte(p.mark)
+	}
+	return b.String()
+}
+
+// splitFeedback splits an answer from its #feedback.
+func splitFeedback(s string) (string, string) {
+	parts := splitUnescaped(s, "#")
+	text := strings.TrimSpace(parts[0])
+	feedback := ""
+	if len(parts) > 1 {
+		feedback = strings.TrimSpace(unescapeGIFT(strings.Join(parts[1:], "#")))
+	}
+	return unescapeGIFT(text), feedback
+}
+
+// giftFraction reads the %50% weight that may start an answer.
+func giftFraction(s string) (*float64, string) {
+	if !strings.HasPrefix(s, "%") {
+		return nil, s
+	}
+	end := strings.Index(s[1:], "%")
+	if end < 0 {
+		return nil, s
+	}
+	value, err := strconv.ParseFloat(s[1:1+end], 64)
+	if err != nil {
+		return nil, s
+	}
+	return &value, strings.TrimSpace(s[end+2:])
+}
+
+// indexUnescaped finds sep in s, skipping escaped characters.
+func indexUnescaped(s, sep string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sep) {
+			return i
+		}
+	}
+	return -1
+}
+
+func splitUnescaped(s, sep string) []string {
+	var parts []string
+	for {
+		i := indexUnescaped(s, sep)
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i])
+		s = s[i+len(sep):]
+	}
+}
+
+func unescapeGIFT(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func escapeGIFT(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\\' || strings.ContainsRune(giftSpecial, r):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// shorten makes a question name from its text, as Moodle does for GIFT
+// questions without a title.
+func shorten(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > 40 {
+		return string(r[:40]) + "…"
+	}
+	return s
+}
+
+// WriteGIFT writes questions as a GIFT file. GIFT cannot hold points or
+// hints, so questions that have them are reported. The file is built in
+// memory and written at once, so a failed write is its only error.
+func WriteGIFT(w io.Writer, questions []Question) ([]Issue, error) {
+	var issues []Issue
+	var bw strings.Builder
+
+	category := ""
+	for _, q := range questions {
+		if q.Category != "" && q.Category != category {
+			category = q.Category
+			fmt.Fprintf(&bw, "$CATEGORY: %s\n\n", category)
+		}
+
+		if len(q.Hints) > 0 {
+			issues = append(issues, issue(q, "GIFT has no hints, %d dropped", len(q.Hints)))
+		}
+		if q.Points != 0 && q.Points != 1 {
+			issues = append(issues, issue(q, "GIFT has no points, %g points dropped", q.Points))
+		}
+
+		if len(q.Tags) > 0 {
+			bw.WriteString("//")
+			for _, t := range q.Tags {
+				fmt.Fprintf(&bw, " [tag:%s]", t)
+			}
+			bw.WriteString("\n")
+		}
+
+		fmt.Fprintf(&bw, "::%s::%s {", escapeGIFT(q.Name), escapeGIFT(q.Text))
+		switch q.Kind {
+		case TrueFalse:
+			writeTrueFalse(&bw, q)
+		case Numerical:
+			bw.WriteString("#")
+			for _, a := range q.Answers {
+				bw.WriteString("\n\t=")
+				writeFraction(&bw, a, 100)
+				fmt.Fprintf(&bw, "%s:%s", a.Text, strconv.FormatFloat(a.Tolerance, 'f', -1, 64))
+				writeFeedback(&bw, a.Feedback)
+			}
+			bw.WriteString("\n")
+		default:
+			for _, a := range q.Answers {
+				switch {
+				case q.Kind == ShortAnswer || q.Single && a.Fraction == 100:
+					bw.WriteString("\n\t=")
+					writeFraction(&bw, a, 100)
+				default:
+					bw.WriteString("\n\t~")
+					writeFraction(&bw, a, 0)
+				}
+				bw.WriteString(escapeGIFT(a.Text))
+				writeFeedback(&bw, a.Feedback)
+			}
+			bw.WriteString("\n")
+		}
+		if q.Feedback != "" {
+			fmt.Fprintf(&bw, "\t####%s\n", escapeGIFT(q.Feedback))
+		}
+		bw.WriteString("}\n\n")
+	}
+
+	_, err := io.WriteString(w, bw.String())
+	return issues, err
+}
+
+func writeTrueFalse(w *strings.Builder, q Question) {
+	var right, wrong string
+	isTrue := false
+	for _, a := range q.Answers {
+		if a.Fraction > 0 {
+			right = a.Feedback
+			isTrue = a.Text == "true"
+		} else {
+			wrong = a.Feedback
+		}
+	}
+
+	if isTrue {
+		w.WriteString("TRUE")
+	} else {
+		w.WriteString("FALSE")
+	}
+	if wrong != "" || right != "" {
+		fmt.Fprintf(w, "#%s#%s", escapeGIFT(wrong), escapeGIFT(right))
+	}
+}
+
+// writeFraction writes an answer's weight, unless it is the one its mark
+// already implies.
+func writeFraction(w *strings.Builder, a Answer, implied float64) {
+	if a.Fraction != implied {
+		fmt.Fprintf(w, "%%%s%%", strconv.FormatFloat(a.Fraction, 'f', -1, 64))
+	}
+}
+
+func writeFeedback(w *strings.Builder, feedback string) {
+	if feedback != "" {
+		fmt.Fprintf(w, "#%s", escapeGIFT(feedback))
+	}
+}
@@ -0,0 +1,113 @@
+// Package moodle reads and writes question banks in Moodle's two exchange
+// formats, Moodle XML and GIFT, so banks can be migrated from Moodle.
+//
+// Both formats are read into Question, which covers the multiple choice,
+// true/false, short answer and numerical question types. Other question
+// types, and details of supported ones that Question cannot hold, are
+// reported as issues instead of being dropped silently. ToQuizfile then maps
+// the questions onto quiz questions, reporting those that do not fit.
+package moodle
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Kind is a Moodle question type.
+type Kind string
+
+// Supported question types.
+const (
+	MultiChoice Kind = "multichoice"
+	TrueFalse   Kind = "truefalse"
+	ShortAnswer Kind = "shortanswer"
+	Numerical   Kind = "numerical"
+)
+
+// Question is a question of a Moodle question bank.
+type Question struct {
+	Kind Kind
+	Name string
+	Text string
+	// Feedback is shown after the question is answered, whatever the answer.
+	Feedback string
+	// Points is the question's default grade, or 0 when the format has none.
+	Points float64
+	// Single is false for multiple choice questions that allow several
+	// answers to be chosen.
+	Single   bool
+	Answers  []Answer
+	Hints    []string
+	Tags     []string
+	Category string
+
+	// Line is where the question starts in the file it was read from.
+	Line int
+}
+
+// Answer is an answer of a question. True/false questions have the answers
+// "true" and "false".
+type Answer struct {
+	Text string
+	// Fraction is the percentage of the question's points the answer earns,
+	// which can be negative.
+	Fraction float64
+	Feedback string
+	// Tolerance is how far a numerical answer may be from Text and still
+	// earn the answer's points.
+	Tolerance float64
+}
+
+// Issue is something in a file that could not be read, converted or written,
+// with the line of the question it concerns when there is one.
+type Issue struct {
+	Line     int    `json:"line"`
+	Question string `json:"question,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (i Issue) Error() string {
+	s := i.Message
+	if i.Question != "" {
+		s = i.Question + ": " + s
+	}
+	if i.Line > 0 {
+		s = fmt.Sprintf("line %d: %s", i.Line, s)
+	}
+	return s
+}
+
+func issue(q Question, format string, args ...any) Issue {
+	return Issue{Line: q.Line, Question: q.Name, Message: fmt.Sprintf(format, args...)}
+}
+
+var (
+	lineBreak = regexp.MustCompile(`(?i)<br\s*/?>|</p>`)
+	tag       = regexp.MustCompile(`<[^>]*>`)
+	image     = regexp.MustCompile(`(?i)<img[\s>]`)
+)
+
+// plainText converts Moodle's HTML text to plain text, keeping line breaks.
+// It reports whether the text had images, which are dropped.
+func plainText(s string) (string, bool) {
+	hadImage := image.MatchString(s)
+	s = lineBreak.ReplaceAllString(s, "\n")
+	s = tag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), hadImage
+}
+
+// htmlText converts plain text to the HTML that plainText reads back.
+func htmlText(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "<p>" + strings.ReplaceAll(html.EscapeString(s), "\n", "<br>") + "</p>"
+}
@@ -0,0 +1,373 @@
+package moodle
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+)
+
+type reader func(io.Reader) ([]Question, []Issue, error)
+
+func writeGIFT(w io.Writer, questions []Question) error {
+	_, err := WriteGIFT(w, questions)
+	return err
+}
+
+func readFile(t *testing.T, path string, read reader) ([]Question, []Issue) {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	questions, issues, err := read(f)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return questions, issues
+}
+
+func messages(issues []Issue) []string {
+	out := make([]string, len(issues))
+	for i, issue := range issues {
+		out[i] = issue.Error()
+	}
+	return out
+}
+
+// withoutLines clears the lines questions were read from, which change
+// when a file is written again.
+func withoutLines(questions []Question) []Question {
+	out := make([]Question, len(questions))
+	for i, q := range questions {
+		q.Line = 0
+		out[i] = q
+	}
+	return out
+}
+
+func TestReadReportsUnsupported(t *testing.T) {
+	tests := []struct {
+		path  string
+		read  reader
+		kinds []Kind
+		want  []string
+	}{
+		{
+			path:  "testdata/sample.xml",
+			read:  ReadXML,
+			kinds: []Kind{MultiChoice, MultiChoice, TrueFalse, ShortAnswer, Numerical, MultiChoice, MultiChoice, ShortAnswer},
+			want: []string{
+				"line 162: Climate essay: essay questions are not supported",
+				"line 172: Flag: embedded images and files are dropped",
+			},
+		},
+		{
+			path:  "testdata/sample.gift",
+			read:  ReadGIFT,
+			kinds: []Kind{MultiChoice, MultiChoice, TrueFalse, TrueFalse, ShortAnswer, Numerical, Numerical, MultiChoice, MultiChoice},
+			want: []string{
+				"line 39: Climate essay: essay questions are not supported",
+				"line 41: Pairs: matching questions are not supported",
+				"line 46: Welcome: description questions, which have no answers, are not supported",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			questions, issues := readFile(t, tt.path, tt.read)
+
+			var kinds []Kind
+			for _, q := range questions {
+				kinds = append(kinds, q.Kind)
+			}
+			if !reflect.DeepEqual(kinds, tt.kinds) {
+				t.Errorf("kinds = %v, want %v", kinds, tt.kinds)
+			}
+			if got := messages(issues); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("issues = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadGIFT(t *testing.T) {
+	questions, _ := readFile(t, "testdata/sample.gift", ReadGIFT)
+	byName := make(map[string]Question)
+	for _, q := range questions {
+		byName[q.Name] = q
+	}
+
+	sahara := byName["Sahara"]
+	wantSahara := []Answer{
+		{Text: "true", Feedback: "Antarctica is larger."},
+		{Text: "false", Fraction: 100, Feedback: "Right, Antarctica is larger."},
+	}
+	if !reflect.DeepEqual(sahara.Answers, wantSahara) {
+		t.Errorf("Sahara answers = %+v, want %+v", sahara.Answers, wantSahara)
+	}
+
+	ratio := byName["Ratio"]
+	wantRatio := []Answer{{Text: "1.5", Fraction: 100, Tolerance: 0.5}}
+	if !reflect.DeepEqual(ratio.Answers, wantRatio) {
+		t.Errorf("Ratio answers = %+v, want %+v", ratio.Answers, wantRatio)
+	}
+
+	escapes := byName["Escapes"]
+	if want := "What does 1 = 1 mean in a {set}?"; escapes.Text != want {
+		t.Errorf("Escapes text = %q, want %q", escapes.Text, want)
+	}
+	if want := "Equality: both sides match"; escapes.Answers[0].Text != want {
+		t.Errorf("Escapes answer = %q, want %q", escapes.Answers[0].Text, want)
+	}
+
+	capital := byName["Capital of France"]
+	if want := []string{"europe", "capitals"}; !reflect.DeepEqual(capital.Tags, want) {
+		t.Errorf("Capital of France tags = %q, want %q", capital.Tags, want)
+	}
+	if want := "$course$/top/Geography"; capital.Category != want {
+		t.Errorf("category = %q, want %q", capital.Category, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		path  string
+		read  reader
+		write func(io.Writer, []Question) error
+	}{
+		{"testdata/sample.xml", ReadXML, WriteXML},
+		{"testdata/sample.gift", ReadGIFT, writeGIFT},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			want, _ := readFile(t, tt.path, tt.read)
+
+			var buf bytes.Buffer
+			err := tt.write(&buf, want)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, issues, err := tt.read(&buf)
+			if err != nil {
+				t.Fatalf("reading written file: %v\n%s", err, buf.String())
+			}
+			if len(issues) > 0 {
+				t.Errorf("reading written file reported %q", messages(issues))
+			}
+			if !reflect.DeepEqual(withoutLines(got), withoutLines(want)) {
+				t.Errorf("round trip changed questions\ngot:  %+v\nwant: %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestToQuizfile(t *testing.T) {
+	questions, _ := readFile(t, "testdata/sample.xml", ReadXML)
+	converted, issues := ToQuizfile(questions)
+
+	want := []Converted{
+		{Line: 9, Question: quizfile.Question{
+			ID:            "capital-of-france",
+			QuestionText:  "What is the capital of France?",
+			OptionA:       "Paris",
+			OptionB:       "Lyon",
+			OptionC:       "Marseille",
+			OptionD:       "Nice",
+			CorrectAnswer: "A",
+			Points:        2,
+			Explanation:   "Paris has been the capital since 987.",
+			Hints:         []string{"It is on the Seine."},
+			Tags:          []string{"europe", "capitals"},
+		}},
+		{Line: 58, Question: quizfile.Question{
+			ID:            "longest-river",
+			QuestionText:  "Which river is the longest?",
+			OptionA:       "Nile",
+			OptionB:       "Amazon",
+			OptionC:       "Danube",
+			CorrectAnswer: "A",
+			Points:        1,
+			Explanation:   "Right, at about 6,650 km.",
+		}},
+		{Line: 90, Question: quizfile.Question{
+			ID:            "everest",
+			QuestionText:  "Mount Everest is the highest mountain above sea level.",
+			OptionA:       "True",
+			OptionB:       "False",
+			CorrectAnswer: "A",
+			Points:        1,
+		}},
+		{Line: 115, Question: quizfile.Question{
+			ID:            "largest-ocean",
+			QuestionText:  "Name the largest ocean.",
+			OptionA:       "Arctic",
+			OptionB:       "Pacific",
+			CorrectAnswer: "B",
+			Points:        1,
+		}},
+		{Line: 138, Question: quizfile.Question{
+			ID:            "continents",
+			QuestionText:  "How many continents are there?",
+			OptionA:       "7",
+			OptionB:       "8",
+			OptionC:       "9",
+			OptionD:       "10",
+			CorrectAnswer: "A",
+			Points:        1,
+		}},
+		{Line: 172, Question: quizfile.Question{
+			ID:            "flag",
+			QuestionText:  "Whose flag is this?",
+			OptionA:       "Japan",
+			OptionB:       "Bangladesh",
+			CorrectAnswer: "A",
+			Points:        1,
+		}},
+		{Line: 210, Question: quizfile.Question{
+			ID:            "smallest-ocean",
+			QuestionText:  "Name the smallest ocean.",
+			OptionA:       "Arctic",
+			OptionB:       "Pacific",
+			CorrectAnswer: "A",
+			Points:        1,
+		}},
+	}
+	if !reflect.DeepEqual(converted, want) {
+		t.Errorf("converted\ngot:  %+v\nwant: %+v", converted, want)
+	}
+
+	wantIssues := []string{
+		"line 9: Capital of France: question categories are not kept, tag the questions instead",
+		"line 58: Longest river: partial credit and penalties on single answers are dropped, use the quiz's wrong answer penalty",
+		"line 58: Longest river: feedback on individual answers is dropped",
+		"line 115: Largest ocean: only the right option earns points, the question's other answers and their credit are dropped",
+		`line 115: Largest ocean: shortanswer question becomes multiple choice with "Pacific" as the right option and "Arctic" as wrong options, check them`,
+		"line 138: Continents: only the right option earns points, the question's other answers and their credit are dropped",
+		`line 138: Continents: numerical question becomes multiple choice with "7" as the right option and "8", "9", "10" as wrong options, check them`,
+		"line 138: Continents: feedback on individual answers is dropped",
+		"line 190: Landlocked countries: questions with several right answers are not supported",
+		`line 210: Smallest ocean: shortanswer question becomes multiple choice with "Arctic" as the right option and "Pacific" as wrong options, check them`,
+	}
+	if got := messages(issues); !reflect.DeepEqual(got, wantIssues) {
+		t.Errorf("issues\ngot:  %q\nwant: %q", got, wantIssues)
+	}
+
+	for _, c := range converted {
+		for _, err := range c.Question.Problems() {
+			t.Errorf("line %d: %v", c.Line, err)
+		}
+	}
+}
+
+func TestQuizfileRoundTrip(t *testing.T) {
+	difficulty := int32(2)
+	questions := []quizfile.Question{
+		{
+			ID:            "goroutines",
+			QuestionText:  "What starts a goroutine?",
+			OptionA:       "go",
+			OptionB:       "async",
+			OptionC:       "spawn",
+			OptionD:       "thread",
+			CorrectAnswer: "A",
+			Points:        1,
+			Explanation:   "The go statement runs a call in a new goroutine.",
+			Tags:          []string{"concurrency"},
+		},
+		{
+			ID:            "zero-value",
+			QuestionText:  "What is the zero value of a map?\nAnswer for a declared, unassigned map.",
+			OptionA:       "An empty map",
+			OptionB:       "nil",
+			OptionC:       "{}",
+			CorrectAnswer: "B",
+			Points:        1,
+			Difficulty:    &difficulty,
+		},
+		{
+			ID:            "gofmt",
+			QuestionText:  "gofmt settles formatting debates: key = value & <tags>.",
+			OptionA:       "True",
+			OptionB:       "False",
+			CorrectAnswer: "A",
+			Points:        1,
+		},
+	}
+
+	// Difficulty has no place in either format
+	want := make([]quizfile.Question, len(questions))
+	copy(want, questions)
+	want[1].Difficulty = nil
+
+	formats := []struct {
+		name  string
+		read  reader
+		write func(io.Writer, []Question) error
+	}{
+		{"xml", ReadXML, WriteXML},
+		{"gift", ReadGIFT, writeGIFT},
+	}
+
+	for _, format := range formats {
+		t.Run(format.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := format.write(&buf, FromQuizfile(questions))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			read, issues, err := format.read(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			converted, more := ToQuizfile(read)
+			issues = append(issues, more...)
+			if len(issues) > 0 {
+				t.Errorf("issues: %q", messages(issues))
+			}
+
+			got := make([]quizfile.Question, len(converted))
+			for i, c := range converted {
+				got[i] = c.Question
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip changed questions\ngot:  %+v\nwant: %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteGIFTReportsDropped(t *testing.T) {
+	questions := FromQuizfile([]quizfile.Question{{
+		ID:            "hinted",
+		QuestionText:  "Which is prime?",
+		OptionA:       "4",
+		OptionB:       "7",
+		CorrectAnswer: "B",
+		Points:        3,
+		Hints:         []string{"It is odd."},
+	}})
+
+	issues, err := WriteGIFT(io.Discard, questions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"hinted: GIFT has no hints, 1 dropped",
+		"hinted: GIFT has no points, 3 points dropped",
+	}
+	if got := messages(issues); !reflect.DeepEqual(got, want) {
+		t.Errorf("issues = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,289 @@
+package moodle
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Moodle XML elements, as far as Question needs them.
+type xmlQuiz struct {
+	XMLName   xml.Name      `xml:"quiz"`
+	Questions []xmlQuestion `xml:"question"`
+}
+
+type xmlQuestion struct {
+	Type            string      `xml:"type,attr"`
+	Category        *xmlText    `xml:"category"`
+	Name            *xmlText    `xml:"name"`
+	QuestionText    *xmlText    `xml:"questiontext"`
+	GeneralFeedback *xmlText    `xml:"generalfeedback"`
+	DefaultGrade    *float64    `xml:"defaultgrade"`
+	Single          string      `xml:"single,omitempty"`
+	Answers         []xmlAnswer `xml:"answer"`
+	Hints           []xmlText   `xml:"hint"`
+	Tags            *xmlTags    `xml:"tags"`
+	Units           *xmlUnits   `xml:"units"`
+}
+
+type xmlTags struct {
+	Tags []xmlText `xml:"tag"`
+}
+
+type xmlUnits struct {
+	Units []struct {
+		Name string `xml:"unit_name"`
+	} `xml:"unit"`
+}
+
+type xmlText struct {
+	Format string    `xml:"format,attr,omitempty"`
+	Text   string    `xml:"text"`
+	Files  []xmlFile `xml:"file"`
+}
+
+type xmlFile struct {
+	Name string `xml:"name,attr"`
+}
+
+type xmlAnswer struct {
+	Fraction  float64   `xml:"fraction,attr"`
+	Format    string    `xml:"format,attr,omitempty"`
+	Text      string    `xml:"text"`
+	Files     []xmlFile `xml:"file"`
+	Feedback  *xmlText  `xml:"feedback"`
+	Tolerance *float64  `xml:"tolerance"`
+}
+
+// ReadXML reads the questions of a Moodle XML file. Questions of other types
+// are skipped and reported, as are details of supported questions that are
+// dropped, such as embedded images. The error is for files that are not
+// Moodle XML at all.
+func ReadXML(r io.Reader) ([]Question, []Issue, error) {
+	var questions []Question
+	var issues []Issue
+	category := ""
+	seenQuiz := false
+
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local == "quiz" {
+			seenQuiz = true
+			continue
+		}
+		if start.Name.Local != "question" {
+			err = dec.Skip()
+			if err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		line, _ := dec.InputPos()
+		var x xmlQuestion
+		err = dec.DecodeElement(&x, &start)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if x.Type == "category" {
+			if x.Category != nil {
+				category = x.Category.Text
+			}
+			continue
+		}
+
+		q, problems := x.question(line)
+		issues = append(issues, problems...)
+		if q == nil {
+			continue
+		}
+		q.Category = category
+		questions = append(questions, *q)
+	}
+
+	if !seenQuiz {
+		return nil, nil, errors.New("not a Moodle XML file: no <quiz> element")
+	}
+	return questions, issues, nil
+}
+
+// question converts a decoded question, or returns nil when its type is not
+// supported.
+func (x xmlQuestion) question(line int) (*Question, []Issue) {
+	q := Question{Kind: Kind(x.Type), Single: true, Line: line}
+	if x.Name != nil {
+		q.Name = strings.TrimSpace(x.Name.Text)
+	}
+
+	var issues []Issue
+	report := func(format string, args ...any) {
+		issues = append(issues, issue(q, format, args...))
+	}
+
+	switch q.Kind {
+	case MultiChoice, TrueFalse, ShortAnswer, Numerical:
+	default:
+		report("%s questions are not supported", x.Type)
+		return nil, issues
+	}
+
+	images := false
+	text := func(t *xmlText) string {
+		if t == nil {
+			return ""
+		}
+		if len(t.Files) > 0 {
+			images = true
+		}
+		return fromFormat(t.Format, t.Text, &images)
+	}
+
+	q.Text = text(x.QuestionText)
+	q.Feedback = text(x.GeneralFeedback)
+	if x.DefaultGrade != nil {
+		q.Points = *x.DefaultGrade
+	}
+	if q.Kind == MultiChoice {
+		q.Single = x.Single != "false" && x.Single != "0"
+	}
+
+	for _, a := range x.Answers {
+		answer := Answer{
+			Text:     fromFormat(a.Format, a.Text, &images),
+			Fraction: a.Fraction,
+			Feedback: text(a.Feedback),
+		}
+		if len(a.Files) > 0 {
+			images = true
+		}
+		if a.Tolerance != nil {
+			answer.Tolerance = *a.Tolerance
+		}
+		q.Answers = append(q.Answers, answer)
+	}
+
+	for _, h := range x.Hints {
+		if hint := text(&h); hint != "" {
+			q.Hints = append(q.Hints, hint)
+		}
+	}
+	if x.Tags != nil {
+		for _, t := range x.Tags.Tags {
+			if tag := strings.TrimSpace(t.Text); tag != "" {
+				q.Tags = append(q.Tags, tag)
+			}
+		}
+	}
+
+	if images {
+		report("embedded images and files are dropped")
+	}
+	if x.Units != nil && len(x.Units.Units) > 0 {
+		report("units are dropped")
+	}
+
+	return &q, issues
+}
+
+// fromFormat converts text in the given Moodle text format to plain text,
+// noting in images whether it had any. Only HTML needs converting: plain,
+// Markdown and auto-formatted text is kept as written.
+func fromFormat(format, s string, images *bool) string {
+	if format != "" && format != "html" {
+		return strings.TrimSpace(s)
+	}
+	s, hadImage := plainText(s)
+	if hadImage {
+		*images = true
+	}
+	return s
+}
+
+// WriteXML writes questions as a Moodle XML file. Text is written as HTML.
+func WriteXML(w io.Writer, questions []Question) error {
+	quiz := xmlQuiz{}
+
+	category := ""
+	for _, q := range questions {
+		if q.Category != "" && q.Category != category {
+			category = q.Category
+			quiz.Questions = append(quiz.Questions, xmlQuestion{Type: "category", Category: &xmlText{Text: category}})
+		}
+
+		points := q.Points
+		x := xmlQuestion{
+			Type:            string(q.Kind),
+			Name:            &xmlText{Text: q.Name},
+			QuestionText:    &xmlText{Format: "html", Text: htmlText(q.Text)},
+			GeneralFeedback: &xmlText{Format: "html", Text: htmlText(q.Feedback)},
+			DefaultGrade:    &points,
+		}
+		if q.Kind == MultiChoice {
+			x.Single = "false"
+			if q.Single {
+				x.Single = "true"
+			}
+		}
+
+		for _, a := range q.Answers {
+			answer := xmlAnswer{
+				Fraction: a.Fraction,
+				Format:   "html",
+				Text:     htmlText(a.Text),
+				Feedback: &xmlText{Format: "html", Text: htmlText(a.Feedback)},
+			}
+			switch q.Kind {
+			case TrueFalse, ShortAnswer, Numerical:
+				// Moodle compares these answers as typed, not as HTML
+				answer.Format = "moodle_auto_format"
+				answer.Text = a.Text
+			}
+			if q.Kind == Numerical {
+				tolerance := a.Tolerance
+				answer.Tolerance = &tolerance
+			}
+			x.Answers = append(x.Answers, answer)
+		}
+
+		for _, h := range q.Hints {
+			x.Hints = append(x.Hints, xmlText{Format: "html", Text: htmlText(h)})
+		}
+		if len(q.Tags) > 0 {
+			x.Tags = &xmlTags{}
+			for _, t := range q.Tags {
+				x.Tags.Tags = append(x.Tags.Tags, xmlText{Text: t})
+			}
+		}
+
+		quiz.Questions = append(quiz.Questions, x)
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err = enc.Encode(quiz)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
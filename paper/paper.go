@@ -0,0 +1,321 @@
+// Package paper lays out quizzes as printed exams: question papers in
+// several shuffled variants, an answer key per variant and a bubble answer
+// sheet, and reads back the answers scanned from the sheets.
+//
+// Variants are not stored. They are shuffled with a seed made from the quiz
+// and the variant's letter, so printing a quiz again gives the same papers.
+// Every paper carries a code made from the questions and options as printed;
+// results are only accepted for the code of the quiz as it is now, so
+// answers are never mapped onto questions that changed after printing.
+// Fixing a correct answer or points does not change the code, and scanned
+// results are graded with the fix.
+package paper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+)
+
+// MaxVariants is the number of variants a paper can have, A to J.
+const MaxVariants = 10
+
+// MaxSize is the largest results file accepted.
+const MaxSize = 5 << 20
+
+// Letters are the letters of options in the order they are printed.
+var Letters = []string{"A", "B", "C", "D"}
+
+// Paper is a quiz laid out for printing.
+type Paper struct {
+	QuizID string
+	Title  string
+	// Code identifies the questions and options as printed.
+	Code     string
+	Variants []Variant
+}
+
+// Variant is one ordering of a paper's questions and their options.
+type Variant struct {
+	Name      string
+	Questions []Question
+}
+
+// Question is a question as printed in a variant.
+type Question struct {
+	ID     string
+	Text   string
+	Points float64
+	// Options are in printed order, labelled with Letters.
+	Options []Option
+	// Answer is the printed letter of the correct option.
+	Answer string
+}
+
+// Option is a printed option. Letter is its letter in the quiz, which
+// answers are graded against.
+type Option struct {
+	Letter string
+	Text   string
+}
+
+// Build lays out questions, in quiz order, as a paper with the given number
+// of variants. Questions with two options, such as true or false, keep
+// their order; all other options are shuffled.
+func Build(quiz repo.Quiz, questions []repo.Question, variants int) Paper {
+	p := Paper{QuizID: quiz.ID, Title: quiz.Title, Code: Code(questions)}
+
+	for v := range variants {
+		name := string(rune('A' + v))
+		h := fnv.New64a()
+		h.Write([]byte(quiz.ID + "/" + name))
+		rng := rand.New(rand.NewPCG(h.Sum64(), 0))
+
+		variant := Variant{Name: name, Questions: make([]Question, 0, len(questions))}
+		for _, i := range rng.Perm(len(questions)) {
+			q := questions[i]
+			var options []Option
+			for j, text := range []string{q.OptionA, q.OptionB, q.OptionC, q.OptionD} {
+				if text != "" {
+					options = append(options, Option{Letter: Letters[j], Text: text})
+				}
+			}
+			if len(options) > 2 {
+				rng.Shuffle(len(options), func(a, b int) { options[a], options[b] = options[b], options[a] })
+			}
+
+			printed := Question{ID: q.ID, Text: q.QuestionText, Points: q.Points, Options: options}
+			for j, option := range options {
+				if option.Letter == q.CorrectAnswer {
+					printed.Answer = Letters[j]
+				}
+			}
+			variant.Questions = append(variant.Questions, printed)
+		}
+		p.Variants = append(p.Variants, variant)
+	}
+
+	return p
+}
+
+// Code identifies questions by their IDs, text and options, as eight hex
+// digits. It changes whenever a printed paper of them would.
+func Code(questions []repo.Question) string {
+	h := sha256.New()
+	for _, q := range questions {
+		for _, s := range []string{q.ID, q.QuestionText, q.OptionA, q.OptionB, q.OptionC, q.OptionD} {
+			fmt.Fprintf(h, "%d:%s", len(s), s)
+		}
+	}
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil))[:8])
+}
+
+// Variant returns the variant called name.
+func (p Paper) Variant(name string) (Variant, bool) {
+	for _, v := range p.Variants {
+		if strings.EqualFold(v.Name, name) {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// Problem is a results row that cannot be recorded.
+type Problem struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// Result is a student's scanned answer sheet.
+type Result struct {
+	Line    int
+	Student string
+	Variant string
+	// Answers maps question IDs to answers with the quiz's letters. Blank
+	// questions are left out.
+	Answers map[string]string
+	// Blank counts the questions left blank or marked more than once.
+	Blank int
+}
+
+var questionColumn = regexp.MustCompile(`^q?(\d+)$`)
+
+// ReadResults reads scanned answer sheets as CSV with a header row. The
+// student and variant columns are required. Answers are either an answers
+// column with a letter per question, such as "BDA-C", or a column per
+// question headed 1, 2… or q1, q2…, as optical mark readers export them.
+// Blank questions are "-", ".", "_" or empty, and questions marked more
+// than once are "*"; both are graded as skipped. Letters are the ones
+// printed on the student's variant.
+//
+// Every row is checked against the paper. Problems are returned instead of
+// results, so that a file is recorded whole or not at all.
+func ReadResults(r io.Reader, p Paper) ([]Result, []Problem, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, []Problem{{Line: 1, Message: "the file is empty"}}, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	student, variant, answers := -1, -1, -1
+	columns := make(map[int]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "student":
+			student = i
+		case "variant":
+			variant = i
+		case "answers":
+			answers = i
+		default:
+			if m := questionColumn.FindStringSubmatch(name); m != nil {
+				if n, _ := strconv.Atoi(m[1]); n > 0 {
+					columns[n-1] = i
+				}
+			}
+		}
+	}
+	if student < 0 || variant < 0 || (answers < 0 && len(columns) == 0) {
+		return nil, []Problem{{Line: 1, Message: "the header needs student, variant and answers columns, or a column per question"}}, nil
+	}
+
+	var results []Result
+	var problems []Problem
+	students := make(map[string]int)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		cell := func(i int) string {
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		report := func(format string, args ...any) {
+			problems = append(problems, Problem{Line: line, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		if cell(student) == "" {
+			report("student is empty")
+			continue
+		}
+		if first, ok := students[strings.ToLower(cell(student))]; ok {
+			report("%s already has an answer sheet on line %d", cell(student), first)
+			continue
+		}
+		students[strings.ToLower(cell(student))] = line
+
+		v, ok := p.Variant(cell(variant))
+		if !ok {
+			report("variant %q is not on this paper, variants are %s to %s", cell(variant), p.Variants[0].Name, p.Variants[len(p.Variants)-1].Name)
+			continue
+		}
+
+		var marks []string
+		if answers >= 0 {
+			marks = splitAnswers(cell(answers))
+		} else {
+			for n, i := range columns {
+				for len(marks) <= n {
+					marks = append(marks, "")
+				}
+				marks[n] = cell(i)
+			}
+		}
+		if len(marks) > len(v.Questions) {
+			report("%d answers, but variant %s has %d questions", len(marks), v.Name, len(v.Questions))
+			continue
+		}
+
+		result := Result{Line: line, Student: cell(student), Variant: v.Name, Answers: make(map[string]string)}
+		valid := true
+		for n, q := range v.Questions {
+			mark := ""
+			if n < len(marks) {
+				mark = strings.ToUpper(marks[n])
+			}
+			switch mark {
+			case "", "-", ".", "_", "*":
+				result.Blank++
+				continue
+			}
+
+			i := -1
+			for j := range q.Options {
+				if Letters[j] == mark {
+					i = j
+				}
+			}
+			if i < 0 {
+				report("answer %q to question %d is not one of its %d options", marks[n], n+1, len(q.Options))
+				valid = false
+				break
+			}
+			result.Answers[q.ID] = q.Options[i].Letter
+		}
+		if valid {
+			results = append(results, result)
+		}
+	}
+
+	if len(results) == 0 && len(problems) == 0 {
+		problems = append(problems, Problem{Line: 1, Message: "no answer sheets in the file"})
+	}
+	if len(problems) > 0 {
+		return nil, problems, nil
+	}
+	return results, nil, nil
+}
+
+// splitAnswers splits an answers cell into a mark per question. Marks are
+// single letters unless they are separated by commas, semicolons, bars or
+// spaces. Between commas, semicolons or bars a blank can also be empty.
+func splitAnswers(s string) []string {
+	if strings.ContainsAny(s, ",;|") {
+		marks := strings.Split(strings.NewReplacer(";", ",", "|", ",").Replace(s), ",")
+		for i := range marks {
+			marks[i] = strings.TrimSpace(marks[i])
+		}
+		return marks
+	}
+	if strings.Contains(s, " ") {
+		return strings.Fields(s)
+	}
+	marks := make([]string, 0, len(s))
+	for _, r := range s {
+		marks = append(marks, string(r))
+	}
+	return marks
+}
@@ -0,0 +1,240 @@
+package paper
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+
+	"github.com/Iknite-Space/sqlc-example-api/pdf"
+)
+
+// Page layout in points.
+const (
+	margin     = 50.0
+	bottom     = pdf.PageHeight - 60
+	lineHeight = 14.0
+	textSize   = 11.0
+)
+
+// Answer sheet grid: rows of a question number and its bubbles, in columns.
+const (
+	sheetTop     = 250.0
+	sheetRow     = 18.0
+	sheetRows    = 30
+	sheetColumns = 3
+	bubbleGap    = 22.0
+	bubbleRadius = 6.0
+)
+
+// RenderPDF writes the student papers: for each variant, its questions and
+// then its answer sheet, each starting on a new page.
+func RenderPDF(w io.Writer, p Paper) error {
+	doc := pdf.New()
+	for _, v := range p.Variants {
+		questionsPDF(doc, p, v)
+		sheetPDF(doc, p, v)
+	}
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// RenderKeysPDF writes the answer key of each variant, for markers only.
+func RenderKeysPDF(w io.Writer, p Paper) error {
+	doc := pdf.New()
+	for _, v := range p.Variants {
+		page := doc.AddPage()
+		heading(page, p, v, "Answer Key")
+
+		// Four columns of 45 answers a page
+		for i, q := range v.Questions {
+			if i > 0 && i%180 == 0 {
+				page = doc.AddPage()
+				heading(page, p, v, "Answer Key (continued)")
+			}
+			col, row := (i%180)/45, i%45
+			page.Text(margin+float64(col)*125, 140+float64(row)*lineHeight, 10, pdf.Regular,
+				fmt.Sprintf("%d. %s  (%g pt)", i+1, q.Answer, q.Points))
+		}
+	}
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// heading starts a page with the quiz title, what the page is and the
+// variant and paper code that identify it.
+func heading(page *pdf.Page, p Paper, v Variant, what string) {
+	page.Text(margin, 60, 16, pdf.Bold, p.Title)
+	page.Text(margin, 82, 11, pdf.Regular, fmt.Sprintf("%s - Variant %s - Paper %s", what, v.Name, p.Code))
+	page.Line(margin, 95, pdf.PageWidth-margin, 95, 0.5)
+}
+
+func questionsPDF(doc *pdf.Document, p Paper, v Variant) {
+	page := doc.AddPage()
+	heading(page, p, v, "Question Paper")
+	page.Text(margin, 120, textSize, pdf.Regular, "Name: ______________________________    Date: ______________")
+	page.Text(margin, 140, 9, pdf.Regular, "Mark one answer per question on the answer sheet.")
+	y := 170.0
+
+	width := pdf.PageWidth - 2*margin
+	for i, q := range v.Questions {
+		lines := pdf.Wrap(fmt.Sprintf("%d. %s (%g pt)", i+1, q.Text, q.Points), width, textSize, pdf.Bold)
+		var options [][]string
+		height := float64(len(lines)) * lineHeight
+		for j, option := range q.Options {
+			wrapped := pdf.Wrap(Letters[j]+")  "+option.Text, width-20, textSize, pdf.Regular)
+			options = append(options, wrapped)
+			height += float64(len(wrapped)) * lineHeight
+		}
+
+		if y+height > bottom {
+			page = doc.AddPage()
+			page.Text(margin, 50, 9, pdf.Regular, fmt.Sprintf("%s - Variant %s - Paper %s", p.Title, v.Name, p.Code))
+			y = 80
+		}
+
+		for _, line := range lines {
+			page.Text(margin, y, textSize, pdf.Bold, line)
+			y += lineHeight
+		}
+		for _, wrapped := range options {
+			for _, line := range wrapped {
+				page.Text(margin+20, y, textSize, pdf.Regular, line)
+				y += lineHeight
+			}
+		}
+		y += lineHeight / 2
+	}
+}
+
+// sheetPDF draws a variant's bubble answer sheet. The variant is printed
+// and has its own row of bubbles, so sheets can be read without the
+// question paper.
+func sheetPDF(doc *pdf.Document, p Paper, v Variant) {
+	perPage := sheetRows * sheetColumns
+	for start := 0; start == 0 || start < len(v.Questions); start += perPage {
+		page := doc.AddPage()
+		heading(page, p, v, "Answer Sheet")
+		page.Text(margin, 125, textSize, pdf.Regular, "Student: ______________________________")
+		page.Text(margin, 155, textSize, pdf.Regular, "Variant:")
+		for i, variant := range p.Variants {
+			x := margin + 70 + float64(i)*bubbleGap
+			bubble(page, x, 151, variant.Name)
+		}
+		page.Text(margin, 190, 9, pdf.Regular, "Fill in one circle per question completely. Fill in your variant above.")
+		page.Line(margin, 205, pdf.PageWidth-margin, 205, 0.5)
+
+		for i := start; i < len(v.Questions) && i < start+perPage; i++ {
+			col, row := (i-start)/sheetRows, (i-start)%sheetRows
+			x := margin + float64(col)*165
+			y := sheetTop + float64(row)*sheetRow
+			number := strconv.Itoa(i + 1)
+			page.Text(x+20-pdf.TextWidth(number, 10, pdf.Regular), y+3.5, 10, pdf.Regular, number)
+			for j := range v.Questions[i].Options {
+				bubble(page, x+40+float64(j)*bubbleGap, y, Letters[j])
+			}
+		}
+	}
+}
+
+// bubble draws a circle to fill in, labelled with its letter.
+func bubble(page *pdf.Page, x, y float64, letter string) {
+	page.Circle(x, y, bubbleRadius, 0.7)
+	page.Text(x-pdf.TextWidth(letter, 7, pdf.Regular)/2, y+2.5, 7, pdf.Regular, letter)
+}
+
+var funcs = template.FuncMap{
+	"letter": func(i int) string { return Letters[i] },
+	"inc":    func(i int) int { return i + 1 },
+}
+
+var htmlTemplate = template.Must(template.New("paper").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - Paper {{.Code}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; margin: 40px; }
+.page { break-after: page; }
+.meta { color: #555; border-bottom: 1px solid #999; padding-bottom: 8px; }
+ol.questions > li { margin-bottom: 14px; break-inside: avoid; }
+ol.options { list-style: upper-alpha; }
+.sheet ol { columns: 3; }
+.sheet li { margin-bottom: 6px; break-inside: avoid; }
+.bubble { display: inline-block; width: 16px; height: 16px; line-height: 16px; border: 1px solid #333; border-radius: 50%; text-align: center; font-size: 9px; margin-right: 4px; }
+</style>
+</head>
+<body>
+{{- $p := .}}
+{{- range $v := .Variants}}
+<section class="page">
+<h1>{{$p.Title}}</h1>
+<p class="meta">Question Paper - Variant {{$v.Name}} - Paper {{$p.Code}}</p>
+<p>Name: ______________________________ Date: ______________</p>
+<p><small>Mark one answer per question on the answer sheet.</small></p>
+<ol class="questions">
+{{- range $v.Questions}}
+<li><strong>{{.Text}}</strong> ({{.Points}} pt)
+<ol class="options">{{range .Options}}<li>{{.Text}}</li>{{end}}</ol>
+</li>
+{{- end}}
+</ol>
+</section>
+<section class="page sheet">
+<h1>{{$p.Title}}</h1>
+<p class="meta">Answer Sheet - Variant {{$v.Name}} - Paper {{$p.Code}}</p>
+<p>Student: ______________________________</p>
+<p>Variant: {{range $p.Variants}}<span class="bubble">{{.Name}}</span>{{end}}</p>
+<p><small>Fill in one circle per question completely. Fill in your variant above.</small></p>
+<ol>
+{{- range $v.Questions}}
+<li>{{range $i, $o := .Options}}<span class="bubble">{{letter $i}}</span>{{end}}</li>
+{{- end}}
+</ol>
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+var keysTemplate = template.Must(template.New("keys").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - Answer Keys - Paper {{.Code}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; margin: 40px; }
+.page { break-after: page; }
+.meta { color: #555; border-bottom: 1px solid #999; padding-bottom: 8px; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #999; padding: 2px 10px; text-align: left; }
+</style>
+</head>
+<body>
+{{- $p := .}}
+{{- range $v := .Variants}}
+<section class="page">
+<h1>{{$p.Title}}</h1>
+<p class="meta">Answer Key - Variant {{$v.Name}} - Paper {{$p.Code}}</p>
+<table>
+<tr><th>#</th><th>Answer</th><th>Points</th><th>Question</th></tr>
+{{- range $i, $q := $v.Questions}}
+<tr><td>{{inc $i}}</td><td>{{$q.Answer}}</td><td>{{$q.Points}}</td><td>{{$q.Text}}</td></tr>
+{{- end}}
+</table>
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+// RenderHTML writes the student papers as an HTML page that prints each
+// question paper and answer sheet on its own pages.
+func RenderHTML(w io.Writer, p Paper) error {
+	return htmlTemplate.Execute(w, p)
+}
+
+// RenderKeysHTML writes the answer keys as an HTML page, a variant a page.
+func RenderKeysHTML(w io.Writer, p Paper) error {
+	return keysTemplate.Execute(w, p)
+}
@@ -0,0 +1,29 @@
+package policy
+
+// Attempt modes. Exam and adaptive attempts are official and count towards
+// leaderboards, statistics and attempt limits; practice and flashcard
+// attempts only appear in the player's own history.
+const (
+	ModeExam      = "exam"
+	ModePractice  = "practice"
+	ModeFlashcard = "flashcard"
+	ModeAdaptive  = "adaptive"
+)
+
+// ValidMode reports whether m is a mode players can choose when taking a
+// quiz. Adaptive attempts have their own endpoint.
+func ValidMode(m string) bool {
+	return m == ModeExam || m == ModePractice || m == ModeFlashcard
+}
+
+// Official reports whether attempts in mode m count as official results.
+func Official(m string) bool {
+	return m == ModeExam || m == ModeAdaptive
+}
+
+// AllowsUnofficial reports whether a practice or flashcard attempt may go
+// ahead. Attempt limits and cooldowns only apply to official attempts, but
+// the availability window applies to every attempt.
+func (d Decision) AllowsUnofficial() bool {
+	return d.Allowed || d.Reason == NoAttemptsLeft || d.Reason == CoolingDown
+}
@@ -0,0 +1,80 @@
+// Package policy decides whether a player may start a new attempt at a quiz
+// given its attempt limit, cooldown and availability window.
+package policy
+
+import (
+	"time"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+)
+
+// Reason explains why an attempt was refused.
+type Reason string
+
+const (
+	NotOpenYet     Reason = "not_open_yet"
+	Closed         Reason = "closed"
+	NoAttemptsLeft Reason = "no_attempts_left"
+	CoolingDown    Reason = "cooling_down"
+)
+
+// Decision is the outcome of checking a quiz's attempt policy.
+type Decision struct {
+	Allowed bool
+	Reason  Reason
+	Message string
+	// RetryAt is when the player may try again. It is zero when they never
+	// can, such as after the quiz has closed.
+	RetryAt time.Time
+	// Now is the database time the decision was made at. Timestamps are
+	// stored without a time zone, so comparisons must use database time.
+	Now time.Time
+	// AttemptsRemaining is nil when the quiz has no attempt limit.
+	AttemptsRemaining *int
+}
+
+// RetryAfter returns how long the player has to wait before retrying.
+func (d Decision) RetryAfter() time.Duration {
+	if d.RetryAt.IsZero() {
+		return 0
+	}
+
+	return d.RetryAt.Sub(d.Now)
+}
+
+// Check applies the quiz's attempt policy to a player's attempt history.
+func Check(e repo.GetAttemptEligibilityRow) Decision {
+	now := e.CheckedAt.Time
+	d := Decision{Allowed: true, Now: now}
+
+	if e.MaxAttempts != nil {
+		remaining := max(int(*e.MaxAttempts)-int(e.AttemptsUsed), 0)
+		d.AttemptsRemaining = &remaining
+	}
+
+	switch {
+	case e.OpensAt.Valid && now.Before(e.OpensAt.Time):
+		d.Allowed = false
+		d.Reason = NotOpenYet
+		d.Message = "this quiz is not open yet"
+		d.RetryAt = e.OpensAt.Time
+	case e.ClosesAt.Valid && !now.Before(e.ClosesAt.Time):
+		d.Allowed = false
+		d.Reason = Closed
+		d.Message = "this quiz has closed"
+	case d.AttemptsRemaining != nil && *d.AttemptsRemaining == 0:
+		d.Allowed = false
+		d.Reason = NoAttemptsLeft
+		d.Message = "no attempts remaining for this quiz"
+	case e.CooldownSeconds > 0 && e.LastAttemptAt.Valid:
+		retryAt := e.LastAttemptAt.Time.Add(time.Duration(e.CooldownSeconds) * time.Second)
+		if now.Before(retryAt) {
+			d.Allowed = false
+			d.Reason = CoolingDown
+			d.Message = "please wait before attempting this quiz again"
+			d.RetryAt = retryAt
+		}
+	}
+
+	return d
+}
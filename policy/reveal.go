@@ -0,0 +1,34 @@
+package policy
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Reveal policies control when players may see correct answers and
+// explanations.
+const (
+	RevealNever        = "never"
+	RevealAfterAttempt = "after_attempt"
+	RevealAfterClose   = "after_close"
+)
+
+// ValidRevealPolicy reports whether p is a known reveal policy.
+func ValidRevealPolicy(p string) bool {
+	return p == RevealNever || p == RevealAfterAttempt || p == RevealAfterClose
+}
+
+// RevealAnswers reports whether correct answers and explanations may be
+// shown for a finished attempt at now. Quizzes revealed after closing stay
+// hidden forever if they have no closing time.
+func RevealAnswers(revealPolicy string, closesAt pgtype.Timestamp, now time.Time) bool {
+	switch revealPolicy {
+	case RevealAfterAttempt:
+		return true
+	case RevealAfterClose:
+		return closesAt.Valid && !now.Before(closesAt.Time)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,203 @@
+// Package qti reads and writes IMS QTI 2.1 and 3.0 content packages: zip
+// files holding an imsmanifest.xml, an assessmentItem file per question and,
+// optionally, an assessmentTest that orders them.
+//
+// Quiz questions map onto items with a single choiceInteraction of two to
+// four choices and one correct response. Other items, and content that quiz
+// questions cannot hold, are reported as issues instead of being dropped
+// silently. The files that items show, such as images, are carried as the
+// questions' media.
+//
+// Every file of a package is validated against content models transcribed
+// from the IMS XSDs (see schema.go): namespaces, required attributes,
+// attribute values and the order and number of children, for the elements
+// quiz packages use. The XSDs themselves are not loaded, as the Go standard
+// library has no XSD validator, so the XHTML content of items is not
+// checked beyond their media.
+package qti
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Version is a QTI version.
+type Version string
+
+// Supported versions.
+const (
+	V21 Version = "2.1"
+	V30 Version = "3.0"
+)
+
+// MaxSize is the largest package Read accepts.
+const MaxSize = 20 << 20
+
+// ValidVersion reports whether v is a supported version.
+func ValidVersion(v Version) bool {
+	return v == V21 || v == V30
+}
+
+// Issue is something in a package that could not be imported or exported,
+// with the file it concerns.
+type Issue struct {
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+}
+
+func (i Issue) Error() string {
+	if i.File == "" {
+		return i.Message
+	}
+	return i.File + ": " + i.Message
+}
+
+// node is an element of a QTI document. QTI 2.1 names elements in camel
+// case and QTI 3.0 in kebab case with a qti- prefix, so names and attribute
+// keys are normalised to lower case without the prefix or hyphens:
+// both choiceInteraction and qti-choice-interaction become
+// "choiceinteraction".
+type node struct {
+	name string
+	// space is the element's namespace.
+	space    string
+	attrs    map[string]string
+	children []*node
+	// parts holds the element's character data and children in document
+	// order.
+	parts []part
+}
+
+type part struct {
+	text  string
+	child *node
+}
+
+func normalise(name string) string {
+	name = strings.TrimPrefix(name, "qti-")
+	return strings.ToLower(strings.ReplaceAll(name, "-", ""))
+}
+
+// parse reads an XML document into nodes.
+func parse(r io.Reader) (*node, error) {
+	dec := xml.NewDecoder(r)
+	var stack []*node
+	var root *node
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: normalise(t.Name.Local), space: t.Name.Space, attrs: make(map[string]string)}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					continue
+				}
+				n.attrs[normalise(a.Name.Local)] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+				parent.parts = append(parent.parts, part{child: n})
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				n := stack[len(stack)-1]
+				n.parts = append(n.parts, part{text: string(t)})
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
+
+// child returns the first child with the given normalised name.
+func (n *node) child(name string) *node {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// all returns every descendant with the given normalised name.
+func (n *node) all(name string) []*node {
+	var found []*node
+	for _, c := range n.children {
+		if c.name == name {
+			found = append(found, c)
+		}
+		found = append(found, c.all(name)...)
+	}
+	return found
+}
+
+// blocks are the XHTML elements that start a new line of text.
+var blocks = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "blockquote": true, "pre": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "tr": true,
+}
+
+// media are the elements that embed files.
+var media = map[string]bool{"img": true, "object": true, "audio": true, "video": true}
+
+// text converts an element's content to plain text, one line per paragraph.
+// Elements that skip returns true for are left out. It reports whether
+// media was left out too.
+func (n *node) text(skip func(*node) bool) (string, bool) {
+	var b strings.Builder
+	hasMedia := false
+
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, p := range n.parts {
+			if p.child == nil {
+				// Whitespace is collapsed below, once the whole line is known
+				b.WriteString(strings.NewReplacer("\n", " ", "\t", " ", "\r", " ").Replace(p.text))
+				continue
+			}
+			c := p.child
+			if skip != nil && skip(c) {
+				continue
+			}
+			if media[c.name] {
+				hasMedia = true
+				continue
+			}
+			if blocks[c.name] {
+				b.WriteString("\n")
+			}
+			walk(c)
+			if blocks[c.name] {
+				b.WriteString("\n")
+			}
+		}
+	}
+	walk(n)
+
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n"), hasMedia
+}
@@ -0,0 +1,233 @@
+package qti
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+)
+
+// pack zips the package in dir. replace swaps files of the package for
+// other files in testdata, by their name in the package, or leaves them out
+// when the other file is empty.
+func pack(t *testing.T, dir string, replace map[string]string) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+
+		if other, ok := replace[name]; ok {
+			if other == "" {
+				return nil
+			}
+			path = other
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func read(t *testing.T, r *bytes.Reader) (quizfile.Quiz, []Issue) {
+	t.Helper()
+
+	quiz, issues, err := Read(r, r.Size(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return quiz, issues
+}
+
+func messages(issues []Issue) []string {
+	out := make([]string, len(issues))
+	for i, issue := range issues {
+		out[i] = issue.Error()
+	}
+	return out
+}
+
+func sample(t *testing.T) []quizfile.Question {
+	t.Helper()
+
+	flag, err := os.ReadFile("testdata/v21/items/media/flag.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []quizfile.Question{
+		{
+			ID:            "capital",
+			QuestionText:  "What is the capital of France?",
+			OptionA:       "Berlin",
+			OptionB:       "Paris",
+			OptionC:       "Madrid",
+			CorrectAnswer: "B",
+			Points:        2,
+			Explanation:   "Paris has been the capital since 987.",
+			Media:         []quizfile.Media{{Name: "flag.svg", ContentType: "image/svg+xml", Data: flag}},
+		},
+		{
+			ID:            "planets",
+			QuestionText:  "Which planet is the largest?",
+			OptionA:       "Mercury",
+			OptionB:       "Venus",
+			OptionC:       "Earth",
+			OptionD:       "Jupiter",
+			CorrectAnswer: "D",
+			Points:        3,
+		},
+	}
+}
+
+func TestRead(t *testing.T) {
+	want := sample(t)
+
+	for _, dir := range []string{"testdata/v21", "testdata/v30"} {
+		t.Run(dir, func(t *testing.T) {
+			quiz, issues := read(t, pack(t, dir, nil))
+
+			if quiz.Title != "Geography" {
+				t.Errorf("title = %q, want the test's title", quiz.Title)
+			}
+			if !reflect.DeepEqual(quiz.Questions, want) {
+				t.Errorf("questions = %+v, want %+v", quiz.Questions, want)
+			}
+			wantIssues := []string{"items/essay.xml: extendedtextinteraction items are not supported"}
+			if got := messages(issues); !reflect.DeepEqual(got, wantIssues) {
+				t.Errorf("issues = %q, want %q", got, wantIssues)
+			}
+		})
+	}
+}
+
+// TestRoundTrip writes what each fixture reads as every version and reads
+// it back, which must give the same questions and no issues.
+func TestRoundTrip(t *testing.T) {
+	for _, dir := range []string{"testdata/v21", "testdata/v30"} {
+		quiz, _ := read(t, pack(t, dir, nil))
+		quiz.Slug = "geography"
+
+		for _, version := range []Version{V21, V30} {
+			t.Run(dir+" as "+string(version), func(t *testing.T) {
+				var buf bytes.Buffer
+				issues, err := Write(&buf, quiz, version)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(issues) > 0 {
+					t.Errorf("write issues = %q, want none", messages(issues))
+				}
+
+				got, issues := read(t, bytes.NewReader(buf.Bytes()))
+				if len(issues) > 0 {
+					t.Errorf("read issues = %q, want none", messages(issues))
+				}
+				if got.Title != quiz.Title {
+					t.Errorf("title = %q, want %q", got.Title, quiz.Title)
+				}
+				if !reflect.DeepEqual(got.Questions, quiz.Questions) {
+					t.Errorf("questions = %+v, want %+v", got.Questions, quiz.Questions)
+				}
+			})
+		}
+	}
+}
+
+func TestReadRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		dir     string
+		replace map[string]string
+		want    string
+	}{
+		{
+			name:    "missing attribute",
+			dir:     "testdata/v21",
+			replace: map[string]string{"items/capital.xml": "testdata/invalid/missing-attribute.xml"},
+			want:    "/assessmentitem/itembody/choiceinteraction: attribute responseidentifier is required",
+		},
+		{
+			name:    "children out of order",
+			dir:     "testdata/v21",
+			replace: map[string]string{"items/capital.xml": "testdata/invalid/order.xml"},
+			want:    "/assessmentitem: <responsedeclaration> is not allowed here",
+		},
+		{
+			name:    "unknown namespace",
+			dir:     "testdata/v21",
+			replace: map[string]string{"items/capital.xml": "testdata/invalid/namespace.xml"},
+			want:    "not a QTI 2.1 or 3.0 namespace",
+		},
+		{
+			name:    "attribute value",
+			dir:     "testdata/v21",
+			replace: map[string]string{"items/capital.xml": "testdata/invalid/cardinality.xml"},
+			want:    `cardinality is "one", want one of single, multiple, ordered, record`,
+		},
+		{
+			name:    "manifest order",
+			dir:     "testdata/v21",
+			replace: map[string]string{"imsmanifest.xml": "testdata/invalid/manifest.xml"},
+			want:    "imsmanifest.xml breaks the QTI schema",
+		},
+		{
+			name:    "boolean",
+			dir:     "testdata/v30",
+			replace: map[string]string{"items/planets.xml": "testdata/invalid/time-dependent.xml"},
+			want:    `/assessmentitem: timedependent is "sometimes", want true or false`,
+		},
+		{
+			name:    "missing media",
+			dir:     "testdata/v30",
+			replace: map[string]string{"items/media/flag.svg": ""},
+			want:    "items/capital.xml: media items/media/flag.svg is missing from the package",
+		},
+		{
+			name:    "missing item",
+			dir:     "testdata/v21",
+			replace: map[string]string{"items/planets.xml": ""},
+			want:    "items/planets.xml is missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := pack(t, tt.dir, tt.replace)
+			quiz, _, err := Read(r, r.Size(), "")
+			if err == nil {
+				t.Fatalf("read %d questions, want an error", len(quiz.Questions))
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,459 @@
+package qti
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+)
+
+// Read reads a package into a quiz. The quiz is titled after the package's
+// assessment test, or title when it has none. Items that are not single
+// choice questions are skipped and reported, as is content quiz questions
+// cannot hold. The files an item's media refers to are kept as the
+// question's media. The error is for packages that are broken or break the
+// schema (see validate), so that nothing of them is imported.
+func Read(r io.ReaderAt, size int64, title string) (quizfile.Quiz, []Issue, error) {
+	quiz := quizfile.Quiz{Title: title}
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return quiz, nil, fmt.Errorf("not a QTI package: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	manifest, err := parseFile(files, "imsmanifest.xml")
+	if err != nil {
+		return quiz, nil, fmt.Errorf("not a QTI package: %w", err)
+	}
+	if manifest.name != "manifest" {
+		return quiz, nil, fmt.Errorf("imsmanifest.xml: root element is <%s>, want <manifest>", manifest.name)
+	}
+
+	var items, tests, others []string
+	var issues []Issue
+	for _, res := range manifest.all("resource") {
+		href := res.attrs["href"]
+		kind := res.attrs["type"]
+		switch {
+		case strings.HasPrefix(kind, "imsqti_item_"):
+			items = append(items, href)
+		case strings.HasPrefix(kind, "imsqti_test_"):
+			tests = append(tests, href)
+		default:
+			for _, f := range res.all("file") {
+				others = append(others, f.attrs["href"])
+			}
+		}
+	}
+	if len(items) == 0 {
+		return quiz, nil, errors.New("imsmanifest.xml lists no assessment items")
+	}
+
+	// The assessment test, when there is one, gives the title and order
+	if len(tests) > 0 {
+		if len(tests) > 1 {
+			issues = append(issues, Issue{File: tests[1], Message: "only the first assessment test is read"})
+		}
+		order, testTitle, err := readTest(files, tests[0])
+		if err != nil {
+			return quiz, nil, err
+		}
+		if testTitle != "" {
+			quiz.Title = testTitle
+		}
+		items = ordered(items, order)
+	}
+
+	ids := make(map[string]string)
+	used := make(map[string]bool)
+	for _, href := range items {
+		question, problems, err := readItem(files, href, used)
+		if err != nil {
+			return quiz, nil, err
+		}
+		issues = append(issues, problems...)
+		if question == nil {
+			continue
+		}
+		if other, ok := ids[question.ID]; ok {
+			return quiz, nil, fmt.Errorf("%s: identifier %q is already used by %s", href, question.ID, other)
+		}
+		ids[question.ID] = href
+		quiz.Questions = append(quiz.Questions, *question)
+	}
+	for _, href := range others {
+		if !used[href] {
+			issues = append(issues, Issue{File: href, Message: "no imported item uses the file, so it is not kept"})
+		}
+	}
+
+	return quiz, issues, nil
+}
+
+func parseFile(files map[string]*zip.File, name string) (*node, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s is missing", name)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	n, err := parse(rc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	problems := validate(n, name)
+	if len(problems) > 0 {
+		errs := make([]error, len(problems))
+		for i, p := range problems {
+			errs[i] = p
+		}
+		return nil, fmt.Errorf("%s breaks the QTI schema:\n%w", name, errors.Join(errs...))
+	}
+	return n, nil
+}
+
+// readTest returns the items of an assessment test, in order, and its
+// title.
+func readTest(files map[string]*zip.File, href string) ([]string, string, error) {
+	test, err := parseFile(files, href)
+	if err != nil {
+		return nil, "", err
+	}
+	if test.name != "assessmenttest" {
+		return nil, "", fmt.Errorf("%s: root element is <%s>, want an assessment test", href, test.name)
+	}
+
+	var order []string
+	for _, ref := range test.all("assessmentitemref") {
+		// Item references are relative to the test
+		order = append(order, path.Join(path.Dir(href), ref.attrs["href"]))
+	}
+	return order, strings.TrimSpace(test.attrs["title"]), nil
+}
+
+// ordered sorts items into the order of the test. Items the test does not
+// use keep their manifest order, after the others.
+func ordered(items, order []string) []string {
+	listed := make(map[string]bool, len(items))
+	for _, item := range items {
+		listed[item] = true
+	}
+
+	var out []string
+	used := make(map[string]bool)
+	for _, item := range order {
+		if listed[item] && !used[item] {
+			out = append(out, item)
+			used[item] = true
+		}
+	}
+	for _, item := range items {
+		if !used[item] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+var letters = []string{"A", "B", "C", "D"}
+
+// readItem reads an assessment item, or returns nil when it is not a single
+// choice question. The media files it keeps are marked in used.
+func readItem(files map[string]*zip.File, href string, used map[string]bool) (*quizfile.Question, []Issue, error) {
+	item, err := parseFile(files, href)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item.name != "assessmentitem" {
+		return nil, nil, fmt.Errorf("%s: root element is <%s>, want an assessment item", href, item.name)
+	}
+
+	var issues []Issue
+	report := func(format string, args ...any) {
+		issues = append(issues, Issue{File: href, Message: fmt.Sprintf(format, args...)})
+	}
+
+	body := item.child("itembody")
+	if body == nil {
+		return nil, nil, fmt.Errorf("%s: the item has no body", href)
+	}
+
+	var interactions []*node
+	var collect func(*node)
+	collect = func(n *node) {
+		for _, c := range n.children {
+			if strings.HasSuffix(c.name, "interaction") {
+				interactions = append(interactions, c)
+				continue
+			}
+			collect(c)
+		}
+	}
+	collect(body)
+
+	if len(interactions) != 1 {
+		report("items with %d interactions are not supported, quiz questions have one", len(interactions))
+		return nil, issues, nil
+	}
+	interaction := interactions[0]
+	if interaction.name != "choiceinteraction" {
+		report("%s items are not supported", interaction.name)
+		return nil, issues, nil
+	}
+	if max := interaction.attrs["maxchoices"]; max != "" && max != "1" {
+		report("items where several choices are made are not supported")
+		return nil, issues, nil
+	}
+
+	choices := interaction.all("simplechoice")
+	if len(choices) < 2 || len(choices) > len(letters) {
+		report("has %d choices, quiz questions have 2 to %d", len(choices), len(letters))
+		return nil, issues, nil
+	}
+
+	var declaration *node
+	for _, d := range item.all("responsedeclaration") {
+		if d.attrs["identifier"] == interaction.attrs["responseidentifier"] {
+			declaration = d
+		}
+	}
+	if declaration == nil {
+		return nil, nil, fmt.Errorf("%s: no response declaration for %q", href, interaction.attrs["responseidentifier"])
+	}
+	if declaration.attrs["cardinality"] != "single" {
+		report("items with several correct choices are not supported")
+		return nil, issues, nil
+	}
+
+	var correct string
+	if cr := declaration.child("correctresponse"); cr != nil {
+		if v := cr.child("value"); v != nil {
+			correct, _ = v.text(nil)
+		}
+	}
+	if correct == "" {
+		report("items without a correct response are not supported")
+		return nil, issues, nil
+	}
+
+	// Media in the question is kept with it, media elsewhere is moved there
+	elsewhere := false
+	q := quizfile.Question{ID: item.attrs["identifier"], Points: 1}
+	if len(q.ID) > quizfile.MaxIDLength {
+		q.ID = quizfile.QuestionID(q.ID)
+	}
+
+	// The question is the body around the interaction and its prompt
+	text, _ := body.text(func(n *node) bool { return n == interaction || strings.HasPrefix(n.name, "feedback") })
+	if prompt := interaction.child("prompt"); prompt != nil {
+		promptText, _ := prompt.text(nil)
+		text = strings.TrimSpace(text + "\n" + promptText)
+	}
+	q.QuestionText = text
+
+	options := []*string{&q.OptionA, &q.OptionB, &q.OptionC, &q.OptionD}
+	for i, choice := range choices {
+		choiceText, hasMedia := choice.text(nil)
+		elsewhere = elsewhere || hasMedia
+		*options[i] = choiceText
+		if choice.attrs["identifier"] == correct {
+			q.CorrectAnswer = letters[i]
+		}
+	}
+	if q.CorrectAnswer == "" {
+		return nil, nil, fmt.Errorf("%s: correct response %q is not a choice", href, correct)
+	}
+
+	if points, ok := score(item, declaration, correct); ok {
+		q.Points = points
+	}
+
+	feedback := item.all("modalfeedback")
+	if len(feedback) > 0 {
+		explanation, hasMedia := feedback[0].text(nil)
+		elsewhere = elsewhere || hasMedia
+		q.Explanation = explanation
+	}
+	if len(feedback) > 1 {
+		report("only the first of %d modal feedbacks is kept, as the explanation", len(feedback))
+	}
+	if len(item.all("templatedeclaration")) > 0 {
+		report("templates are not supported, the item is imported as written")
+	}
+	for _, n := range body.all("feedbackinline") {
+		_, hasMedia := n.text(nil)
+		elsewhere = elsewhere || hasMedia
+	}
+	for _, n := range body.all("feedbackblock") {
+		_, hasMedia := n.text(nil)
+		elsewhere = elsewhere || hasMedia
+	}
+	if elsewhere {
+		report("media in choices and feedback is kept with the question")
+	}
+
+	media, problems, err := readMedia(files, href, item, used)
+	if err != nil {
+		return nil, nil, err
+	}
+	q.Media = media
+	issues = append(issues, problems...)
+
+	return &q, issues, nil
+}
+
+// mediaSources are the attributes naming the file of each media element.
+var mediaSources = map[string]string{"img": "src", "object": "data", "audio": "src", "video": "src", "source": "src"}
+
+// readMedia loads the files an item's media refers to, each once. Files
+// outside the package are reported, as they are not downloaded.
+func readMedia(files map[string]*zip.File, href string, item *node, used map[string]bool) ([]quizfile.Media, []Issue, error) {
+	var media []quizfile.Media
+	var issues []Issue
+	names := make(map[string]bool)
+	loaded := make(map[string]bool)
+
+	var walk func(*node)
+	var err error
+	walk = func(n *node) {
+		for _, c := range n.children {
+			if err != nil {
+				return
+			}
+			walk(c)
+		}
+		src := n.attrs[mediaSources[n.name]]
+		if src == "" || err != nil {
+			return
+		}
+		if u, parseErr := url.Parse(src); parseErr != nil || u.Scheme != "" || strings.HasPrefix(src, "/") {
+			issues = append(issues, Issue{File: href, Message: fmt.Sprintf("media %s is outside the package and is not kept", src)})
+			return
+		}
+		if unescaped, unescapeErr := url.PathUnescape(src); unescapeErr == nil {
+			src = unescaped
+		}
+
+		// Media is relative to the item
+		name := path.Join(path.Dir(href), src)
+		if loaded[name] {
+			return
+		}
+		loaded[name] = true
+
+		f, ok := files[name]
+		if !ok {
+			err = fmt.Errorf("%s: media %s is missing from the package", href, name)
+			return
+		}
+		if f.UncompressedSize64 > quizfile.MaxMediaSize {
+			issues = append(issues, Issue{File: name, Message: fmt.Sprintf("media larger than %d MB is not kept", quizfile.MaxMediaSize>>20)})
+			return
+		}
+		var m quizfile.Media
+		m, err = readMediaFile(f)
+		if err != nil {
+			return
+		}
+		m.Name = mediaName(name, names)
+		media = append(media, m)
+		used[name] = true
+	}
+	walk(item)
+
+	return media, issues, err
+}
+
+func readMediaFile(f *zip.File) (quizfile.Media, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return quizfile.Media{}, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, quizfile.MaxMediaSize+1))
+	if err != nil {
+		return quizfile.Media{}, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	if len(data) == 0 || len(data) > quizfile.MaxMediaSize {
+		return quizfile.Media{}, fmt.Errorf("%s: media must be between 1 byte and %d MB", f.Name, quizfile.MaxMediaSize>>20)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(f.Name))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return quizfile.Media{ContentType: contentType, Data: data}, nil
+}
+
+// mediaName names a media file after its base name, changed to the
+// characters media names allow and made unique among names.
+func mediaName(file string, names map[string]bool) string {
+	base := strings.Map(func(r rune) rune {
+		if r < 128 && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_') {
+			return r
+		}
+		return '-'
+	}, path.Base(file))
+	base = strings.TrimLeft(base, ".")
+	if len(base) > 200 {
+		base = base[len(base)-200:]
+	}
+	if base == "" {
+		base = "media"
+	}
+
+	name := base
+	for n := 2; names[name]; n++ {
+		name = fmt.Sprintf("%d-%s", n, base)
+	}
+	names[name] = true
+	return name
+}
+
+// score finds the points for the correct response: its value in the
+// response mapping, or else the item's MAXSCORE outcome.
+func score(item, declaration *node, correct string) (float64, bool) {
+	if mapping := declaration.child("mapping"); mapping != nil {
+		for _, entry := range mapping.all("mapentry") {
+			if entry.attrs["mapkey"] == correct {
+				points, err := strconv.ParseFloat(entry.attrs["mappedvalue"], 64)
+				return points, err == nil && points > 0
+			}
+		}
+	}
+
+	for _, outcome := range item.all("outcomedeclaration") {
+		if outcome.attrs["identifier"] != "MAXSCORE" {
+			continue
+		}
+		if value := outcome.child("defaultvalue"); value != nil {
+			if v := value.child("value"); v != nil {
+				text, _ := v.text(nil)
+				points, err := strconv.ParseFloat(text, 64)
+				return points, err == nil && points > 0
+			}
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,300 @@
+package qti
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The content models below are transcribed from the IMS schemas that Write
+// names in xsi:schemaLocation: imsqti_v2p1.xsd, imsqti_asiv3p0_v1p0.xsd and
+// the content packaging schema imscp_v1p1.xsd. They cover every element
+// quiz packages are made of: the manifest, assessment tests, assessment
+// items, their declarations and single choice interactions. Elements the
+// models do not name, such as the XHTML content of an item body or the
+// rules of response processing, are not checked further.
+//
+// Read validates every file of a package against them before importing
+// anything, and Write validates every file it writes.
+
+// schemaNamespaces are the namespaces each version's documents are read
+// in. QTI 2.2 is a compatible revision of 2.1.
+var schemaNamespaces = map[Version]struct{ qti, manifest []string }{
+	V21: {
+		qti:      []string{"http://www.imsglobal.org/xsd/imsqti_v2p1", "http://www.imsglobal.org/xsd/imsqti_v2p2"},
+		manifest: []string{"http://www.imsglobal.org/xsd/imscp_v1p1"},
+	},
+	V30: {
+		qti:      []string{"http://www.imsglobal.org/xsd/imsqtiasi_v3p0"},
+		manifest: []string{"http://www.imsglobal.org/xsd/qti/qtiv3p0/imscp_v1p1", "http://www.imsglobal.org/xsd/imscp_v1p1"},
+	},
+}
+
+// many is the maximum of a child that may repeat without limit.
+const many = -1
+
+// child is a particle of an element's sequence: one of names, occurring
+// min to max times.
+type child struct {
+	names    []string
+	min, max int
+}
+
+func one(names ...string) child      { return child{names, 1, 1} }
+func optional(names ...string) child { return child{names, 0, 1} }
+func anyOf(names ...string) child    { return child{names, 0, many} }
+func some(names ...string) child     { return child{names, 1, many} }
+
+// model is the content model of an element, by normalised name.
+type model struct {
+	// required are the attributes the element must have, in every version
+	// or only in v21 or v30.
+	required, v21, v30 []string
+	// enums lists the values an attribute can take.
+	enums map[string][]string
+	// booleans and floats are attributes of those types.
+	booleans, floats []string
+	// sequence is the element's children, in order. Elements without a
+	// sequence have mixed or open content, which is not checked.
+	sequence []child
+	// leaf elements hold text only.
+	leaf bool
+}
+
+var (
+	cardinalities = []string{"single", "multiple", "ordered", "record"}
+	baseTypes     = []string{
+		"identifier", "boolean", "integer", "float", "string", "point", "pair",
+		"directedPair", "duration", "file", "uri", "intOrIdentifier",
+		"directed-pair", "int-or-identifier",
+	}
+)
+
+var models = map[string]model{
+	// imscp_v1p1.xsd
+	"manifest": {
+		required: []string{"identifier"},
+		sequence: []child{optional("metadata"), one("organizations"), one("resources"), anyOf("manifest")},
+	},
+	"organizations": {sequence: []child{anyOf("organization")}},
+	"resources":     {sequence: []child{anyOf("resource")}},
+	"resource": {
+		required: []string{"identifier", "type"},
+		sequence: []child{optional("metadata"), anyOf("file"), anyOf("dependency")},
+	},
+	"file":       {required: []string{"href"}, sequence: []child{optional("metadata")}},
+	"dependency": {required: []string{"identifierref"}, sequence: []child{}},
+
+	// Assessment tests
+	"assessmenttest": {
+		required: []string{"identifier", "title"},
+		sequence: []child{
+			anyOf("contextdeclaration"), anyOf("outcomedeclaration"), optional("timelimits"),
+			anyOf("stylesheet"), anyOf("rubricblock"), some("testpart"),
+			optional("outcomeprocessing"), anyOf("testfeedback"),
+		},
+	},
+	"testpart": {
+		required: []string{"identifier", "navigationmode", "submissionmode"},
+		enums: map[string][]string{
+			"navigationmode": {"linear", "nonlinear"},
+			"submissionmode": {"individual", "simultaneous"},
+		},
+		sequence: []child{
+			anyOf("precondition"), anyOf("branchrule"), optional("itemsessioncontrol"),
+			optional("timelimits"), anyOf("rubricblock"),
+			some("assessmentsection", "assessmentsectionref"), anyOf("testfeedback"),
+		},
+	},
+	"assessmentsection": {
+		required: []string{"identifier", "title", "visible"},
+		booleans: []string{"visible", "fixed", "keeptogether", "required"},
+		sequence: []child{
+			anyOf("precondition"), anyOf("branchrule"), optional("itemsessioncontrol"),
+			optional("timelimits"), optional("selection"), optional("ordering"), anyOf("rubricblock"),
+			anyOf("assessmentitemref", "assessmentsection", "assessmentsectionref"),
+		},
+	},
+	"assessmentitemref": {
+		required: []string{"identifier", "href"},
+		booleans: []string{"fixed", "required"},
+		sequence: []child{
+			anyOf("precondition"), anyOf("branchrule"), optional("itemsessioncontrol"),
+			optional("timelimits"), anyOf("variablemapping"), anyOf("weight"), anyOf("templatedefault"),
+		},
+	},
+
+	// Assessment items
+	"assessmentitem": {
+		required: []string{"identifier", "title"},
+		v21:      []string{"adaptive", "timedependent"},
+		v30:      []string{"timedependent"},
+		booleans: []string{"adaptive", "timedependent"},
+		sequence: []child{
+			anyOf("contextdeclaration"), anyOf("responsedeclaration"), anyOf("outcomedeclaration"),
+			anyOf("templatedeclaration"), optional("templateprocessing"), anyOf("assessmentstimulusref"),
+			optional("companionmaterialsinfo"), anyOf("stylesheet"), optional("itembody"),
+			optional("cataloginfo"), optional("responseprocessing"), anyOf("modalfeedback"),
+		},
+	},
+	"responsedeclaration": {
+		required: []string{"identifier", "cardinality"},
+		enums:    map[string][]string{"cardinality": cardinalities, "basetype": baseTypes},
+		sequence: []child{optional("defaultvalue"), optional("correctresponse"), optional("mapping"), optional("areamapping")},
+	},
+	"outcomedeclaration": {
+		required: []string{"identifier", "cardinality"},
+		enums:    map[string][]string{"cardinality": cardinalities, "basetype": baseTypes},
+		floats:   []string{"normalmaximum", "normalminimum", "masterymaximum"},
+		sequence: []child{optional("defaultvalue"), optional("matchtable", "interpolationtable")},
+	},
+	"defaultvalue":    {sequence: []child{some("value")}},
+	"correctresponse": {sequence: []child{some("value")}},
+	"value":           {leaf: true},
+	"mapping": {
+		floats:   []string{"lowerbound", "upperbound", "defaultvalue"},
+		sequence: []child{some("mapentry")},
+	},
+	"mapentry": {
+		required: []string{"mapkey", "mappedvalue"},
+		floats:   []string{"mappedvalue"},
+		booleans: []string{"casesensitive"},
+		sequence: []child{},
+	},
+	"choiceinteraction": {
+		required: []string{"responseidentifier"},
+		v21:      []string{"shuffle", "maxchoices"},
+		booleans: []string{"shuffle"},
+		sequence: []child{optional("prompt"), some("simplechoice")},
+	},
+	"simplechoice": {
+		required: []string{"identifier"},
+		booleans: []string{"fixed"},
+		enums:    map[string][]string{"showhide": {"show", "hide"}},
+	},
+	"modalfeedback": {
+		required: []string{"outcomeidentifier", "showhide", "identifier"},
+		enums:    map[string][]string{"showhide": {"show", "hide"}},
+	},
+
+	// XHTML media in item content
+	"img":    {required: []string{"src", "alt"}, sequence: []child{}},
+	"object": {required: []string{"data", "type"}},
+}
+
+// validate checks a document of a package against the content models,
+// for the version its namespace names. It returns every problem, with file
+// as their file.
+func validate(root *node, file string) []Issue {
+	var issues []Issue
+	report := func(format string, args ...any) {
+		issues = append(issues, Issue{File: file, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var version Version
+	if root.name == "manifest" {
+		known := append(slices.Clip(schemaNamespaces[V21].manifest), schemaNamespaces[V30].manifest...)
+		if !slices.Contains(known, root.space) {
+			report("<manifest> is in namespace %q, not a content packaging namespace", root.space)
+		}
+	} else {
+		var ok bool
+		version, ok = versionOf(root)
+		if !ok {
+			report("<%s> is in namespace %q, not a QTI 2.1 or 3.0 namespace", root.name, root.space)
+			return issues
+		}
+	}
+
+	var walk func(n *node, path string)
+	walk = func(n *node, path string) {
+		path += "/" + n.name
+		m, ok := models[n.name]
+		if !ok {
+			for _, c := range n.children {
+				walk(c, path)
+			}
+			return
+		}
+
+		required := m.required
+		switch version {
+		case V21:
+			required = append(slices.Clip(required), m.v21...)
+		case V30:
+			required = append(slices.Clip(required), m.v30...)
+		}
+		for _, a := range required {
+			if _, ok := n.attrs[a]; !ok {
+				report("%s: attribute %s is required", path, a)
+			}
+		}
+		for a, values := range m.enums {
+			if v, ok := n.attrs[a]; ok && !slices.Contains(values, v) {
+				report("%s: %s is %q, want one of %s", path, a, v, strings.Join(values, ", "))
+			}
+		}
+		for _, a := range m.booleans {
+			if v, ok := n.attrs[a]; ok && v != "true" && v != "false" && v != "1" && v != "0" {
+				report("%s: %s is %q, want true or false", path, a, v)
+			}
+		}
+		for _, a := range m.floats {
+			if v, ok := n.attrs[a]; ok {
+				if _, err := strconv.ParseFloat(v, 64); err != nil {
+					report("%s: %s is %q, want a number", path, a, v)
+				}
+			}
+		}
+
+		if m.leaf && len(n.children) > 0 {
+			report("%s: holds <%s>, want text only", path, n.children[0].name)
+		}
+		if m.sequence != nil {
+			for _, problem := range matchSequence(m.sequence, n.children) {
+				report("%s: %s", path, problem)
+			}
+		}
+
+		for _, c := range n.children {
+			walk(c, path)
+		}
+	}
+	walk(root, "")
+
+	return issues
+}
+
+// matchSequence checks children against a sequence, in order.
+func matchSequence(sequence []child, children []*node) []string {
+	var problems []string
+	i := 0
+	for _, particle := range sequence {
+		count := 0
+		for i < len(children) && slices.Contains(particle.names, children[i].name) {
+			count++
+			i++
+		}
+		if count < particle.min {
+			problems = append(problems, fmt.Sprintf("<%s> is required", strings.Join(particle.names, "> or <")))
+		}
+		if particle.max != many && count > particle.max {
+			problems = append(problems, fmt.Sprintf("<%s> can appear only %d time(s)", particle.names[0], particle.max))
+		}
+	}
+	if i < len(children) {
+		problems = append(problems, fmt.Sprintf("<%s> is not allowed here", children[i].name))
+	}
+	return problems
+}
+
+// versionOf detects the version of a QTI document from its namespace.
+func versionOf(root *node) (Version, bool) {
+	for _, v := range []Version{V21, V30} {
+		if slices.Contains(schemaNamespaces[v].qti, root.space) {
+			return v, true
+		}
+	}
+	return "", false
+}
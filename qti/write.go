@@ -0,0 +1,345 @@
+package qti
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+)
+
+// namespaces of each version: the content package manifest, the QTI
+// elements and where their schema is published.
+var namespaces = map[Version]struct {
+	manifest, qti, schema, template, itemType, testType string
+}{
+	V21: {
+		manifest: "http://www.imsglobal.org/xsd/imscp_v1p1",
+		qti:      "http://www.imsglobal.org/xsd/imsqti_v2p1",
+		schema:   "http://www.imsglobal.org/xsd/qti/qtiv2p1/imsqti_v2p1.xsd",
+		template: "http://www.imsglobal.org/question/qti_v2p1/rptemplates/map_response",
+		itemType: "imsqti_item_xmlv2p1",
+		testType: "imsqti_test_xmlv2p1",
+	},
+	V30: {
+		manifest: "http://www.imsglobal.org/xsd/qti/qtiv3p0/imscp_v1p1",
+		qti:      "http://www.imsglobal.org/xsd/imsqtiasi_v3p0",
+		schema:   "https://purl.imsglobal.org/spec/qti/v3p0/schema/xsd/imsqti_asiv3p0_v1p0.xsd",
+		template: "https://purl.imsglobal.org/spec/qti/v3p0/rptemplates/map_response.xml",
+		itemType: "imsqti_item_xmlv3p0",
+		testType: "imsqti_test_xmlv3p0",
+	},
+}
+
+// testFile is where Write puts the assessment test.
+const testFile = "assessment.xml"
+
+// Write writes quiz as a package of the given version: an item per
+// question, and an assessment test that keeps them in order. It returns
+// the content that packages cannot hold, such as hints, which is left out.
+// Question media is written next to the items and shown after the
+// question text.
+//
+// Item identifiers are the question IDs, changed where QTI does not allow
+// them: an ID that does not start with a letter is prefixed with "item-".
+func Write(w io.Writer, quiz quizfile.Quiz, version Version) ([]Issue, error) {
+	if !ValidVersion(version) {
+		return nil, fmt.Errorf("unsupported QTI version %q", version)
+	}
+	ns := namespaces[version]
+
+	var issues []Issue
+	if quiz.Description != "" || len(quiz.Tags) > 0 {
+		issues = append(issues, Issue{File: testFile, Message: "the quiz description and tags are not exported"})
+	}
+
+	zw := zip.NewWriter(w)
+	manifest := elem("manifest", "xmlns", ns.manifest, "identifier", "manifest-"+identifier(quiz.Slug))
+	manifest.add(elem("metadata").add(
+		elem("schema").text("QTI Package"),
+		elem("schemaversion").text(string(version)+".0"),
+	))
+	manifest.add(elem("organizations"))
+	resources := elem("resources")
+	test := elem("resource", "identifier", "test", "type", ns.testType, "href", testFile)
+	test.add(elem("file", "href", testFile))
+	resources.add(test)
+
+	section := element(version, "assessmentSection", "identifier", "section", "title", quiz.Title, "visible", "true")
+	used := make(map[string]bool)
+	for _, q := range quiz.Questions {
+		id := identifier(q.ID)
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", identifier(q.ID), n)
+		}
+		used[id] = true
+		href := "items/" + id + ".xml"
+
+		if len(q.Hints) > 0 {
+			issues = append(issues, Issue{File: href, Message: "hints are not exported"})
+		}
+		if q.Difficulty != nil || len(q.Tags) > 0 {
+			issues = append(issues, Issue{File: href, Message: "the question's difficulty and tags are not exported"})
+		}
+
+		err := writeFile(zw, href, item(version, id, q))
+		if err != nil {
+			return nil, err
+		}
+
+		res := elem("resource", "identifier", id, "type", ns.itemType, "href", href).add(elem("file", "href", href))
+		for _, m := range q.Media {
+			file := "items/" + mediaPath(id, m)
+			err = writeMedia(zw, file, m.Data)
+			if err != nil {
+				return nil, err
+			}
+			res.add(elem("file", "href", file))
+		}
+		resources.add(res)
+		test.add(elem("dependency", "identifierref", id))
+		section.add(element(version, "assessmentItemRef", "identifier", id, "href", href))
+	}
+	manifest.add(resources)
+
+	// The test's score is the sum of its items' scores
+	score := element(version, "outcomeProcessing").add(
+		element(version, "setOutcomeValue", "identifier", "SCORE").add(
+			element(version, "sum").add(element(version, "testVariables", "variableIdentifier", "SCORE")),
+		),
+	)
+	root := element(version, "assessmentTest", "identifier", "test", "title", quiz.Title).add(
+		element(version, "outcomeDeclaration", "identifier", "SCORE", "cardinality", "single", "baseType", "float"),
+		element(version, "testPart", "identifier", "part", "navigationMode", "nonlinear", "submissionMode", "simultaneous").add(section),
+		score,
+	)
+	err := writeFile(zw, testFile, qtiRoot(version, root))
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeFile(zw, "imsmanifest.xml", manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return issues, zw.Close()
+}
+
+// item builds the assessment item of a question. Its response is mapped to
+// the question's points, and its explanation is modal feedback that is
+// always shown: FEEDBACK is never set, so it never matches the feedback's
+// identifier.
+func item(version Version, id string, q quizfile.Question) *el {
+	points := strconv.FormatFloat(q.Points, 'f', -1, 64)
+
+	body := element(version, "itemBody")
+	for _, line := range strings.Split(q.QuestionText, "\n") {
+		body.add(elem("p").text(line))
+	}
+	for _, m := range q.Media {
+		// Media is XHTML, which is not renamed in QTI 3.0
+		src := mediaPath(id, m)
+		if strings.HasPrefix(m.ContentType, "image/") {
+			body.add(elem("p").add(elem("img", "src", src, "alt", m.Name)))
+		} else {
+			body.add(elem("p").add(elem("object", "data", src, "type", m.ContentType).text(m.Name)))
+		}
+	}
+	interaction := element(version, "choiceInteraction", "responseIdentifier", "RESPONSE", "shuffle", "false", "maxChoices", "1")
+	body.add(interaction)
+	for _, letter := range letters {
+		if option := q.Option(letter); option != "" {
+			interaction.add(element(version, "simpleChoice", "identifier", letter).text(option))
+		}
+	}
+
+	root := element(version, "assessmentItem", "identifier", id, "title", title(q.QuestionText), "adaptive", "false", "timeDependent", "false").add(
+		element(version, "responseDeclaration", "identifier", "RESPONSE", "cardinality", "single", "baseType", "identifier").add(
+			element(version, "correctResponse").add(element(version, "value").text(q.CorrectAnswer)),
+			element(version, "mapping", "defaultValue", "0").add(
+				element(version, "mapEntry", "mapKey", q.CorrectAnswer, "mappedValue", points),
+			),
+		),
+		element(version, "outcomeDeclaration", "identifier", "SCORE", "cardinality", "single", "baseType", "float"),
+		element(version, "outcomeDeclaration", "identifier", "MAXSCORE", "cardinality", "single", "baseType", "float").add(
+			element(version, "defaultValue").add(element(version, "value").text(points)),
+		),
+		element(version, "outcomeDeclaration", "identifier", "FEEDBACK", "cardinality", "single", "baseType", "identifier"),
+		body,
+		element(version, "responseProcessing", "template", namespaces[version].template),
+	)
+
+	if q.Explanation != "" {
+		feedback := element(version, "modalFeedback", "outcomeIdentifier", "FEEDBACK", "showHide", "hide", "identifier", "NONE")
+		content := feedback
+		if version == V30 {
+			// QTI 3.0 wraps feedback in a content body
+			content = element(version, "contentBody")
+			feedback.add(content)
+		}
+		for _, line := range strings.Split(q.Explanation, "\n") {
+			content.add(elem("p").text(line))
+		}
+		root.add(feedback)
+	}
+
+	return qtiRoot(version, root)
+}
+
+// mediaPath is where a question's media file is written, relative to its
+// item.
+func mediaPath(id string, m quizfile.Media) string {
+	return "media/" + id + "/" + m.Name
+}
+
+// qtiRoot adds the QTI namespace and schema location to a root element.
+func qtiRoot(version Version, root *el) *el {
+	ns := namespaces[version]
+	attrs := elem("", "xmlns", ns.qti, "xmlns:xsi", "http://www.w3.org/2001/XMLSchema-instance", "xsi:schemaLocation", ns.qti+" "+ns.schema).attrs
+	root.attrs = append(attrs, root.attrs...)
+	return root
+}
+
+// title shortens question text to an item title.
+func title(text string) string {
+	text, _, _ = strings.Cut(text, "\n")
+	if r := []rune(text); len(r) > 80 {
+		text = string(r[:77]) + "..."
+	}
+	return text
+}
+
+// identifier makes s a valid QTI identifier, which starts with a letter or
+// underscore and holds only letters, digits, underscores, hyphens and dots.
+func identifier(s string) string {
+	id := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' {
+			return r
+		}
+		return '-'
+	}, s)
+	if id == "" || !unicode.IsLetter([]rune(id)[0]) && id[0] != '_' {
+		id = "item-" + id
+	}
+	return id
+}
+
+// el is an element Write builds. Attributes keep their order, so the files
+// it writes do not change between runs.
+type el struct {
+	name     string
+	attrs    []xml.Attr
+	content  string
+	children []*el
+}
+
+// elem returns an element with the given name and attribute name and value
+// pairs, written as is.
+func elem(name string, attrs ...string) *el {
+	e := &el{name: name}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		e.attr(attrs[i], attrs[i+1])
+	}
+	return e
+}
+
+// element returns a QTI element, named for the version: QTI 2.1 names are
+// written as given, in camel case, and QTI 3.0 names are converted to
+// kebab case with a qti- prefix, attribute names without it.
+func element(version Version, name string, attrs ...string) *el {
+	if version == V21 {
+		return elem(name, attrs...)
+	}
+	for i := 0; i < len(attrs); i += 2 {
+		attrs[i] = kebab(attrs[i])
+	}
+	return elem("qti-"+kebab(name), attrs...)
+}
+
+func kebab(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsUpper(r) {
+			b.WriteByte('-')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (e *el) attr(name, value string) {
+	e.attrs = append(e.attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+// text sets the element's text, which is written alone on one line.
+func (e *el) text(s string) *el {
+	e.content = s
+	return e
+}
+
+func (e *el) add(children ...*el) *el {
+	e.children = append(e.children, children...)
+	return e
+}
+
+// writeFile writes a document to the package, after checking it against the
+// schema as Read does, so that packages Write makes can be read back.
+func writeFile(zw *zip.Writer, name string, root *el) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	root.write(&b, "")
+
+	n, err := parse(strings.NewReader(b.String()))
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if problems := validate(n, name); len(problems) > 0 {
+		return fmt.Errorf("%s breaks the QTI schema: %w", name, problems[0])
+	}
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeMedia(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (e *el) write(b *strings.Builder, indent string) {
+	b.WriteString(indent + "<" + e.name)
+	for _, a := range e.attrs {
+		b.WriteString(" " + a.Name.Local + `="`)
+		xml.EscapeText(b, []byte(a.Value))
+		b.WriteString(`"`)
+	}
+
+	switch {
+	case len(e.children) > 0:
+		b.WriteString(">\n")
+		for _, c := range e.children {
+			c.write(b, indent+"  ")
+		}
+		b.WriteString(indent + "</" + e.name + ">\n")
+	case e.content != "":
+		b.WriteString(">")
+		xml.EscapeText(b, []byte(e.content))
+		b.WriteString("</" + e.name + ">\n")
+	default:
+		b.WriteString("/>\n")
+	}
+}
@@ -0,0 +1,213 @@
+// Package quizfile reads quizzes from JSON and YAML files, so quiz content
+// can be kept in files and loaded into the database by the seeder.
+//
+// Each file holds one quiz. Quizzes are identified by their slug and
+// questions by an ID that is unique within the quiz, so a file can be loaded
+// again after editing and update the existing quiz in place.
+package quizfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Iknite-Space/sqlc-example-api/policy"
+	"github.com/Iknite-Space/sqlc-example-api/scoring"
+	"github.com/Iknite-Space/sqlc-example-api/slug"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxIDLength is the longest question ID the database can store.
+const MaxIDLength = 100
+
+// MaxOptionLength is the longest option, in characters, the database can
+// store.
+const MaxOptionLength = 255
+
+// MaxMediaSize is the largest media file a question can hold.
+const MaxMediaSize = 5 << 20
+
+// Quiz is a quiz as written in a quiz file. Optional settings left out of
+// the file take the same defaults as quizzes created through the API.
+type Quiz struct {
+	Slug               string     `json:"slug" yaml:"slug"`
+	Title              string     `json:"title" yaml:"title"`
+	Description        string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Difficulty         *int32     `json:"difficulty,omitempty" yaml:"difficulty,omitempty"`
+	PassPercent        *float64   `json:"pass_percent,omitempty" yaml:"pass_percent,omitempty"`
+	MaxAttempts        *int32     `json:"max_attempts,omitempty" yaml:"max_attempts,omitempty"`
+	CooldownSeconds    int32      `json:"cooldown_seconds,omitempty" yaml:"cooldown_seconds,omitempty"`
+	WrongAnswerPenalty float64    `json:"wrong_answer_penalty,omitempty" yaml:"wrong_answer_penalty,omitempty"`
+	HintPenalty        *float64   `json:"hint_penalty,omitempty" yaml:"hint_penalty,omitempty"`
+	RevealPolicy       string     `json:"reveal_policy,omitempty" yaml:"reveal_policy,omitempty"`
+	Tags               []string   `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Questions          []Question `json:"questions" yaml:"questions"`
+
+	// Path is the file the quiz was read from.
+	Path string `json:"-" yaml:"-"`
+}
+
+// Question is a multiple choice question in a quiz file. Its ID defaults to
+// a slug of the question text. Questions have two to four options, filled
+// from option_a, so true/false questions leave option_c and option_d empty.
+type Question struct {
+	ID            string   `json:"id,omitempty" yaml:"id,omitempty"`
+	QuestionText  string   `json:"question_text" yaml:"question_text"`
+	OptionA       string   `json:"option_a" yaml:"option_a"`
+	OptionB       string   `json:"option_b" yaml:"option_b"`
+	OptionC       string   `json:"option_c" yaml:"option_c"`
+	OptionD       string   `json:"option_d" yaml:"option_d"`
+	CorrectAnswer string   `json:"correct_answer" yaml:"correct_answer"`
+	Points        float64  `json:"points,omitempty" yaml:"points,omitempty"`
+	Explanation   string   `json:"explanation,omitempty" yaml:"explanation,omitempty"`
+	Hints         []string `json:"hints,omitempty" yaml:"hints,omitempty"`
+	Difficulty    *int32   `json:"difficulty,omitempty" yaml:"difficulty,omitempty"`
+	Tags          []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Media are files shown with the question, such as images. They come
+	// from QTI packages and bundles, quiz files do not hold them.
+	Media []Media `json:"media,omitempty" yaml:"-"`
+}
+
+// Media is a file shown with a question, named uniquely within it.
+type Media struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+}
+
+// Option returns the text of the option with the given letter, or "" for
+// an unused option or unknown letter.
+func (q Question) Option(letter string) string {
+	switch letter {
+	case "A":
+		return q.OptionA
+	case "B":
+		return q.OptionB
+	case "C":
+		return q.OptionC
+	case "D":
+		return q.OptionD
+	}
+	return ""
+}
+
+// IsQuizFile reports whether path has an extension ReadFile understands.
+func IsQuizFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// ReadFile reads and validates a quiz file. Unknown fields are rejected, so
+// a misspelt setting is reported instead of silently ignored.
+func ReadFile(path string) (Quiz, error) {
+	var q Quiz
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return q, err
+	}
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&q)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&q)
+	}
+	if err != nil {
+		return q, fmt.Errorf("%s: %w", path, err)
+	}
+
+	q.Path = path
+	q.SetDefaults()
+
+	// Prefix every problem with the file, so each reads on its own
+	problems := q.problems()
+	for i, err := range problems {
+		problems[i] = fmt.Errorf("%s: %w", path, err)
+	}
+
+	return q, errors.Join(problems...)
+}
+
+// ReadDir reads every quiz file under dir, in name order. All invalid files
+// are reported together, as are slugs used by more than one file.
+func ReadDir(dir string) ([]Quiz, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && IsQuizFile(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var quizzes []Quiz
+	var errs []error
+	seen := make(map[string]string)
+	for _, path := range paths {
+		q, err := ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, ok := seen[q.Slug]; ok {
+			errs = append(errs, fmt.Errorf("%s: slug %q is already used by %s", path, q.Slug, other))
+			continue
+		}
+		seen[q.Slug] = path
+		quizzes = append(quizzes, q)
+	}
+
+	return quizzes, errors.Join(errs...)
+}
+
+// SetDefaults fills in the optional settings left out of a quiz file.
+func (q *Quiz) SetDefaults() {
+	if q.Slug == "" {
+		q.Slug = slug.Make(q.Title)
+	}
+	if q.RevealPolicy == "" {
+		q.RevealPolicy = policy.RevealAfterAttempt
+	}
+	if q.HintPenalty == nil {
+		penalty := scoring.DefaultHintPenalty
+		q.HintPenalty = &penalty
+	}
+
+	for i := range q.Questions {
+		question := &q.Questions[i]
+		if question.ID == "" {
+			question.ID = QuestionID(question.QuestionText)
+		}
+		if question.Points == 0 {
+			question.Points = 1
+		}
+	}
+}
+
+// QuestionID derives a question ID from its text.
+func QuestionID(text string) string {
+	id := slug.Make(text)
+	if len(id) > MaxIDLength {
+		id = strings.TrimRight(id[:MaxIDLength], "-")
+	}
+	return id
+}
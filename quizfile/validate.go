@@ -0,0 +1,156 @@
+package quizfile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Iknite-Space/sqlc-example-api/difficulty"
+	"github.com/Iknite-Space/sqlc-example-api/policy"
+	"github.com/Iknite-Space/sqlc-example-api/slug"
+)
+
+// Validate checks a quiz against the rules the database and API enforce,
+// reporting every problem found.
+func (q Quiz) Validate() error {
+	return errors.Join(q.problems()...)
+}
+
+func (q Quiz) problems() []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if q.Title == "" {
+		fail("title is required")
+	}
+	if q.Slug == "" || q.Slug != slug.Make(q.Slug) {
+		fail("slug %q must be lowercase letters and digits separated by hyphens", q.Slug)
+	}
+	if !difficulty.Valid(q.Difficulty) {
+		fail("difficulty must be between %d and %d", difficulty.Min, difficulty.Max)
+	}
+	if q.PassPercent != nil && (*q.PassPercent < 0 || *q.PassPercent > 100) {
+		fail("pass_percent must be between 0 and 100")
+	}
+	if q.MaxAttempts != nil && *q.MaxAttempts < 1 {
+		fail("max_attempts must be at least 1")
+	}
+	if q.CooldownSeconds < 0 {
+		fail("cooldown_seconds cannot be negative")
+	}
+	if q.WrongAnswerPenalty < 0 {
+		fail("wrong_answer_penalty cannot be negative")
+	}
+	if q.HintPenalty != nil && (*q.HintPenalty < 0 || *q.HintPenalty > 1) {
+		fail("hint_penalty must be between 0 and 1")
+	}
+	if !policy.ValidRevealPolicy(q.RevealPolicy) {
+		fail("reveal_policy must be never, after_attempt or after_close")
+	}
+	for _, t := range q.Tags {
+		if slug.Make(t) == "" {
+			fail("tag %q has no letters or digits", t)
+		}
+	}
+
+	if len(q.Questions) == 0 {
+		fail("at least one question is required")
+	}
+
+	ids := make(map[string]int)
+	for i, question := range q.Questions {
+		n := i + 1
+		if first, ok := ids[question.ID]; ok {
+			fail("question %d: id %q is already used by question %d", n, question.ID, first)
+		}
+		ids[question.ID] = n
+
+		for _, err := range question.Problems() {
+			fail("question %d: %w", n, err)
+		}
+	}
+
+	return errs
+}
+
+// Problems lists everything wrong with a question, for callers that report
+// problems against their own positions, such as spreadsheet rows.
+func (q Question) Problems() []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if q.ID == "" || len(q.ID) > MaxIDLength {
+		fail("id must be between 1 and %d characters", MaxIDLength)
+	}
+	if q.QuestionText == "" {
+		fail("question_text is required")
+	}
+	if q.OptionA == "" || q.OptionB == "" {
+		fail("option_a and option_b are required")
+	}
+	if q.OptionC == "" && q.OptionD != "" {
+		fail("option_d needs option_c, options are filled in order")
+	}
+	for _, letter := range []string{"A", "B", "C", "D"} {
+		if utf8.RuneCountInString(q.Option(letter)) > MaxOptionLength {
+			fail("option_%s is longer than %d characters", strings.ToLower(letter), MaxOptionLength)
+		}
+	}
+	switch q.CorrectAnswer {
+	case "A", "B", "C", "D":
+		if q.Option(q.CorrectAnswer) == "" {
+			fail("correct_answer %s is an empty option", q.CorrectAnswer)
+		}
+	default:
+		fail("correct_answer must be A, B, C or D")
+	}
+	if q.Points <= 0 {
+		fail("points must be positive")
+	}
+	if !difficulty.Valid(q.Difficulty) {
+		fail("difficulty must be between %d and %d", difficulty.Min, difficulty.Max)
+	}
+	for _, t := range q.Tags {
+		if slug.Make(t) == "" {
+			fail("tag %q has no letters or digits", t)
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, m := range q.Media {
+		if !ValidMediaName(m.Name) {
+			fail("media name %q must be 1 to 255 letters, digits, dots, hyphens and underscores", m.Name)
+		}
+		if names[m.Name] {
+			fail("media name %q is used twice", m.Name)
+		}
+		names[m.Name] = true
+		if m.ContentType == "" {
+			fail("media %q needs a content type", m.Name)
+		}
+		if len(m.Data) == 0 || len(m.Data) > MaxMediaSize {
+			fail("media %q must be between 1 byte and %d MB", m.Name, MaxMediaSize>>20)
+		}
+	}
+
+	return errs
+}
+
+// ValidMediaName reports whether name can name a question's media file. It
+// is used in URLs, so it is kept to characters that need no escaping.
+func ValidMediaName(name string) bool {
+	if name == "" || len(name) > 255 || name[0] == '.' {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,389 @@
+// Package quizsync reconciles the database with a directory of quiz files
+// (see package quizfile), so quizzes can be kept and reviewed in git.
+//
+// A sync is planned before anything is written: the plan lists every quiz,
+// question and tag to create, update or delete. The directory is the whole
+// truth for the quizzes the seeder and sync have written, which are marked
+// as file-managed: those missing from it are deleted, as are questions and
+// tags missing from their file. Quizzes created through the API or imported
+// from a bundle are not marked and are never deleted, nor are questions
+// created through the API. A quiz in the directory whose slug matches an
+// unmarked quiz takes it over and marks it. Quizzes are compared and written
+// as the seeder and bundle imports do (see package reconcile), and are
+// locked while planning.
+//
+// Deleting a quiz or question, or changing a question's options or correct
+// answer, breaks the attempts recorded against it. Such changes to a quiz
+// that has attempts are marked destructive, and callers should refuse them
+// unless forced.
+package quizsync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+	"github.com/Iknite-Space/sqlc-example-api/reconcile"
+)
+
+// Action is what a change does.
+type Action string
+
+// Actions, with the sign the plan shows them with.
+const (
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+)
+
+var signs = map[Action]string{Create: "+", Update: "~", Delete: "-"}
+
+// Kind is what a change is made to.
+type Kind string
+
+// Kinds of change.
+const (
+	KindQuiz     Kind = "quiz"
+	KindQuestion Kind = "question"
+	KindTag      Kind = "tag"
+)
+
+// Change is a planned change to a quiz, one of its questions or a tag on
+// either. Tags are only created (added) and deleted (removed). Question is
+// the question's ID in its file.
+type Change struct {
+	Action   Action `json:"action"`
+	Kind     Kind   `json:"kind"`
+	Quiz     string `json:"quiz"`
+	Question string `json:"question,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	// Title is the quiz's title or the question's text.
+	Title string `json:"title"`
+	// Fields are the settings an update changes.
+	Fields      []string `json:"fields,omitempty"`
+	Destructive bool     `json:"destructive,omitempty"`
+
+	// id is the database ID of what is deleted, or of the tag to remove.
+	id       string
+	quiz     *quizfile.Quiz
+	question *quizfile.Question
+	tagName  string
+}
+
+// QuizPlan is the plan for one quiz.
+type QuizPlan struct {
+	Slug string `json:"slug"`
+	// Path is the quiz's file, empty for a quiz being deleted.
+	Path     string   `json:"path,omitempty"`
+	Attempts int64    `json:"attempts"`
+	Changes  []Change `json:"changes"`
+
+	// id is the quiz's database ID, empty until it is created.
+	id string
+	// questions are the database IDs of the quiz's questions, by their ID
+	// in the file.
+	questions map[string]string
+}
+
+// Plan is every change a sync makes, by quiz. Quizzes without changes are
+// left out.
+type Plan struct {
+	Quizzes []QuizPlan `json:"quizzes"`
+}
+
+// Build plans the changes that make the database match quizzes, which
+// should be every quiz file in the directory being synced.
+func Build(ctx context.Context, querier repo.Querier, quizzes []quizfile.Quiz) (Plan, error) {
+	var plan Plan
+
+	for i := range quizzes {
+		q := &quizzes[i]
+		qp, err := planQuiz(ctx, querier, q)
+		if err != nil {
+			return plan, fmt.Errorf("%s: %w", q.Path, err)
+		}
+		if len(qp.Changes) > 0 {
+			plan.Quizzes = append(plan.Quizzes, qp)
+		}
+	}
+
+	existing, err := querier.ListFileManagedQuizzes(ctx)
+	if err != nil {
+		return plan, err
+	}
+	for _, quiz := range existing {
+		inFiles := slices.ContainsFunc(quizzes, func(q quizfile.Quiz) bool { return q.Slug == *quiz.Slug })
+		if inFiles {
+			continue
+		}
+
+		attempts, err := querier.CountQuizAttempts(ctx, quiz.ID)
+		if err != nil {
+			return plan, err
+		}
+		plan.Quizzes = append(plan.Quizzes, QuizPlan{
+			Slug:     *quiz.Slug,
+			Attempts: attempts,
+			Changes: []Change{{
+				Action:      Delete,
+				Kind:        KindQuiz,
+				Quiz:        *quiz.Slug,
+				Title:       quiz.Title,
+				Destructive: attempts > 0,
+				id:          quiz.ID,
+			}},
+			id: quiz.ID,
+		})
+	}
+
+	return plan, nil
+}
+
+func planQuiz(ctx context.Context, querier repo.Querier, q *quizfile.Quiz) (QuizPlan, error) {
+	qp := QuizPlan{Slug: q.Slug, Path: q.Path, questions: make(map[string]string)}
+
+	existing, found, err := reconcile.FindQuiz(ctx, querier, q.Slug)
+	if err != nil {
+		return qp, err
+	}
+	if !found {
+		qp.Changes = append(qp.Changes, Change{Action: Create, Kind: KindQuiz, Quiz: q.Slug, Title: q.Title, quiz: q})
+		qp.Changes = append(qp.Changes, tagChanges(q.Slug, "", nil, q.Tags)...)
+		for i := range q.Questions {
+			fq := &q.Questions[i]
+			qp.Changes = append(qp.Changes, Change{Action: Create, Kind: KindQuestion, Quiz: q.Slug, Question: fq.ID, Title: fq.QuestionText, question: fq})
+			qp.Changes = append(qp.Changes, tagChanges(q.Slug, fq.ID, nil, fq.Tags)...)
+		}
+		return qp, nil
+	}
+	qp.id = existing.ID
+
+	qp.Attempts, err = querier.CountQuizAttempts(ctx, existing.ID)
+	if err != nil {
+		return qp, err
+	}
+
+	fields := reconcile.QuizFields(existing, *q)
+	if !reconcile.Managed(existing) {
+		fields = append(fields, "managed_by")
+	}
+	if len(fields) > 0 {
+		qp.Changes = append(qp.Changes, Change{Action: Update, Kind: KindQuiz, Quiz: q.Slug, Title: q.Title, Fields: fields, quiz: q})
+	}
+
+	tags, err := querier.ListTagsForQuiz(ctx, existing.ID)
+	if err != nil {
+		return qp, err
+	}
+	qp.Changes = append(qp.Changes, tagChanges(q.Slug, "", tags, q.Tags)...)
+
+	questions, err := querier.ListQuestionsWithAnswers(ctx, existing.ID)
+	if err != nil {
+		return qp, err
+	}
+	byID := make(map[string]repo.Question, len(questions))
+	for _, question := range questions {
+		if question.ExternalID != nil {
+			byID[*question.ExternalID] = question
+			qp.questions[*question.ExternalID] = question.ID
+		}
+	}
+
+	keep := make(map[string]bool, len(q.Questions))
+	for i := range q.Questions {
+		fq := &q.Questions[i]
+		question, found := byID[fq.ID]
+		keep[fq.ID] = true
+
+		var tags []repo.Tag
+		if !found {
+			qp.Changes = append(qp.Changes, Change{Action: Create, Kind: KindQuestion, Quiz: q.Slug, Question: fq.ID, Title: fq.QuestionText, question: fq})
+		} else {
+			if fields := reconcile.QuestionFields(question, *fq); len(fields) > 0 {
+				qp.Changes = append(qp.Changes, Change{
+					Action:      Update,
+					Kind:        KindQuestion,
+					Quiz:        q.Slug,
+					Question:    fq.ID,
+					Title:       fq.QuestionText,
+					Fields:      fields,
+					Destructive: qp.Attempts > 0 && slices.ContainsFunc(fields, reconcile.AnswerField),
+					question:    fq,
+				})
+			}
+			tags, err = querier.ListTagsForQuestion(ctx, question.ID)
+			if err != nil {
+				return qp, err
+			}
+		}
+		qp.Changes = append(qp.Changes, tagChanges(q.Slug, fq.ID, tags, fq.Tags)...)
+	}
+
+	for _, question := range reconcile.StaleQuestions(questions, keep) {
+		qp.Changes = append(qp.Changes, Change{
+			Action:      Delete,
+			Kind:        KindQuestion,
+			Quiz:        q.Slug,
+			Question:    *question.ExternalID,
+			Title:       question.QuestionText,
+			Destructive: qp.Attempts > 0,
+			id:          question.ID,
+		})
+	}
+
+	return qp, nil
+}
+
+// tagChanges adds the tags named in a file that are missing from the
+// database and removes the ones the file no longer lists.
+func tagChanges(quizSlug, questionID string, current []repo.Tag, names []string) []Change {
+	var changes []Change
+	add, remove := reconcile.TagChanges(current, names)
+	for _, t := range add {
+		changes = append(changes, Change{Action: Create, Kind: KindTag, Quiz: quizSlug, Question: questionID, Tag: t.Slug, tagName: t.Name})
+	}
+	for _, t := range remove {
+		changes = append(changes, Change{Action: Delete, Kind: KindTag, Quiz: quizSlug, Question: questionID, Tag: t.Slug, id: t.ID})
+	}
+	return changes
+}
+
+// Empty reports whether the database already matches the files.
+func (p Plan) Empty() bool {
+	return len(p.Quizzes) == 0
+}
+
+// Count returns the number of changes with the given action.
+func (p Plan) Count(action Action) int {
+	n := 0
+	for _, qp := range p.Quizzes {
+		for _, c := range qp.Changes {
+			if c.Action == action {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Destructive lists the changes that break recorded attempts.
+func (p Plan) Destructive() []Change {
+	var changes []Change
+	for _, qp := range p.Quizzes {
+		for _, c := range qp.Changes {
+			if c.Destructive {
+				changes = append(changes, c)
+			}
+		}
+	}
+	return changes
+}
+
+// Write prints the plan, a quiz at a time, marking each change with +, ~
+// or - and destructive changes with !.
+func (p Plan) Write(w io.Writer) error {
+	for _, qp := range p.Quizzes {
+		where := qp.Path
+		if where == "" {
+			where = "no longer in the files"
+		}
+		attempts := ""
+		if qp.Attempts > 0 {
+			attempts = fmt.Sprintf(", %d attempts", qp.Attempts)
+		}
+		_, err := fmt.Fprintf(w, "\n%s (%s%s)\n", qp.Slug, where, attempts)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range qp.Changes {
+			line := "  " + signs[c.Action] + " "
+			switch {
+			case c.Kind == KindTag && c.Question != "":
+				line += fmt.Sprintf("tag question %s %s", c.Question, c.Tag)
+			case c.Kind == KindTag:
+				line += "tag quiz " + c.Tag
+			case c.Kind == KindQuestion:
+				line += fmt.Sprintf("question %s %q", c.Question, c.Title)
+			default:
+				line += fmt.Sprintf("quiz %q", c.Title)
+			}
+			if len(c.Fields) > 0 {
+				line += fmt.Sprintf(" %v", c.Fields)
+			}
+			if c.Destructive {
+				line += "  ! destructive"
+			}
+			_, err = fmt.Fprintln(w, line)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\nPlan: %d to create, %d to update, %d to delete.\n", p.Count(Create), p.Count(Update), p.Count(Delete))
+	return err
+}
+
+// Apply makes the planned changes. Run it with a querier bound to the
+// transaction the plan was built in, so nothing changes in between and a
+// failed sync leaves the database as it was.
+func Apply(ctx context.Context, querier repo.Querier, plan Plan) error {
+	tags := make(reconcile.Tags)
+	for _, qp := range plan.Quizzes {
+		for _, c := range qp.Changes {
+			err := apply(ctx, querier, &qp, c, tags)
+			if err != nil {
+				return fmt.Errorf("%s: %s: %w", qp.Slug, c.Action, err)
+			}
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, querier repo.Querier, qp *QuizPlan, c Change, tags reconcile.Tags) error {
+	switch {
+	case c.Kind == KindTag:
+		return applyTag(ctx, querier, qp, c, tags)
+
+	case c.Kind == KindQuestion && c.Action == Delete:
+		return querier.DeleteQuestion(ctx, c.id)
+
+	case c.Kind == KindQuestion:
+		question, err := reconcile.UpsertQuestion(ctx, querier, qp.id, *c.question)
+		qp.questions[c.question.ID] = question.ID
+		return err
+
+	case c.Action == Delete:
+		return querier.DeleteQuiz(ctx, c.id)
+
+	default:
+		quiz, err := reconcile.UpsertFileQuiz(ctx, querier, *c.quiz)
+		qp.id = quiz.ID
+		return err
+	}
+}
+
+func applyTag(ctx context.Context, querier repo.Querier, qp *QuizPlan, c Change, tags reconcile.Tags) error {
+	questionID := qp.questions[c.Question]
+
+	if c.Action == Delete {
+		if c.Question != "" {
+			return querier.RemoveQuestionTag(ctx, repo.RemoveQuestionTagParams{QuestionID: questionID, TagID: c.id})
+		}
+		return querier.RemoveQuizTag(ctx, repo.RemoveQuizTagParams{QuizID: qp.id, TagID: c.id})
+	}
+
+	tagID, err := tags.ID(ctx, querier, c.Tag, c.tagName)
+	if err != nil {
+		return err
+	}
+
+	if c.Question != "" {
+		return querier.AddQuestionTag(ctx, repo.AddQuestionTagParams{QuestionID: questionID, TagID: tagID})
+	}
+	return querier.AddQuizTag(ctx, repo.AddQuizTagParams{QuizID: qp.id, TagID: tagID})
+}
@@ -0,0 +1,214 @@
+// Package reconcile holds what the seeder, bundle imports and quiz sync
+// share when they make the database match a quiz file (see package
+// quizfile): finding and locking the quiz, comparing quizzes and questions
+// with the file, writing them and matching their tags.
+//
+// Quizzes loaded from a file are identified by their slug and questions by
+// their external ID. Questions created through the API have no external ID,
+// so they are never stale and never deleted. A quiz can get a slug without
+// being kept in a file, such as when a bundle is imported, so the seeder and
+// sync mark the quizzes they write as file-managed (see UpsertFileQuiz) and
+// only ever delete those.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+	"github.com/Iknite-Space/sqlc-example-api/slug"
+	"github.com/jackc/pgx/v5"
+)
+
+// FindQuiz finds the quiz with the given slug and locks it until the
+// transaction ends, so it cannot change while it is reconciled. found is
+// false when no quiz has the slug.
+func FindQuiz(ctx context.Context, querier repo.Querier, quizSlug string) (quiz repo.Quiz, found bool, err error) {
+	quiz, err = querier.GetQuizBySlug(ctx, quizSlug)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return quiz, false, nil
+	}
+	return quiz, err == nil, err
+}
+
+// QuizFields lists the settings of an existing quiz that differ from its
+// file, by their name in the file.
+func QuizFields(existing repo.Quiz, q quizfile.Quiz) []string {
+	var fields []string
+	changed := func(name string, differ bool) {
+		if differ {
+			fields = append(fields, name)
+		}
+	}
+	changed("title", existing.Title != q.Title)
+	changed("description", existing.Description != q.Description)
+	changed("difficulty", !equalPtr(existing.Difficulty, q.Difficulty))
+	changed("pass_percent", !equalPtr(existing.PassPercent, q.PassPercent))
+	changed("max_attempts", !equalPtr(existing.MaxAttempts, q.MaxAttempts))
+	changed("cooldown_seconds", existing.CooldownSeconds != q.CooldownSeconds)
+	changed("wrong_answer_penalty", existing.WrongAnswerPenalty != q.WrongAnswerPenalty)
+	changed("hint_penalty", existing.HintPenalty != *q.HintPenalty)
+	changed("reveal_policy", existing.RevealPolicy != q.RevealPolicy)
+	return fields
+}
+
+// QuestionFields lists the fields of an existing question that differ from
+// its file.
+func QuestionFields(existing repo.Question, q quizfile.Question) []string {
+	var fields []string
+	changed := func(name string, differ bool) {
+		if differ {
+			fields = append(fields, name)
+		}
+	}
+	changed("question_text", existing.QuestionText != q.QuestionText)
+	changed("option_a", existing.OptionA != q.OptionA)
+	changed("option_b", existing.OptionB != q.OptionB)
+	changed("option_c", existing.OptionC != q.OptionC)
+	changed("option_d", existing.OptionD != q.OptionD)
+	changed("correct_answer", existing.CorrectAnswer != q.CorrectAnswer)
+	changed("points", existing.Points != q.Points)
+	changed("explanation", existing.Explanation != q.Explanation)
+	changed("hints", !slices.Equal(existing.Hints, q.Hints))
+	changed("difficulty", !equalPtr(existing.Difficulty, q.Difficulty))
+	return fields
+}
+
+// AnswerField reports whether changing a question field changes what
+// recorded answers mean: answers are stored as option letters.
+func AnswerField(field string) bool {
+	switch field {
+	case "option_a", "option_b", "option_c", "option_d", "correct_answer":
+		return true
+	}
+	return false
+}
+
+// UpsertQuiz creates the quiz with q's slug, or updates its settings.
+func UpsertQuiz(ctx context.Context, querier repo.Querier, q quizfile.Quiz) (repo.Quiz, error) {
+	return querier.UpsertQuiz(ctx, repo.UpsertQuizParams{
+		Slug:               q.Slug,
+		Title:              q.Title,
+		Description:        q.Description,
+		WrongAnswerPenalty: q.WrongAnswerPenalty,
+		PassPercent:        q.PassPercent,
+		MaxAttempts:        q.MaxAttempts,
+		CooldownSeconds:    q.CooldownSeconds,
+		RevealPolicy:       q.RevealPolicy,
+		HintPenalty:        *q.HintPenalty,
+		Difficulty:         q.Difficulty,
+	})
+}
+
+// ManagedByFiles is the managed_by of quizzes kept in quiz files.
+const ManagedByFiles = "files"
+
+// Managed reports whether quiz is kept in a quiz file by the seeder or sync.
+func Managed(quiz repo.Quiz) bool {
+	return quiz.ManagedBy != nil && *quiz.ManagedBy == ManagedByFiles
+}
+
+// UpsertFileQuiz upserts the quiz as UpsertQuiz does and marks it as
+// file-managed, so the seeder and sync delete it once its file is gone.
+// Bundle imports write quizzes with UpsertQuiz and leave them unmarked.
+func UpsertFileQuiz(ctx context.Context, querier repo.Querier, q quizfile.Quiz) (repo.Quiz, error) {
+	quiz, err := UpsertQuiz(ctx, querier, q)
+	if err != nil || Managed(quiz) {
+		return quiz, err
+	}
+
+	err = querier.MarkQuizFileManaged(ctx, quiz.ID)
+	if err != nil {
+		return quiz, err
+	}
+	managedBy := ManagedByFiles
+	quiz.ManagedBy = &managedBy
+	return quiz, nil
+}
+
+// UpsertQuestion creates the question with q's ID in a quiz, or updates it.
+func UpsertQuestion(ctx context.Context, querier repo.Querier, quizID string, q quizfile.Question) (repo.Question, error) {
+	return querier.UpsertQuestion(ctx, repo.UpsertQuestionParams{
+		QuizID:        quizID,
+		ExternalID:    q.ID,
+		QuestionText:  q.QuestionText,
+		OptionA:       q.OptionA,
+		OptionB:       q.OptionB,
+		OptionC:       q.OptionC,
+		OptionD:       q.OptionD,
+		CorrectAnswer: q.CorrectAnswer,
+		Points:        q.Points,
+		Explanation:   q.Explanation,
+		Hints:         q.Hints,
+		Difficulty:    q.Difficulty,
+	})
+}
+
+// StaleQuestions returns the questions that were loaded from a file but
+// whose external ID is no longer in keep. Questions created through the API
+// are left out.
+func StaleQuestions(questions []repo.Question, keep map[string]bool) []repo.Question {
+	var stale []repo.Question
+	for _, q := range questions {
+		if q.ExternalID != nil && !keep[*q.ExternalID] {
+			stale = append(stale, q)
+		}
+	}
+	return stale
+}
+
+// TagChanges compares a quiz's or question's tags with the tag names in its
+// file. add are the tags to add, with their slug and name but no ID yet,
+// in the order the file names them, and remove the tags to remove.
+func TagChanges(current []repo.Tag, names []string) (add, remove []repo.Tag) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		tagSlug := slug.Make(name)
+		if wanted[tagSlug] {
+			continue
+		}
+		wanted[tagSlug] = true
+		if !slices.ContainsFunc(current, func(t repo.Tag) bool { return t.Slug == tagSlug }) {
+			add = append(add, repo.Tag{Name: name, Slug: tagSlug})
+		}
+	}
+
+	for _, t := range current {
+		if !wanted[t.Slug] {
+			remove = append(remove, t)
+		}
+	}
+	return add, remove
+}
+
+// Tags holds the IDs of tags already found or created, by slug, so a run
+// looks each one up once.
+type Tags map[string]string
+
+// ID finds the tag with the given slug, creating it if it does not exist
+// yet.
+func (t Tags) ID(ctx context.Context, querier repo.Querier, tagSlug, name string) (string, error) {
+	if id, ok := t[tagSlug]; ok {
+		return id, nil
+	}
+
+	tag, err := querier.GetTagBySlug(ctx, tagSlug)
+	if errors.Is(err, pgx.ErrNoRows) {
+		tag, err = querier.CreateTag(ctx, repo.CreateTagParams{Name: name, Slug: tagSlug})
+	}
+	if err != nil {
+		return "", err
+	}
+
+	t[tagSlug] = tag.ID
+	return tag.ID, nil
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,147 @@
+package report
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+	"github.com/Iknite-Space/sqlc-example-api/scoring"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	attemptColumns = []any{"attempt_id", "quiz_id", "quiz_title", "user_name", "mode", "score", "max_points", "score_percent", "passed", "total_questions", "created_at"}
+	answerColumns  = []any{"question_id", "question_text", "answer", "is_correct", "points_awarded"}
+)
+
+// Attempt is an attempt as exported to NDJSON, one per line.
+type Attempt struct {
+	ID             string           `json:"id"`
+	QuizID         string           `json:"quiz_id"`
+	QuizTitle      string           `json:"quiz_title"`
+	UserName       string           `json:"user_name"`
+	Mode           string           `json:"mode"`
+	Score          float64          `json:"score"`
+	MaxPoints      float64          `json:"max_points"`
+	ScorePercent   float64          `json:"score_percent"`
+	Passed         bool             `json:"passed"`
+	TotalQuestions int32            `json:"total_questions"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	Answers        []Answer         `json:"answers,omitempty"`
+}
+
+// Answer is a question's answer in an exported attempt.
+type Answer struct {
+	QuestionID    string  `json:"question_id"`
+	QuestionText  string  `json:"question_text"`
+	Answer        string  `json:"answer"`
+	IsCorrect     bool    `json:"is_correct"`
+	PointsAwarded float64 `json:"points_awarded"`
+}
+
+// Attempts writes the attempts the filter selects, oldest first. With
+// answers, CSV and XLSX files have a row per answer, repeating the attempt's
+// columns, and NDJSON attempts list their answers. Attempts with no stored
+// answers have a single row without them.
+func Attempts(ctx context.Context, stream repo.Streamer, w io.Writer, format Format, filter Filter, answers bool) error {
+	arg := repo.StreamAttemptExportParams{
+		QuizID:      optional(filter.QuizID),
+		CreatedFrom: timestamp(filter.From),
+		CreatedTo:   timestamp(filter.To),
+		WithAnswers: answers,
+	}
+
+	if format == NDJSON {
+		return ndjsonAttempts(ctx, stream, w, arg)
+	}
+
+	t, err := newTable(w, format, "Attempts")
+	if err != nil {
+		return err
+	}
+	header := attemptColumns
+	if answers {
+		header = append(append([]any{}, attemptColumns...), answerColumns...)
+	}
+	err = t.write(header...)
+	if err != nil {
+		return err
+	}
+
+	err = stream.StreamAttemptExport(ctx, arg, func(row repo.StreamAttemptExportRow) error {
+		cells := []any{
+			row.ID, row.QuizID, row.QuizTitle, row.UserName, row.Mode,
+			row.Score, row.MaxPoints, percent(row.Score, row.MaxPoints), row.Passed, row.TotalQuestions,
+			timeCell(row.CreatedAt),
+		}
+		if answers {
+			cells = append(cells, deref(row.QuestionID), deref(row.QuestionText), deref(row.Answer), deref(row.IsCorrect), deref(row.PointsAwarded))
+		}
+		return t.write(cells...)
+	})
+	if err != nil {
+		return err
+	}
+	return t.close()
+}
+
+// ndjsonAttempts writes an attempt per line. An attempt's answers arrive
+// on consecutive rows, so only one attempt is held at a time.
+func ndjsonAttempts(ctx context.Context, stream repo.Streamer, w io.Writer, arg repo.StreamAttemptExportParams) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	var current *Attempt
+
+	err := stream.StreamAttemptExport(ctx, arg, func(row repo.StreamAttemptExportRow) error {
+		if current != nil && current.ID != row.ID {
+			err := enc.Encode(current)
+			if err != nil {
+				return err
+			}
+			current = nil
+		}
+		if current == nil {
+			current = &Attempt{
+				ID:             row.ID,
+				QuizID:         row.QuizID,
+				QuizTitle:      row.QuizTitle,
+				UserName:       row.UserName,
+				Mode:           row.Mode,
+				Score:          row.Score,
+				MaxPoints:      row.MaxPoints,
+				ScorePercent:   percent(row.Score, row.MaxPoints),
+				Passed:         row.Passed,
+				TotalQuestions: row.TotalQuestions,
+				CreatedAt:      row.CreatedAt,
+			}
+		}
+		if row.QuestionID != nil {
+			current.Answers = append(current.Answers, Answer{
+				QuestionID:    *row.QuestionID,
+				QuestionText:  *row.QuestionText,
+				Answer:        *row.Answer,
+				IsCorrect:     *row.IsCorrect,
+				PointsAwarded: *row.PointsAwarded,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		err = enc.Encode(current)
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// percent is a score as a percentage, rounded to one decimal.
+func percent(score, maxPoints float64) float64 {
+	return math.Round(scoring.Percent(score, maxPoints)*10) / 10
+}
@@ -0,0 +1,119 @@
+package report
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/Iknite-Space/sqlc-example-api/db/repo"
+)
+
+// GradebookEntry is a player's row of a gradebook as exported to NDJSON, one
+// per line. It lists only the quizzes the player attempted.
+type GradebookEntry struct {
+	UserName string          `json:"user_name"`
+	Quizzes  []GradebookCell `json:"quizzes"`
+}
+
+// GradebookCell is a player's best result on a quiz.
+type GradebookCell struct {
+	QuizID           string  `json:"quiz_id"`
+	Title            string  `json:"title"`
+	Attempts         int64   `json:"attempts"`
+	BestScorePercent float64 `json:"best_score_percent"`
+}
+
+// Gradebook writes a row per player with their best score, as a
+// percentage, on each quiz the filter selects. CSV and XLSX files have a
+// column per quiz that was attempted in the period, headed by its title,
+// and leave the quizzes a player did not attempt empty.
+func Gradebook(ctx context.Context, querier repo.Querier, stream repo.Streamer, w io.Writer, format Format, filter Filter) error {
+	// The columns are known before the first player is read
+	quizzes, err := querier.ListGradebookQuizzes(ctx, repo.ListGradebookQuizzesParams{
+		CreatedFrom:  timestamp(filter.From),
+		CreatedTo:    timestamp(filter.To),
+		CollectionID: optional(filter.CollectionID),
+	})
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int, len(quizzes))
+	for i, quiz := range quizzes {
+		columns[quiz.ID] = i
+	}
+
+	var flush func(GradebookEntry) error
+	var done func() error
+	if format == NDJSON {
+		bw := bufio.NewWriter(w)
+		enc := json.NewEncoder(bw)
+		flush = func(entry GradebookEntry) error { return enc.Encode(entry) }
+		done = bw.Flush
+	} else {
+		t, err := newTable(w, format, "Gradebook")
+		if err != nil {
+			return err
+		}
+		header := []any{"user_name"}
+		for _, quiz := range quizzes {
+			header = append(header, quiz.Title)
+		}
+		err = t.write(header...)
+		if err != nil {
+			return err
+		}
+
+		flush = func(entry GradebookEntry) error {
+			cells := make([]any, 1+len(quizzes))
+			cells[0] = entry.UserName
+			for _, cell := range entry.Quizzes {
+				cells[1+columns[cell.QuizID]] = cell.BestScorePercent
+			}
+			return t.write(cells...)
+		}
+		done = t.close
+	}
+
+	// A player's results arrive together, so one row is held at a time
+	var current *GradebookEntry
+	err = stream.StreamGradebook(ctx, repo.StreamGradebookParams{
+		CreatedFrom:  timestamp(filter.From),
+		CreatedTo:    timestamp(filter.To),
+		CollectionID: optional(filter.CollectionID),
+	}, func(row repo.StreamGradebookRow) error {
+		col, ok := columns[row.QuizID]
+		if !ok {
+			// Attempted after the columns were listed
+			return nil
+		}
+		if current != nil && current.UserName != row.UserName {
+			err := flush(*current)
+			if err != nil {
+				return err
+			}
+			current = nil
+		}
+		if current == nil {
+			current = &GradebookEntry{UserName: row.UserName}
+		}
+		current.Quizzes = append(current.Quizzes, GradebookCell{
+			QuizID:           row.QuizID,
+			Title:            quizzes[col].Title,
+			Attempts:         row.Attempts,
+			BestScorePercent: math.Round(row.BestScorePercent*10) / 10,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		err = flush(*current)
+		if err != nil {
+			return err
+		}
+	}
+	return done()
+}
@@ -0,0 +1,134 @@
+// Package report exports quiz results for spreadsheets: attempts, with
+// their answers, and gradebooks with one row per player and one column per
+// quiz, as CSV, NDJSON or XLSX.
+//
+// Rows are streamed from the database to the writer as they are read (see
+// repo.Streamer), so exports of any size run in constant memory. Only
+// official, submitted attempts are exported, as in the quiz statistics.
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Iknite-Space/sqlc-example-api/xlsx"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Format is an export file format.
+type Format string
+
+// Supported formats.
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+	XLSX   Format = "xlsx"
+)
+
+// ValidFormat reports whether f is a supported format.
+func ValidFormat(f Format) bool {
+	return f == CSV || f == NDJSON || f == XLSX
+}
+
+// ContentType is the media type of files in the format.
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case NDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+}
+
+// Filter selects the attempts a report covers. Empty fields do not filter.
+type Filter struct {
+	// QuizID limits an attempt export to one quiz.
+	QuizID string
+	// CollectionID limits a gradebook to the quizzes of a collection.
+	CollectionID string
+	// From and To limit the attempts to those started at or after From and
+	// before To.
+	From, To time.Time
+}
+
+func timestamp(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t, Valid: !t.IsZero()}
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// table writes the rows of a CSV file or XLSX worksheet.
+type table interface {
+	write(cells ...any) error
+	close() error
+}
+
+func newTable(w io.Writer, format Format, name string) (table, error) {
+	if format == XLSX {
+		xw, err := xlsx.NewWriter(w, name)
+		if err != nil {
+			return nil, err
+		}
+		return xlsxTable{xw}, nil
+	}
+	return csvTable{csv.NewWriter(w)}, nil
+}
+
+type xlsxTable struct{ w *xlsx.Writer }
+
+func (t xlsxTable) write(cells ...any) error { return t.w.WriteRow(cells...) }
+func (t xlsxTable) close() error             { return t.w.Close() }
+
+type csvTable struct{ w *csv.Writer }
+
+func (t csvTable) write(cells ...any) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		switch v := cell.(type) {
+		case nil:
+		case string:
+			record[i] = v
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case int:
+			record[i] = strconv.Itoa(v)
+		case int32:
+			record[i] = strconv.Itoa(int(v))
+		case int64:
+			record[i] = strconv.FormatInt(v, 10)
+		case bool:
+			record[i] = strconv.FormatBool(v)
+		}
+	}
+	return t.w.Write(record)
+}
+
+func (t csvTable) close() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+// timeCell formats a timestamp so that spreadsheets sort it.
+func timeCell(t pgtype.Timestamp) any {
+	if !t.Valid {
+		return nil
+	}
+	return t.Time.Format(time.DateTime)
+}
+
+// deref returns the value p points to, or nil for an empty cell.
+func deref[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
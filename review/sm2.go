@@ -0,0 +1,70 @@
+// Package review schedules spaced repetition of missed questions with the
+// SM-2 algorithm.
+package review
+
+import "math"
+
+// Recall quality ranges from 0 (complete blackout) to 5 (perfect recall).
+// Anything below PassingQuality counts as forgotten.
+const (
+	MinQuality     = 0
+	MaxQuality     = 5
+	PassingQuality = 3
+)
+
+// Quality used when recall is graded from an answer rather than rated by the
+// player.
+const (
+	CorrectQuality = 4
+	WrongQuality   = 1
+)
+
+// minEase keeps intervals of hard questions from collapsing.
+const minEase = 1.3
+
+// Card is the scheduling state of a question in a player's review queue.
+type Card struct {
+	EaseFactor   float64
+	IntervalDays int32
+	Repetitions  int32
+	Lapses       int32
+}
+
+// ValidQuality reports whether q is a recall quality from 0 to 5.
+func ValidQuality(q int) bool {
+	return q >= MinQuality && q <= MaxQuality
+}
+
+// QualityFor grades recall from whether the answer was correct.
+func QualityFor(correct bool) int {
+	if correct {
+		return CorrectQuality
+	}
+	return WrongQuality
+}
+
+// Schedule returns card after a review with recall quality q. Remembered
+// questions come back after 1 day, then 6 days, then at intervals growing by
+// the ease factor; forgotten ones start over the next day.
+func Schedule(card Card, q int) Card {
+	if q < PassingQuality {
+		card.Repetitions = 0
+		card.IntervalDays = 1
+		card.Lapses++
+	} else {
+		switch card.Repetitions {
+		case 0:
+			card.IntervalDays = 1
+		case 1:
+			card.IntervalDays = 6
+		default:
+			card.IntervalDays = int32(math.Round(float64(card.IntervalDays) * card.EaseFactor))
+		}
+		card.Repetitions++
+	}
+
+	d := float64(MaxQuality - q)
+	card.EaseFactor = math.Max(minEase, card.EaseFactor+0.1-d*(0.08+d*0.02))
+
+	return card
+}
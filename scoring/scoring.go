@@ -0,0 +1,116 @@
+// Package scoring grades quiz answers against weighted questions.
+package scoring
+
+// DefaultHintPenalty is the fraction of a question's points lost per hint
+// when a quiz does not set its own hint penalty.
+const DefaultHintPenalty = 0.25
+
+// Question is the information needed to grade a single answer.
+type Question struct {
+	ID            string
+	CorrectAnswer string
+	Points        float64
+	// HintsUsed is how many hints the player revealed for this question.
+	HintsUsed int
+}
+
+// Rules are the per-quiz grading settings.
+type Rules struct {
+	// WrongAnswerPenalty is the fraction of a question's points lost for a
+	// wrong answer.
+	WrongAnswerPenalty float64
+	// HintPenalty is the fraction of a question's points lost for each hint
+	// revealed.
+	HintPenalty float64
+}
+
+// Result is the outcome of grading a single question.
+type Result struct {
+	QuestionID string  `json:"question_id"`
+	Correct    bool    `json:"correct"`
+	Skipped    bool    `json:"skipped"`
+	Points     float64 `json:"points"`
+	HintsUsed  int     `json:"hints_used,omitempty"`
+}
+
+// Summary is the outcome of grading a whole attempt.
+type Summary struct {
+	Score     float64  `json:"score"`
+	MaxPoints float64  `json:"max_points"`
+	Results   []Result `json:"results"`
+}
+
+// AvailablePoints returns what a correct answer is still worth after the
+// player has revealed hintsUsed hints.
+func AvailablePoints(points float64, hintsUsed int, hintPenalty float64) float64 {
+	return points * max(0, 1-float64(hintsUsed)*hintPenalty)
+}
+
+// ValidAnswer reports whether answer is the letter of one of a question's
+// options, given in order from A. Questions with fewer than four options
+// leave the rest empty, and their letters are not answers.
+func ValidAnswer(answer string, options ...string) bool {
+	for i, option := range options {
+		if answer == string(rune('A'+i)) {
+			return option != ""
+		}
+	}
+	return false
+}
+
+// GradeAnswer grades a single answer. A correct answer earns the question's
+// points less any hint penalty, a skipped question earns nothing and a wrong
+// answer loses the wrong answer penalty times the question's points.
+func GradeAnswer(q Question, answer string, rules Rules) Result {
+	result := Result{QuestionID: q.ID, HintsUsed: q.HintsUsed}
+
+	switch {
+	case answer == "":
+		result.Skipped = true
+	case answer == q.CorrectAnswer:
+		result.Correct = true
+		result.Points = AvailablePoints(q.Points, q.HintsUsed, rules.HintPenalty)
+	default:
+		result.Points = -q.Points * rules.WrongAnswerPenalty
+	}
+
+	return result
+}
+
+// Grade grades every question of an attempt. Answers are keyed by question
+// ID. The total score never drops below zero, even with negative marking.
+func Grade(questions []Question, answers map[string]string, rules Rules) Summary {
+	summary := Summary{Results: make([]Result, 0, len(questions))}
+
+	for _, q := range questions {
+		result := GradeAnswer(q, answers[q.ID], rules)
+		summary.Score += result.Points
+		summary.MaxPoints += q.Points
+		summary.Results = append(summary.Results, result)
+	}
+
+	if summary.Score < 0 {
+		summary.Score = 0
+	}
+
+	return summary
+}
+
+// Percent returns score as a percentage of maxPoints.
+func Percent(score, maxPoints float64) float64 {
+	if maxPoints <= 0 {
+		return 0
+	}
+
+	return score / maxPoints * 100
+}
+
+// Passed reports whether score meets the pass mark. A quiz without a pass
+// mark cannot be passed.
+func Passed(score, maxPoints float64, passPercent *float64) bool {
+	if passPercent == nil {
+		return false
+	}
+
+	return Percent(score, maxPoints) >= *passPercent
+}
@@ -0,0 +1,29 @@
+// Package slug turns human readable names into stable, URL friendly
+// identifiers.
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Make lowercases s and joins its letters and digits with single hyphens,
+// so "World History & Art" becomes "world-history-art".
+func Make(s string) string {
+	var b strings.Builder
+	hyphen := false
+
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,158 @@
+package trivia
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+	"github.com/Iknite-Space/sqlc-example-api/xlsx"
+)
+
+// kahootColumns are the columns of Kahoot's quiz spreadsheet template,
+// found by how their headers start: Kahoot adds limits to them, such as
+// "Question - max 120 characters".
+type kahootColumns struct {
+	header   int
+	question int
+	answers  [4]int
+	time     int
+	correct  int
+	image    int
+}
+
+// kahootHeader finds the header row of a Kahoot spreadsheet. The template
+// has instructions above it.
+func kahootHeader(rows []xlsx.Row) (kahootColumns, bool) {
+	for i, row := range rows {
+		cols := kahootColumns{header: i, question: -1, answers: [4]int{-1, -1, -1, -1}, time: -1, correct: -1, image: -1}
+		for j, cell := range row.Cells {
+			cell = strings.ToLower(strings.TrimSpace(cell))
+			switch {
+			case strings.HasPrefix(cell, "question"):
+				cols.question = j
+			case strings.HasPrefix(cell, "correct answer"):
+				cols.correct = j
+			case strings.HasPrefix(cell, "time limit"):
+				cols.time = j
+			case strings.HasPrefix(cell, "image"):
+				cols.image = j
+			case strings.HasPrefix(cell, "answer "):
+				n, err := strconv.Atoi(strings.Fields(cell)[1])
+				if err == nil && n >= 1 && n <= len(cols.answers) {
+					cols.answers[n-1] = j
+				}
+			}
+		}
+		if cols.question >= 0 && cols.correct >= 0 && cols.answers[0] >= 0 && cols.answers[1] >= 0 {
+			return cols, true
+		}
+	}
+	return kahootColumns{}, false
+}
+
+// IsKahoot reports whether spreadsheet rows follow Kahoot's quiz template.
+func IsKahoot(rows []xlsx.Row) bool {
+	_, ok := kahootHeader(rows)
+	return ok
+}
+
+// ReadKahoot reads the rows of a spreadsheet made from Kahoot's quiz
+// template. Answers are shuffled into the quiz's options, except True and
+// False, which stay in that order. Questions with several right answers
+// are skipped, and time limits and images are reported as dropped.
+//
+// Kahoot spreadsheets have no categories, so the questions have no tags.
+func ReadKahoot(rows []xlsx.Row) ([]Item, []Issue, error) {
+	cols, ok := kahootHeader(rows)
+	if !ok {
+		return nil, nil, fmt.Errorf("not a Kahoot spreadsheet: no row with question, answer and correct answer columns")
+	}
+
+	var items []Item
+	var issues []Issue
+	report := func(line int, format string, args ...any) {
+		issues = append(issues, Issue{Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	seen := newDeduper()
+	timed := false
+	for _, row := range rows[cols.header+1:] {
+		cell := func(i int) string {
+			if i < 0 || i >= len(row.Cells) {
+				return ""
+			}
+			return strings.TrimSpace(row.Cells[i])
+		}
+
+		q := quizfile.Question{QuestionText: cell(cols.question), Points: 1}
+		var answers []string
+		var numbers []int
+		for n, i := range cols.answers {
+			if answer := cell(i); answer != "" {
+				answers = append(answers, answer)
+				numbers = append(numbers, n+1)
+			}
+		}
+		// The template comes with numbered rows that are left empty
+		if q.QuestionText == "" && len(answers) == 0 {
+			continue
+		}
+
+		if cell(cols.time) != "" && !timed {
+			timed = true
+			report(row.Number, "time limits are not kept, quizzes have no time limit per question")
+		}
+		if cell(cols.image) != "" {
+			report(row.Number, "the image is dropped")
+		}
+
+		var right []int
+		for _, v := range strings.Split(cell(cols.correct), ",") {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil || n != float64(int(n)) {
+				report(row.Number, "skipped, correct answer %q is not an answer number", v)
+				right = nil
+				break
+			}
+			right = append(right, int(n))
+		}
+		if len(right) != 1 {
+			if len(right) > 1 {
+				report(row.Number, "skipped, quiz questions have one right answer, not %d", len(right))
+			} else if cell(cols.correct) == "" {
+				report(row.Number, "skipped, no correct answer")
+			}
+			continue
+		}
+
+		correct := -1
+		for i, n := range numbers {
+			if n == right[0] {
+				correct = i
+			}
+		}
+		if correct < 0 {
+			report(row.Number, "skipped, correct answer %d is empty", right[0])
+			continue
+		}
+		if len(answers) < 2 {
+			report(row.Number, "skipped, quiz questions have at least two answers")
+			continue
+		}
+
+		wrong := append(append([]string{}, answers[:correct]...), answers[correct+1:]...)
+		place(&q, answers[correct], wrong)
+
+		if issue := seen.add(row.Number, &q); issue != nil {
+			issues = append(issues, *issue)
+			continue
+		}
+		items = append(items, Item{Line: row.Number, Question: q})
+	}
+
+	return items, issues, nil
+}
@@ -0,0 +1,182 @@
+package trivia
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+)
+
+// openTDBQuestion is a question as the Open Trivia DB API returns it, with
+// its text HTML escaped.
+type openTDBQuestion struct {
+	Type             string   `json:"type"`
+	Difficulty       string   `json:"difficulty"`
+	Category         string   `json:"category"`
+	Question         string   `json:"question"`
+	CorrectAnswer    string   `json:"correct_answer"`
+	IncorrectAnswers []string `json:"incorrect_answers"`
+}
+
+// openTDBDifficulty maps Open Trivia DB difficulties onto the 1 to 5 scale
+// of package difficulty.
+var openTDBDifficulty = map[string]int32{"easy": 2, "medium": 3, "hard": 4}
+
+// ReadOpenTDB reads an Open Trivia DB dump: either an API response, with
+// the questions in "results", or a JSON array of questions. Text is HTML
+// unescaped, categories become tags and difficulties are mapped from easy,
+// medium and hard to 2, 3 and 4. Multiple choice questions have their
+// options shuffled, and true/false questions get the options True and
+// False.
+//
+// Dumps must use the API's default encoding: responses requested with
+// encode=url3986 or encode=base64 are not decoded.
+func ReadOpenTDB(r io.Reader) ([]Item, []Issue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw, err := openTDBResults(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var items []Item
+	var issues []Issue
+	seen := newDeduper()
+	for _, result := range raw {
+		// Questions are reported on the line their object starts on
+		line := bytes.Count(data[:result.offset], []byte("\n")) + 1
+
+		var tq openTDBQuestion
+		err = json.Unmarshal(result.message, &tq)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		q, problem := tq.question()
+		if problem != "" {
+			issues = append(issues, Issue{Line: line, Message: problem})
+			continue
+		}
+		if issue := seen.add(line, &q); issue != nil {
+			issues = append(issues, *issue)
+			continue
+		}
+		items = append(items, Item{Line: line, Question: q})
+	}
+
+	return items, issues, nil
+}
+
+type rawResult struct {
+	offset  int64
+	message json.RawMessage
+}
+
+// openTDBResults finds the questions in a dump, with the offset each starts
+// at.
+func openTDBResults(data []byte) ([]rawResult, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("not an Open Trivia DB dump: %w", err)
+	}
+
+	// An API response is an object with a response code and the results
+	if tok == json.Delim('{') {
+		found := false
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if key == "results" {
+				found = true
+				break
+			}
+
+			var value json.RawMessage
+			err = dec.Decode(&value)
+			if err != nil {
+				return nil, err
+			}
+			if key == "response_code" && string(value) != "0" {
+				return nil, fmt.Errorf("the dump is an Open Trivia DB error response (response_code %s)", value)
+			}
+		}
+		if !found {
+			return nil, errors.New(`not an Open Trivia DB dump: no "results"`)
+		}
+		tok, err = dec.Token()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if tok != json.Delim('[') {
+		return nil, errors.New("not an Open Trivia DB dump: want an array of questions or an API response")
+	}
+
+	var results []rawResult
+	for dec.More() {
+		var result rawResult
+		err = dec.Decode(&result.message)
+		if err != nil {
+			return nil, err
+		}
+		// The decoder is past the object, which ends its message
+		result.offset = dec.InputOffset() - int64(len(result.message))
+		results = append(results, result)
+	}
+	if len(results) == 0 {
+		return nil, errors.New("the dump has no questions")
+	}
+	return results, nil
+}
+
+// question converts an Open Trivia DB question, or explains why it cannot.
+func (tq openTDBQuestion) question() (quizfile.Question, string) {
+	q := quizfile.Question{
+		QuestionText: html.UnescapeString(tq.Question),
+		Points:       1,
+	}
+	if category := html.UnescapeString(tq.Category); category != "" {
+		q.Tags = []string{category}
+	}
+	if d, ok := openTDBDifficulty[tq.Difficulty]; ok {
+		q.Difficulty = &d
+	}
+
+	correct := html.UnescapeString(tq.CorrectAnswer)
+	wrong := make([]string, len(tq.IncorrectAnswers))
+	for i, answer := range tq.IncorrectAnswers {
+		wrong[i] = html.UnescapeString(answer)
+	}
+
+	switch tq.Type {
+	case "boolean":
+		if len(wrong) != 1 || !trueFalse(correct, wrong[0]) {
+			return q, "skipped, the answers of a true/false question must be True and False"
+		}
+		place(&q, correct, wrong)
+	case "multiple":
+		if len(wrong) < 1 || len(wrong) > len(letters)-1 {
+			return q, fmt.Sprintf("skipped, it has %d wrong answers and quiz questions can have 1 to %d", len(wrong), len(letters)-1)
+		}
+		for _, answer := range wrong {
+			if answer == correct {
+				return q, fmt.Sprintf("skipped, the right answer %q is also a wrong one", correct)
+			}
+		}
+		place(&q, correct, wrong)
+	default:
+		return q, fmt.Sprintf("skipped, unknown question type %q", tq.Type)
+	}
+
+	return q, ""
+}
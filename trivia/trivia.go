@@ -0,0 +1,109 @@
+// Package trivia reads ready-made trivia questions: Open Trivia DB JSON
+// dumps and Kahoot quiz spreadsheets.
+//
+// Both formats list the right answer apart from the wrong ones, or in a
+// fixed place, so options are shuffled into A to D. The shuffle is seeded
+// by the question text, so a file imported again puts every option where it
+// was before. Questions that do not fit a quiz, and questions that repeat,
+// are skipped and reported as issues.
+package trivia
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/Iknite-Space/sqlc-example-api/quizfile"
+)
+
+// Item is a question read from a file, with the line it starts on.
+type Item struct {
+	Line     int
+	Question quizfile.Question
+}
+
+// Issue is something in a file that was skipped or dropped.
+type Issue struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (i Issue) Error() string {
+	return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+}
+
+var letters = []string{"A", "B", "C", "D"}
+
+// place fills a question's options with the right answer and the wrong ones
+// in an order that only depends on the question text. True/false questions
+// always have True as option A and False as option B.
+func place(q *quizfile.Question, correct string, wrong []string) {
+	if len(wrong) == 1 && trueFalse(correct, wrong[0]) {
+		q.OptionA, q.OptionB = "True", "False"
+		q.CorrectAnswer = "A"
+		if strings.EqualFold(correct, "false") {
+			q.CorrectAnswer = "B"
+		}
+		return
+	}
+
+	options := append([]string{correct}, wrong...)
+
+	// order[i] is the option placed at letter i; option 0 is the right one
+	h := fnv.New64a()
+	h.Write([]byte(q.QuestionText))
+	order := rand.New(rand.NewPCG(h.Sum64(), 0)).Perm(len(options))
+
+	fields := []*string{&q.OptionA, &q.OptionB, &q.OptionC, &q.OptionD}
+	for i, option := range order {
+		*fields[i] = options[option]
+		if option == 0 {
+			q.CorrectAnswer = letters[i]
+		}
+	}
+}
+
+func trueFalse(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	return a == "true" && b == "false" || a == "false" && b == "true"
+}
+
+// deduper skips questions that repeat one read before and numbers the IDs
+// of different questions whose text makes the same ID.
+type deduper struct {
+	texts map[string]int
+	ids   map[string]int
+}
+
+func newDeduper() *deduper {
+	return &deduper{texts: make(map[string]int), ids: make(map[string]int)}
+}
+
+// add sets the question's ID, or returns an issue when the question was
+// already read.
+func (d *deduper) add(line int, q *quizfile.Question) *Issue {
+	text := normalize(q.QuestionText)
+	if first, ok := d.texts[text]; ok {
+		return &Issue{Line: line, Message: fmt.Sprintf("skipped, the same question is on line %d", first)}
+	}
+	d.texts[text] = line
+
+	id := quizfile.QuestionID(q.QuestionText)
+	d.ids[id]++
+	if n := d.ids[id]; n > 1 {
+		suffix := fmt.Sprintf("-%d", n)
+		if len(id)+len(suffix) > quizfile.MaxIDLength {
+			id = strings.TrimRight(id[:quizfile.MaxIDLength-len(suffix)], "-")
+		}
+		id += suffix
+	}
+	q.ID = id
+	return nil
+}
+
+// normalize reduces question text to what decides whether two questions
+// are the same: case and spacing are ignored.
+func normalize(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
@@ -0,0 +1,243 @@
+// Package xlsx reads and writes Excel workbooks well enough to exchange
+// tables of plain values. Formatting, formulas and dates are not
+// interpreted: each cell is read as the text or number Excel stored for it,
+// and written as text, a number or a boolean.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Row is a row of the first worksheet. Number is the row number Excel shows.
+type Row struct {
+	Number int
+	Cells  []string
+}
+
+// Read returns the non-empty rows of a workbook's first worksheet.
+func Read(r io.ReaderAt, size int64) ([]Row, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not an xlsx workbook: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheet, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var strs []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		strs, err = sharedStrings(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheet]
+	if !ok {
+		return nil, fmt.Errorf("worksheet %s is missing", sheet)
+	}
+	return readSheet(f, strs)
+}
+
+// firstSheet finds the file holding the first sheet listed in the workbook.
+func firstSheet(files map[string]*zip.File) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	err := decode(files, "xl/workbook.xml", &workbook)
+	if err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("workbook has no sheets")
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	err = decode(files, "xl/_rels/workbook.xml.rels", &rels)
+	if err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		// Targets are relative to xl/ unless they start with a slash
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", errors.New("workbook's first sheet has no worksheet")
+}
+
+// sharedStrings reads the table of strings that cells refer to by index.
+func sharedStrings(f *zip.File) ([]string, error) {
+	var table struct {
+		Items []richText `xml:"si"`
+	}
+	err := decodeFile(f, &table)
+	if err != nil {
+		return nil, err
+	}
+
+	strs := make([]string, len(table.Items))
+	for i, item := range table.Items {
+		strs[i] = item.String()
+	}
+	return strs, nil
+}
+
+// richText is a string that is either plain or split into formatted runs.
+type richText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t richText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+func readSheet(f *zip.File, strs []string) ([]Row, error) {
+	var sheet struct {
+		Rows []struct {
+			Number int `xml:"r,attr"`
+			Cells  []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline richText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	err := decodeFile(f, &sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	for i, r := range sheet.Rows {
+		row := Row{Number: r.Number}
+		if row.Number == 0 {
+			row.Number = i + 1
+		}
+
+		for j, c := range r.Cells {
+			col := j
+			if c.Ref != "" {
+				col, err = column(c.Ref)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			var value string
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(strs) {
+					return nil, fmt.Errorf("cell %s refers to a missing shared string", c.Ref)
+				}
+				value = strs[n]
+			case "inlineStr":
+				value = c.Inline.String()
+			case "b":
+				value = "FALSE"
+				if c.Value == "1" {
+					value = "TRUE"
+				}
+			default:
+				value = c.Value
+			}
+
+			for len(row.Cells) <= col {
+				row.Cells = append(row.Cells, "")
+			}
+			row.Cells[col] = value
+		}
+
+		if !blank(row.Cells) {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
+
+// column converts a cell reference such as "AB12" to a zero-based column.
+func column(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+func blank(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func decode(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("not an xlsx workbook: %s is missing", name)
+	}
+	return decodeFile(f, v)
+}
+
+func decodeFile(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	err = xml.NewDecoder(rc).Decode(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.Name, err)
+	}
+	return nil
+}